@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/model"
+)
+
+// mockai 本地模拟大模型服务：同时提供 OpenAI 兼容、Anthropic、Ollama 三种接口，
+// 按规则对提交代码给出确定性的分析结果，便于离线测试 AI 判题流程。
+func main() {
+	addr := flag.String("addr", ":8091", "监听地址")
+	rulesPath := flag.String("rules", "", "规则文件路径（JSON 数组），留空使用内置规则")
+	flag.Parse()
+
+	rules, err := ai.LoadMockRules(*rulesPath)
+	if err != nil {
+		log.Fatalf("加载规则失败: %v", err)
+	}
+	provider := ai.NewMockProvider(rules)
+
+	mux := http.NewServeMux()
+	// OpenAI 兼容接口（DeepSeek/OpenAI/Moonshot）
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		result, ok := handleChat(w, r, provider)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id": "mock",
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": result.Content}},
+			},
+			"usage": map[string]int{
+				"prompt_tokens":     result.PromptTokens,
+				"completion_tokens": result.CompletionTokens,
+				"total_tokens":      result.PromptTokens + result.CompletionTokens,
+			},
+		})
+	})
+	// Anthropic Messages API（客户端预填了 "{"，这里返回其后的内容）
+	mux.HandleFunc("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		result, ok := handleChat(w, r, provider)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"type": "message",
+			"content": []map[string]string{
+				{"type": "text", "text": strings.TrimPrefix(result.Content, "{")},
+			},
+			"usage": map[string]int{
+				"input_tokens":  result.PromptTokens,
+				"output_tokens": result.CompletionTokens,
+			},
+		})
+	})
+	// Ollama /api/chat
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		result, ok := handleChat(w, r, provider)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message":           map[string]string{"role": "assistant", "content": result.Content},
+			"done":              true,
+			"prompt_eval_count": result.PromptTokens,
+			"eval_count":        result.CompletionTokens,
+		})
+	})
+
+	log.Printf("模拟 AI 服务已启动: %s", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatalf("启动失败: %v", err)
+	}
+}
+
+// handleChat 解析请求中的消息并交给模拟提供方分析；失败时按 OpenAI 错误格式返回
+func handleChat(w http.ResponseWriter, r *http.Request, provider *ai.MockProvider) (*ai.ChatResult, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "仅支持 POST")
+		return nil, false
+	}
+
+	var req struct {
+		Model    string           `json:"model"`
+		Messages []ai.ChatMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "请求格式错误: "+err.Error())
+		return nil, false
+	}
+
+	result, err := provider.Chat(ai.DetectChatTask(req.Messages), req.Messages, &model.AISettings{Provider: ai.ProviderMock, Model: req.Model})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	log.Printf("%s model=%s -> %s", r.URL.Path, req.Model, result.Content)
+	return result, true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"type": "mock_error", "message": message},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -117,11 +117,18 @@ func createDefaultAdmin() {
 	log.Printf("已创建默认管理员账号: admin / admin123")
 }
 
+func purgeAICallLogs(s *service.AICallLogService) {
+	if _, err := s.PurgeExpired(); err != nil {
+		log.Printf("清理 AI 调用记录失败: %v", err)
+	}
+}
+
 // startCronTasks 启动定时任务
 func startCronTasks() {
 	go func() {
 		contestService := service.NewContestService()
 		maintenanceService := service.NewMaintenanceService()
+		aiCallLogService := service.NewAICallLogService()
 
 		// 启动时立即执行一次全量同步，修复所有历史数据不一致问题
 		log.Println("正在执行启动时全量数据修复...")
@@ -129,13 +136,22 @@ func startCronTasks() {
 
 		// 启动时立即检查一次已结束的比赛
 		contestService.SyncEndedContests()
+		purgeAICallLogs(aiCallLogService)
 
 		ticker := time.NewTicker(1 * time.Minute)
 		defer ticker.Stop()
-
-		for range ticker.C {
-			// 同步已结束比赛的统计数据
-			contestService.SyncEndedContests()
+		purgeTicker := time.NewTicker(1 * time.Hour)
+		defer purgeTicker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// 同步已结束比赛的统计数据
+				contestService.SyncEndedContests()
+			case <-purgeTicker.C:
+				// 按保留天数清理 AI 调用记录
+				purgeAICallLogs(aiCallLogService)
+			}
 		}
 	}()
 	log.Printf("定时任务已启动")
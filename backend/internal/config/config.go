@@ -28,9 +28,13 @@ type DatabaseConfig struct {
 }
 
 type JudgeConfig struct {
-	Sandbox string `yaml:"sandbox"`
-	Workers int    `yaml:"workers"`
-	Timeout int    `yaml:"timeout"`
+	Sandbox     string `yaml:"sandbox"`
+	Workers     int    `yaml:"workers"`
+	AIWorkers   int    `yaml:"ai_workers"`   // AI 分析 worker 数，与测试点评测互不占用
+	TaskWorkers int    `yaml:"task_workers"` // 后台任务队列 worker 数，与判题、AI 分析互不占用
+	Timeout     int    `yaml:"timeout"`
+	CgroupRoot  string `yaml:"cgroup_root"` // cgroup v2 目录，留空使用 /sys/fs/cgroup/oj-judge，off 关闭
+	NodeID      string `yaml:"node_id"`     // 判题节点标识，留空使用主机名
 }
 
 type AIConfig struct {
@@ -80,9 +84,20 @@ func Load(path string) (*Config, error) {
 	if cfg.Judge.Workers == 0 {
 		cfg.Judge.Workers = 2
 	}
+	if cfg.Judge.AIWorkers == 0 {
+		cfg.Judge.AIWorkers = 2
+	}
+	if cfg.Judge.TaskWorkers == 0 {
+		cfg.Judge.TaskWorkers = 2
+	}
 	if cfg.Judge.Timeout == 0 {
 		cfg.Judge.Timeout = 30
 	}
+	if cfg.Judge.NodeID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			cfg.Judge.NodeID = hostname
+		}
+	}
 	if cfg.JWT.Expire == 0 {
 		cfg.JWT.Expire = 72 * time.Hour
 	}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type AICallLogHandler struct {
+	service *service.AICallLogService
+}
+
+func NewAICallLogHandler() *AICallLogHandler {
+	return &AICallLogHandler{
+		service: service.NewAICallLogService(),
+	}
+}
+
+// List 获取 AI 调用记录列表（不含提示词与响应内容）
+// GET /api/v1/admin/ai-logs?submission_id=&problem_id=
+func (h *AICallLogHandler) List(c *gin.Context) {
+	page := getIntQuery(c, "page", 1)
+	size := getIntQuery(c, "size", 20)
+
+	data, err := h.service.List(page, size, getUintQuery(c, "submission_id"), getUintQuery(c, "problem_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerError("获取调用记录失败"))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(data))
+}
+
+// GetByID 获取 AI 调用记录详情（完整提示词、模型输出与原始响应）
+// GET /api/v1/admin/ai-logs/:id
+func (h *AICallLogHandler) GetByID(c *gin.Context) {
+	entry, err := h.service.GetByID(getUintParam(c, "id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(entry))
+}
+
+// Purge 立即按保留天数清理过期的调用记录
+// POST /api/v1/admin/ai-logs/purge
+func (h *AICallLogHandler) Purge(c *gin.Context) {
+	deleted, err := h.service.PurgeExpired()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerError("清理失败"))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("清理完成", gin.H{"deleted": deleted}))
+}
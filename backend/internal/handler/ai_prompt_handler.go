@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/middleware"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type AIPromptHandler struct {
+	service           *service.AIPromptService
+	submissionService *service.SubmissionService
+	problemService    *service.ProblemService
+	aiClient          *ai.Client
+}
+
+func NewAIPromptHandler() *AIPromptHandler {
+	return &AIPromptHandler{
+		service:           service.NewAIPromptService(),
+		submissionService: service.NewSubmissionService(),
+		problemService:    service.NewProblemService(),
+		aiClient:          ai.NewClient(),
+	}
+}
+
+// List 获取提示词模板列表
+// GET /api/v1/admin/settings/ai/prompts
+func (h *AIPromptHandler) List(c *gin.Context) {
+	templates, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerError("获取提示词模板失败"))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(templates))
+}
+
+// Builtin 获取内置提示词模板，可作为自定义模板的起点
+// GET /api/v1/admin/settings/ai/prompts/builtin
+func (h *AIPromptHandler) Builtin(c *gin.Context) {
+	c.JSON(http.StatusOK, model.Success(ai.BuiltinPromptTemplate()))
+}
+
+// GetByID 获取提示词模板
+// GET /api/v1/admin/settings/ai/prompts/:id
+func (h *AIPromptHandler) GetByID(c *gin.Context) {
+	tpl, err := h.service.GetByID(getUintParam(c, "id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(tpl))
+}
+
+// Create 创建提示词模板
+// POST /api/v1/admin/settings/ai/prompts
+func (h *AIPromptHandler) Create(c *gin.Context) {
+	var req model.SaveAIPromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误"))
+		return
+	}
+	if err := ai.ValidatePromptTemplate(req.SystemPrompt, req.UserPrompt); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("模板无效: "+err.Error()))
+		return
+	}
+
+	tpl, err := h.service.Create(&req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("创建成功", tpl))
+}
+
+// Update 修改提示词模板（内容变化时生成新版本）
+// PUT /api/v1/admin/settings/ai/prompts/:id
+func (h *AIPromptHandler) Update(c *gin.Context) {
+	var req model.SaveAIPromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误"))
+		return
+	}
+	if err := ai.ValidatePromptTemplate(req.SystemPrompt, req.UserPrompt); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("模板无效: "+err.Error()))
+		return
+	}
+
+	tpl, err := h.service.Update(getUintParam(c, "id"), &req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("保存成功", tpl))
+}
+
+// Delete 删除提示词模板
+// DELETE /api/v1/admin/settings/ai/prompts/:id
+func (h *AIPromptHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(getUintParam(c, "id")); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
+// ListVersions 获取提示词模板的历史版本
+// GET /api/v1/admin/settings/ai/prompts/:id/versions
+func (h *AIPromptHandler) ListVersions(c *gin.Context) {
+	versions, err := h.service.ListVersions(getUintParam(c, "id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(versions))
+}
+
+// RestoreVersion 恢复提示词模板的历史版本
+// POST /api/v1/admin/settings/ai/prompts/:id/versions/:version/restore
+func (h *AIPromptHandler) RestoreVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("版本号无效"))
+		return
+	}
+
+	tpl, err := h.service.Restore(getUintParam(c, "id"), version, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("已恢复", tpl))
+}
+
+// Render 按提交试渲染提示词，不调用模型
+// POST /api/v1/admin/settings/ai/prompts/render
+func (h *AIPromptHandler) Render(c *gin.Context) {
+	var req model.RenderAIPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误"))
+		return
+	}
+
+	submission, err := h.submissionService.GetByIDForJudge(req.SubmissionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound("提交不存在"))
+		return
+	}
+	problem, err := h.problemService.GetByID(submission.ProblemID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound("题目不存在"))
+		return
+	}
+
+	// 优先渲染未保存的草稿，其次为指定模板，否则为该题实际生效的模板
+	var tpl *model.AIPromptTemplate
+	switch {
+	case req.UserPrompt != "":
+		tpl = &model.AIPromptTemplate{Name: "草稿", SystemPrompt: req.SystemPrompt, UserPrompt: req.UserPrompt}
+	case req.TemplateID != 0:
+		if tpl, err = h.service.GetByID(req.TemplateID); err != nil {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+	}
+
+	preview, err := h.aiClient.PreviewPrompt(problem, submission.Code, submission.Language, tpl)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("渲染失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(preview))
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type AIUsageHandler struct {
+	service *service.AIUsageService
+}
+
+func NewAIUsageHandler() *AIUsageHandler {
+	return &AIUsageHandler{
+		service: service.NewAIUsageService(),
+	}
+}
+
+// GetStats 获取 AI token 用量统计（按天、题目、比赛）与本月预算使用情况
+// GET /api/v1/admin/ai-usage?from=2006-01-02&to=2006-01-02
+func (h *AIUsageHandler) GetStats(c *gin.Context) {
+	stats, err := h.service.GetStats(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(stats))
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge"
 	"oj-system/internal/middleware"
 	"oj-system/internal/model"
 	"oj-system/internal/repository"
@@ -16,14 +17,18 @@ import (
 )
 
 type ContestHandler struct {
-	service        *service.ContestService
-	submissionRepo *repository.SubmissionRepository
+	service              *service.ContestService
+	hackService          *service.HackService
+	clarificationService *service.ClarificationService
+	submissionRepo       *repository.SubmissionRepository
 }
 
 func NewContestHandler() *ContestHandler {
 	return &ContestHandler{
-		service:        service.NewContestService(),
-		submissionRepo: repository.NewSubmissionRepository(),
+		service:              service.NewContestService(),
+		hackService:          service.NewHackService(),
+		clarificationService: service.NewClarificationService(),
+		submissionRepo:       repository.NewSubmissionRepository(),
 	}
 }
 
@@ -82,9 +87,13 @@ func (h *ContestHandler) GetByID(c *gin.Context) {
 	hasStarted := sessionState != nil && sessionState.Started
 	inLive := sessionState != nil && sessionState.InLive
 
+	// IOI 与 ACM 赛制赛时即时反馈结果，OI 赛制赛后才公布
+	contestType := strings.ToLower(contest.Type)
+	liveFeedback := contestType == "ioi" || contestType == "acm"
+
 	acceptedSet := map[uint]struct{}{}
 	submittedSet := map[uint]struct{}{}
-	showAccepted := isAdmin || strings.ToLower(contest.Type) == "ioi" || (hasStarted && !inLive)
+	showAccepted := isAdmin || liveFeedback || (hasStarted && !inLive)
 	if userID > 0 && hasStarted {
 		rangeStart := contest.StartAt
 		if sessionState.StartAt != nil {
@@ -117,7 +126,7 @@ func (h *ContestHandler) GetByID(c *gin.Context) {
 			mySubmissionCount = &count
 		}
 
-		showScore := strings.ToLower(contest.Type) == "ioi" || !inLive
+		showScore := liveFeedback || !inLive
 		if showScore {
 			liveStart := contest.StartAt
 			liveEnd := contest.EndAt
@@ -162,6 +171,12 @@ func (h *ContestHandler) GetByID(c *gin.Context) {
 		}
 	}
 
+	announcements, clarifications, _ := h.clarificationService.ListForContest(contest, userID, isAdmin)
+
+	if !isAdmin {
+		hideContestRoster(contest)
+	}
+
 	c.JSON(http.StatusOK, model.Success(gin.H{
 		"contest":             contest,
 		"problems":            ordered,
@@ -169,6 +184,8 @@ func (h *ContestHandler) GetByID(c *gin.Context) {
 		"my_live_total":       myLiveTotal,
 		"my_post_total":       myPostTotal,
 		"my_submission_count": mySubmissionCount,
+		"announcements":       announcements,
+		"clarifications":      clarifications,
 	}))
 }
 
@@ -201,7 +218,7 @@ func (h *ContestHandler) StartContest(c *gin.Context) {
 	}))
 }
 
-// GetUserLeaderboard 获取比赛实时排行榜（普通用户可见，窗口期需已开始个人会话）
+// GetUserLeaderboard 获取比赛实时排行榜（按比赛的榜单可见性设置向选手公开，窗口期赛时需已开始个人会话）
 // GET /api/v1/contest/:id/leaderboard
 func (h *ContestHandler) GetUserLeaderboard(c *gin.Context) {
 	contestID := getUintParam(c, "id")
@@ -222,15 +239,26 @@ func (h *ContestHandler) GetUserLeaderboard(c *gin.Context) {
 		return
 	}
 
-	if strings.ToLower(contest.TimingMode) == "window" && !isAdmin {
-		state, _ := h.service.GetSessionState(contest, userID, time.Now())
+	now := time.Now()
+	if strings.ToLower(contest.TimingMode) == "window" && !isAdmin && now.Before(contest.EndAt) {
+		state, _ := h.service.GetSessionState(contest, userID, now)
 		if state == nil || !state.Started {
 			c.JSON(http.StatusForbidden, model.Forbidden("请先开始比赛后查看排行榜"))
 			return
 		}
 	}
 
-	contest, problemIDs, entries, boardMode, err := h.service.GetLeaderboard(contestID, "live")
+	// 管理员看实时榜单，选手看封榜后的榜单（本人结果不封）
+	var entries []model.ContestLeaderboardEntry
+	var freeze *model.ContestFreezeState
+	if isAdmin {
+		contest, _, entries, _, err = h.service.GetLeaderboard(contestID, "live")
+		if err == nil {
+			freeze, err = h.service.GetFreezeState(contest)
+		}
+	} else {
+		contest, entries, freeze, err = h.service.GetFrozenLeaderboard(contestID, userID)
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
 		return
@@ -245,14 +273,319 @@ func (h *ContestHandler) GetUserLeaderboard(c *gin.Context) {
 		entries = filtered
 	}
 
+	view := &model.ContestBoardView{
+		Visibility:   contest.LeaderboardVisibility,
+		Participants: len(entries),
+		Entries:      entries,
+	}
+	if !isAdmin {
+		view, err = h.service.ApplyLeaderboardVisibility(contest, entries, userID, now)
+		if err != nil {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+			return
+		}
+		hideContestRoster(contest)
+	}
+
 	c.JSON(http.StatusOK, model.Success(gin.H{
-		"contest":     contest,
-		"problem_ids": problemIDs,
-		"entries":     entries,
-		"board_mode":  boardMode,
+		"contest":       contest,
+		"problem_ids":   contest.ProblemIDs,
+		"entries":       view.Entries,
+		"board_mode":    "live",
+		"freeze":        freeze,
+		"visibility":    view.Visibility,
+		"scores_hidden": view.ScoresHidden,
+		"participants":  view.Participants,
+		"my_rank":       view.MyRank,
+	}))
+}
+
+// hideContestRoster 参赛名单只对管理员可见，否则匿名榜单可由名单中的用户 ID 反推身份
+func hideContestRoster(contest *model.Contest) {
+	contest.AllowedUsers = nil
+	contest.AllowedGroups = nil
+}
+
+// LockProblem 锁定已通过的比赛题目（锁定后不能再提交该题，可 hack 他人）
+// POST /api/v1/contest/:id/lock
+func (h *ContestHandler) LockProblem(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	var req model.ContestLockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	lock, err := h.hackService.Lock(contestID, middleware.GetUserID(c), req.ProblemID)
+	if err != nil {
+		if err.Error() == "无权限访问该比赛" {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("锁定成功", lock))
+}
+
+// ListLocks 获取自己在比赛中的锁定记录
+// GET /api/v1/contest/:id/locks
+func (h *ContestHandler) ListLocks(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	locks, err := h.hackService.ListLocks(contestID, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(locks))
+}
+
+// ListHackTargets 获取某题可 hack 的提交（需已锁定该题）
+// GET /api/v1/contest/:id/hack/targets?problem_id=
+func (h *ContestHandler) ListHackTargets(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	problemID := getUintQuery(c, "problem_id")
+	if contestID == 0 || problemID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	targets, err := h.hackService.ListTargets(contestID, middleware.GetUserID(c), problemID)
+	if err != nil {
+		if err.Error() == "无权限访问该比赛" {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(targets))
+}
+
+// CreateHack 发起 hack（异步执行）
+// POST /api/v1/contest/:id/hacks
+func (h *ContestHandler) CreateHack(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	var req model.HackCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	hack, err := h.hackService.Create(contestID, middleware.GetUserID(c), &req)
+	if err != nil {
+		if err.Error() == "无权限访问该比赛" {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueueHack(hack.ID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("hack 已提交", hack))
+}
+
+// ListHacks 获取比赛的 hack 记录
+// GET /api/v1/contest/:id/hacks
+func (h *ContestHandler) ListHacks(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	hacks, err := h.hackService.List(contestID, middleware.GetUserID(c), middleware.IsAdmin(c))
+	if err != nil {
+		if err.Error() == "比赛不存在" || err.Error() == "用户不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		} else {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(hacks))
+}
+
+// ListClarifications 获取比赛公告与答疑（选手只看到自己的提问与公开的回复）
+// GET /api/v1/contest/:id/clarifications
+func (h *ContestHandler) ListClarifications(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	announcements, clarifications, err := h.clarificationService.List(contestID, middleware.GetUserID(c), middleware.IsAdmin(c))
+	if err != nil {
+		if err.Error() == "比赛不存在" || err.Error() == "用户不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		} else if err.Error() == "无权限访问该比赛" {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+		} else {
+			c.JSON(http.StatusInternalServerError, model.ServerError(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(gin.H{
+		"announcements":  announcements,
+		"clarifications": clarifications,
 	}))
 }
 
+// AskClarification 选手在比赛中提问
+// POST /api/v1/contest/:id/clarifications
+func (h *ContestHandler) AskClarification(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	var req model.ClarificationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	clarification, err := h.clarificationService.Ask(contestID, middleware.GetUserID(c), &req)
+	if err != nil {
+		if err.Error() == "无权限访问该比赛" {
+			c.JSON(http.StatusForbidden, model.Forbidden(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("提问已提交", clarification))
+}
+
+// AnswerClarification 回复提问（管理员），可仅回复提问者或广播给全体参赛者
+// POST /api/v1/admin/contests/:id/clarifications/:clarification_id/answer
+func (h *ContestHandler) AnswerClarification(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	clarificationID := getUintParam(c, "clarification_id")
+	if contestID == 0 || clarificationID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	var req model.ClarificationAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	clarification, err := h.clarificationService.Answer(contestID, clarificationID, middleware.GetUserID(c), &req)
+	if err != nil {
+		if err.Error() == "提问不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("回复成功", clarification))
+}
+
+// DeleteClarification 删除提问（管理员）
+// DELETE /api/v1/admin/contests/:id/clarifications/:clarification_id
+func (h *ContestHandler) DeleteClarification(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	clarificationID := getUintParam(c, "clarification_id")
+	if contestID == 0 || clarificationID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	if err := h.clarificationService.Delete(contestID, clarificationID); err != nil {
+		if err.Error() == "提问不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ServerError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
+// CreateAnnouncement 发布比赛公告（管理员）
+// POST /api/v1/admin/contests/:id/announcements
+func (h *ContestHandler) CreateAnnouncement(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	var req model.AnnouncementCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	announcement, err := h.clarificationService.CreateAnnouncement(contestID, middleware.GetUserID(c), &req)
+	if err != nil {
+		if err.Error() == "比赛不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("公告已发布", announcement))
+}
+
+// DeleteAnnouncement 删除比赛公告（管理员）
+// DELETE /api/v1/admin/contests/:id/announcements/:announcement_id
+func (h *ContestHandler) DeleteAnnouncement(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	announcementID := getUintParam(c, "announcement_id")
+	if contestID == 0 || announcementID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	if err := h.clarificationService.DeleteAnnouncement(contestID, announcementID); err != nil {
+		if err.Error() == "公告不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ServerError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
 // Create 创建比赛（管理员）
 // POST /api/v1/admin/contests
 func (h *ContestHandler) Create(c *gin.Context) {
@@ -402,12 +735,86 @@ func (h *ContestHandler) GetLeaderboard(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
 		return
 	}
+	freeze, err := h.service.GetFreezeState(contest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, model.Success(gin.H{
 		"contest":     contest,
 		"problem_ids": problemIDs,
 		"entries":     entries,
 		"board_mode":  boardMode,
+		"freeze":      freeze,
+	}))
+}
+
+// GetResolver 获取颁奖揭晓用的榜单：所有人封榜期间的结果均按揭晓进度展示（管理员）
+// GET /api/v1/admin/contests/:id/resolver
+func (h *ContestHandler) GetResolver(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	contest, entries, freeze, err := h.service.GetFrozenLeaderboard(contestID, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(gin.H{
+		"contest":     contest,
+		"problem_ids": contest.ProblemIDs,
+		"entries":     entries,
+		"freeze":      freeze,
+	}))
+}
+
+// RevealNext 揭晓下一格封榜结果（管理员）
+// POST /api/v1/admin/contests/:id/resolver/next
+func (h *ContestHandler) RevealNext(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	step, contest, entries, freeze, err := h.service.RevealNext(contestID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(gin.H{
+		"step":        step,
+		"contest":     contest,
+		"problem_ids": contest.ProblemIDs,
+		"entries":     entries,
+		"freeze":      freeze,
+	}))
+}
+
+// Unfreeze 一次揭晓全部结果并解除封榜（管理员）
+// POST /api/v1/admin/contests/:id/unfreeze
+func (h *ContestHandler) Unfreeze(c *gin.Context) {
+	contestID := getUintParam(c, "id")
+	if contestID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("比赛 ID 无效"))
+		return
+	}
+
+	contest, err := h.service.Unfreeze(contestID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("已解除封榜", gin.H{
+		"contest_id":  contest.ID,
+		"unfrozen_at": contest.UnfrozenAt,
 	}))
 }
 
@@ -421,7 +828,7 @@ func (h *ContestHandler) ExportLeaderboard(c *gin.Context) {
 	}
 
 	mode := c.DefaultQuery("board_mode", "combined")
-	_, problemIDs, entries, boardMode, err := h.service.GetLeaderboard(contestID, mode)
+	contest, problemIDs, entries, boardMode, err := h.service.GetLeaderboard(contestID, mode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
 		return
@@ -434,6 +841,11 @@ func (h *ContestHandler) ExportLeaderboard(c *gin.Context) {
 	writer := csv.NewWriter(c.Writer)
 	defer writer.Flush()
 
+	if strings.ToLower(contest.Type) == "acm" {
+		writeACMLeaderboardCSV(writer, problemIDs, entries, boardMode)
+		return
+	}
+
 	header := []string{"user_id", "username", "group"}
 	if boardMode == "combined" {
 		header = append(header, "live_total", "post_total")
@@ -478,6 +890,52 @@ func (h *ContestHandler) ExportLeaderboard(c *gin.Context) {
 	}
 }
 
+// writeACMLeaderboardCSV 导出 ACM 排行榜：每题导出尝试次数、通过分钟数（未通过留空）与是否一血
+func writeACMLeaderboardCSV(writer *csv.Writer, problemIDs []uint, entries []model.ContestLeaderboardEntry, boardMode string) {
+	showPost := boardMode != "live"
+	header := []string{"rank", "user_id", "username", "group", "solved", "penalty"}
+	if showPost {
+		header = append(header, "post_solved")
+	}
+	for i := range problemIDs {
+		label := getContestProblemLabel(i)
+		header = append(header, label+"_attempts", label+"_time", label+"_first_blood")
+		if showPost {
+			header = append(header, label+"_post_accepted")
+		}
+	}
+	_ = writer.Write(header)
+
+	for rank, entry := range entries {
+		row := []string{
+			strconv.Itoa(rank + 1),
+			strconv.FormatUint(uint64(entry.UserID), 10),
+			entry.Username,
+			entry.Group,
+			strconv.Itoa(entry.Solved),
+			strconv.Itoa(entry.Penalty),
+		}
+		if showPost {
+			row = append(row, strconv.Itoa(entry.PostSolved))
+		}
+		for i := range problemIDs {
+			var cell model.ContestACMCell
+			if i < len(entry.Problems) {
+				cell = entry.Problems[i]
+			}
+			minutes := ""
+			if cell.Accepted {
+				minutes = strconv.Itoa(cell.Minutes)
+			}
+			row = append(row, strconv.Itoa(cell.Attempts), minutes, strconv.FormatBool(cell.FirstBlood))
+			if showPost {
+				row = append(row, strconv.FormatBool(cell.PostAccepted))
+			}
+		}
+		_ = writer.Write(row)
+	}
+}
+
 func buildContestProblemList(
 	ids model.UintList,
 	problems []model.Problem,
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge"
+	"oj-system/internal/model"
+)
+
+type JudgeHandler struct{}
+
+func NewJudgeHandler() *JudgeHandler {
+	return &JudgeHandler{}
+}
+
+// Info 获取判题节点信息（工具链版本、沙箱、内存统计方式）；节点标识默认取主机名，不对外公开
+// GET /api/v1/judge/info
+func (h *JudgeHandler) Info(c *gin.Context) {
+	info := judge.GetJudgeInfo()
+	info.NodeID = ""
+	c.JSON(http.StatusOK, model.Success(info))
+}
+
+// AdminInfo 获取包含节点标识的判题节点信息
+// GET /api/v1/admin/judge/info
+func (h *JudgeHandler) AdminInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, model.Success(judge.GetJudgeInfo()))
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge"
+	"oj-system/internal/middleware"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type PlagiarismHandler struct {
+	service *service.PlagiarismService
+}
+
+func NewPlagiarismHandler() *PlagiarismHandler {
+	return &PlagiarismHandler{
+		service: service.NewPlagiarismService(),
+	}
+}
+
+// Create 对题目或比赛发起代码查重（异步执行）
+// POST /api/v1/admin/plagiarism
+func (h *PlagiarismHandler) Create(c *gin.Context) {
+	var req model.PlagiarismCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	report, err := h.service.Start(&req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueuePlagiarismCheck(report.ID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("查重任务已创建", report))
+}
+
+// List 获取查重报告列表
+// GET /api/v1/admin/plagiarism?scope=&target_id=
+func (h *PlagiarismHandler) List(c *gin.Context) {
+	page := getIntQuery(c, "page", 1)
+	size := getIntQuery(c, "size", 20)
+
+	data, err := h.service.List(page, size, c.Query("scope"), getUintQuery(c, "target_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerError("获取查重报告失败"))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(data))
+}
+
+// GetByID 获取查重报告及相似代码对（不含代码）
+// GET /api/v1/admin/plagiarism/:id
+func (h *PlagiarismHandler) GetByID(c *gin.Context) {
+	report, err := h.service.GetWithPairs(getUintParam(c, "id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(report))
+}
+
+// GetPair 获取一对相似代码的并排对比数据
+// GET /api/v1/admin/plagiarism/:id/pairs/:pair_id
+func (h *PlagiarismHandler) GetPair(c *gin.Context) {
+	detail, err := h.service.GetPairDetail(getUintParam(c, "id"), getUintParam(c, "pair_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(detail))
+}
+
+// Delete 删除查重报告
+// DELETE /api/v1/admin/plagiarism/:id
+func (h *PlagiarismHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(getUintParam(c, "id")); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
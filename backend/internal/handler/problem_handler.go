@@ -6,18 +6,23 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"oj-system/internal/judge"
+	"oj-system/internal/judge/staticcheck"
 	"oj-system/internal/middleware"
 	"oj-system/internal/model"
 	"oj-system/internal/service"
 )
 
 type ProblemHandler struct {
-	service *service.ProblemService
+	service             *service.ProblemService
+	programService      *service.ProblemProgramService
+	verificationService *service.ProblemVerificationService
 }
 
 func NewProblemHandler() *ProblemHandler {
 	return &ProblemHandler{
-		service: service.NewProblemService(),
+		service:             service.NewProblemService(),
+		programService:      service.NewProblemProgramService(),
+		verificationService: service.NewProblemVerificationService(),
 	}
 }
 
@@ -46,8 +51,8 @@ func (h *ProblemHandler) List(c *gin.Context) {
 	}))
 }
 
-// GetByID 获取题目详情
-// GET /api/v1/problem/:id
+// GetByID 获取题目详情；按 lang 参数或 Accept-Language 请求头返回已审核的译文，没有匹配的译文时返回原文
+// GET /api/v1/problem/:id?lang=
 func (h *ProblemHandler) GetByID(c *gin.Context) {
 	id := getUintParam(c, "id")
 	if id == 0 {
@@ -67,6 +72,18 @@ func (h *ProblemHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	preferred := service.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if lang := c.Query("lang"); lang != "" {
+		preferred = append([]string{lang}, preferred...)
+	}
+	service.LocalizeProblem(problem, preferred)
+	if !isAdmin {
+		// 草稿与审核信息只对管理员可见
+		problem.Translations = nil
+	}
+	c.Header("Content-Language", problem.Locale)
+	c.Header("Vary", "Accept-Language")
+
 	c.JSON(http.StatusOK, model.Success(problem))
 }
 
@@ -79,6 +96,10 @@ func (h *ProblemHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if !validateAIJudgeConfig(c, req.AIJudgeConfig) {
+		return
+	}
+
 	userID := middleware.GetUserID(c)
 	problem, err := h.service.Create(&req, userID)
 	if err != nil {
@@ -104,6 +125,10 @@ func (h *ProblemHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !validateAIJudgeConfig(c, req.AIJudgeConfig) {
+		return
+	}
+
 	problem, err := h.service.Update(id, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
@@ -113,6 +138,22 @@ func (h *ProblemHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, model.Success(problem))
 }
 
+// validateAIJudgeConfig 校验 AI 判题配置中的静态检查规则与复核阈值，无效时返回 400
+func validateAIJudgeConfig(c *gin.Context, aiConfig *model.AIJudgeConfig) bool {
+	if aiConfig == nil {
+		return true
+	}
+	if err := staticcheck.Validate(aiConfig.StaticRules); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return false
+	}
+	if aiConfig.ReviewThreshold < 0 || aiConfig.ReviewThreshold > 1 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("复核置信度阈值需在 0 到 1 之间"))
+		return false
+	}
+	return true
+}
+
 // Delete 删除题目（管理员）
 // DELETE /api/v1/problem/:id
 func (h *ProblemHandler) Delete(c *gin.Context) {
@@ -220,6 +261,248 @@ func (h *ProblemHandler) UploadTestcaseZip(c *gin.Context) {
 	c.JSON(http.StatusOK, model.SuccessMessage("批量上传并处理成功", nil))
 }
 
+// ListPrograms 获取题目配套程序（管理员）
+// GET /api/v1/problem/:id/programs
+func (h *ProblemHandler) ListPrograms(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	programs, err := h.programService.List(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(programs))
+}
+
+// SaveProgram 保存题目配套程序（管理员）
+// PUT /api/v1/problem/:id/programs/:role
+func (h *ProblemHandler) SaveProgram(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.ProblemProgramSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	program, err := h.programService.Save(id, c.Param("role"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(program))
+}
+
+// DeleteProgram 删除题目配套程序（管理员）
+// DELETE /api/v1/problem/:id/programs/:role
+func (h *ProblemHandler) DeleteProgram(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	if err := h.programService.Delete(id, c.Param("role")); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
+// GenerateTestcases 服务端运行生成器与标准程序生成测试数据（管理员，异步执行）
+// POST /api/v1/problem/:id/testcase/generate
+func (h *ProblemHandler) GenerateTestcases(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.TestcaseGenerateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+			return
+		}
+	}
+
+	generation, err := h.programService.StartGeneration(id, &req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueueTestcaseGeneration(generation.ID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("生成任务已创建", generation))
+}
+
+// ListGenerations 获取题目测试数据生成记录（管理员）
+// GET /api/v1/problem/:id/testcase/generations
+func (h *ProblemHandler) ListGenerations(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	generations, err := h.programService.ListGenerations(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(generations))
+}
+
+// ListSolutions 获取题目标注解法（管理员）
+// GET /api/v1/problem/:id/solutions
+func (h *ProblemHandler) ListSolutions(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	solutions, err := h.verificationService.ListSolutions(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(solutions))
+}
+
+// CreateSolution 添加题目标注解法（管理员）
+// POST /api/v1/problem/:id/solutions
+func (h *ProblemHandler) CreateSolution(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.ProblemSolutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	solution, err := h.verificationService.CreateSolution(id, &req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(solution))
+}
+
+// UpdateSolution 更新题目标注解法（管理员）
+// PUT /api/v1/problem/:id/solutions/:solution_id
+func (h *ProblemHandler) UpdateSolution(c *gin.Context) {
+	id := getUintParam(c, "id")
+	solutionID := getUintParam(c, "solution_id")
+	if id == 0 || solutionID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	var req model.ProblemSolutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	solution, err := h.verificationService.UpdateSolution(id, solutionID, &req)
+	if err != nil {
+		if err.Error() == "解法不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(solution))
+}
+
+// DeleteSolution 删除题目标注解法（管理员）
+// DELETE /api/v1/problem/:id/solutions/:solution_id
+func (h *ProblemHandler) DeleteSolution(c *gin.Context) {
+	id := getUintParam(c, "id")
+	solutionID := getUintParam(c, "solution_id")
+	if id == 0 || solutionID == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数无效"))
+		return
+	}
+
+	if err := h.verificationService.DeleteSolution(id, solutionID); err != nil {
+		if err.Error() == "解法不存在" {
+			c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ServerError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
+// VerifyProblem 用全部标注解法校验题目测试数据（管理员，异步执行）
+// POST /api/v1/problem/:id/verify
+func (h *ProblemHandler) VerifyProblem(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	verification, err := h.verificationService.StartVerification(id, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueueProblemVerification(verification.ID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("校验任务已创建", verification))
+}
+
+// ListVerifications 获取题目校验记录（管理员）
+// GET /api/v1/problem/:id/verifications
+func (h *ProblemHandler) ListVerifications(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	verifications, err := h.verificationService.ListVerifications(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(verifications))
+}
+
 // UploadProblemImage 上传题面图片（管理员）
 // POST /api/v1/problem/:id/image
 func (h *ProblemHandler) UploadProblemImage(c *gin.Context) {
@@ -310,13 +593,15 @@ func (h *ProblemHandler) DeleteTestcases(c *gin.Context) {
 }
 
 // RejudgeProblem 重新评测题目的所有历史提交（管理员）
-// POST /api/v1/problem/:id/rejudge
+// POST /api/v1/problem/:id/rejudge?force_ai=true
+// 默认复用 AI 分析缓存，force_ai=true 时强制重新调用 AI
 func (h *ProblemHandler) RejudgeProblem(c *gin.Context) {
 	id := getUintParam(c, "id")
 	if id == 0 {
 		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
 		return
 	}
+	forceAI := c.Query("force_ai") == "true"
 
 	submissions, err := h.service.PrepareProblemRejudge(id)
 	if err != nil {
@@ -334,7 +619,7 @@ func (h *ProblemHandler) RejudgeProblem(c *gin.Context) {
 
 	failed := 0
 	for i := range submissions {
-		if err := judge.SubmitToQueue(&submissions[i]); err != nil {
+		if err := judge.SubmitRejudgeToQueue(&submissions[i], forceAI); err != nil {
 			failed++
 		}
 	}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge"
+	"oj-system/internal/middleware"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type ProblemTranslationHandler struct {
+	service *service.ProblemTranslationService
+}
+
+func NewProblemTranslationHandler() *ProblemTranslationHandler {
+	return &ProblemTranslationHandler{
+		service: service.NewProblemTranslationService(),
+	}
+}
+
+// List 获取题目的全部译文（含草稿）及支持的语言（管理员）
+// GET /api/v1/problem/:id/translations
+func (h *ProblemTranslationHandler) List(c *gin.Context) {
+	overview, err := h.service.Overview(getUintParam(c, "id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(overview))
+}
+
+// Translate 用 AI 将题面翻译为所选语言（异步执行，结果为待审核的草稿）（管理员）
+// POST /api/v1/problem/:id/translations/ai
+func (h *ProblemTranslationHandler) Translate(c *gin.Context) {
+	id := getUintParam(c, "id")
+	var req model.ProblemTranslateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	locales, err := h.service.StartMachineTranslation(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueueProblemTranslation(id, locales); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("翻译任务已创建", gin.H{"locales": locales}))
+}
+
+// Save 保存人工修改的译文，可同时标记为已审核（管理员）
+// PUT /api/v1/problem/:id/translations/:locale
+func (h *ProblemTranslationHandler) Save(c *gin.Context) {
+	var req model.ProblemTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	translation, err := h.service.Save(getUintParam(c, "id"), c.Param("locale"), &req,
+		middleware.GetUserID(c), middleware.GetUsername(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("译文已保存", translation))
+}
+
+// Delete 删除某一语言的译文（管理员）
+// DELETE /api/v1/problem/:id/translations/:locale
+func (h *ProblemTranslationHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(getUintParam(c, "id"), c.Param("locale")); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("删除成功", nil))
+}
+
+// DiscardPending 放弃已发布译文的待审核版本，保留已发布的内容（管理员）
+// DELETE /api/v1/problem/:id/translations/:locale/pending
+func (h *ProblemTranslationHandler) DiscardPending(c *gin.Context) {
+	if err := h.service.DiscardPending(getUintParam(c, "id"), c.Param("locale")); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("已放弃新版本", nil))
+}
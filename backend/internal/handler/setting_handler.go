@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge/ai"
 	"oj-system/internal/model"
 	"oj-system/internal/service"
 )
@@ -47,7 +48,7 @@ func (h *SettingHandler) UpdateAISettings(c *gin.Context) {
 func (h *SettingHandler) TestAIConnection(c *gin.Context) {
 	settings := h.service.GetAISettings()
 	
-	if settings.APIKey == "" {
+	if ai.NewProvider(settings.Provider).RequiresAPIKey() && settings.APIKey == "" {
 		c.JSON(http.StatusBadRequest, model.BadRequest("请先配置 API Key"))
 		return
 	}
@@ -149,6 +149,43 @@ func (h *SubmissionHandler) AbortSubmission(c *gin.Context) {
 	}))
 }
 
+// ListAIReviews 获取等待教师复核 AI 判定的提交（管理员）
+// GET /api/v1/admin/ai-reviews?problem_id=
+func (h *SubmissionHandler) ListAIReviews(c *gin.Context) {
+	page := getIntQuery(c, "page", 1)
+	size := getIntQuery(c, "size", 20)
+
+	data, err := h.service.ListAIReviewQueue(page, size, getUintQuery(c, "problem_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerError("获取复核队列失败"))
+		return
+	}
+	c.JSON(http.StatusOK, model.Success(data))
+}
+
+// ReviewAIResult 教师确认或改判低置信度的 AI 判定（管理员）
+// POST /api/v1/admin/submissions/:id/ai-review
+func (h *SubmissionHandler) ReviewAIResult(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("提交 ID 无效"))
+		return
+	}
+
+	var req model.AIReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	submission, err := judge.ReviewAIResult(id, &req, middleware.GetUserID(c), middleware.GetUsername(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessMessage("复核结果已保存", submission))
+}
+
 // DeleteSubmission 删除提交记录（管理员）
 // DELETE /api/v1/admin/submissions/:id
 func (h *SubmissionHandler) DeleteSubmission(c *gin.Context) {
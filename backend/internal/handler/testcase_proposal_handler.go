@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"oj-system/internal/judge"
+	"oj-system/internal/middleware"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+type TestcaseProposalHandler struct {
+	service *service.TestcaseProposalService
+}
+
+func NewTestcaseProposalHandler() *TestcaseProposalHandler {
+	return &TestcaseProposalHandler{
+		service: service.NewTestcaseProposalService(),
+	}
+}
+
+// Propose 请 AI 建议边界测试数据与数据生成器（管理员，异步执行）
+// POST /api/v1/problem/:id/testcase/ai-propose
+func (h *TestcaseProposalHandler) Propose(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.TestcaseProposeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+			return
+		}
+	}
+
+	batch, err := h.service.Start(id, &req, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	if err := judge.EnqueueTestcaseProposal(batch.ID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, model.Error(http.StatusServiceUnavailable, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage("建议任务已创建", batch))
+}
+
+// List 获取题目最近的 AI 测试数据建议（管理员）
+// GET /api/v1/problem/:id/testcase/ai-proposals
+func (h *TestcaseProposalHandler) List(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	batches, err := h.service.List(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NotFound(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Success(batches))
+}
+
+// Accept 将选中的建议数据追加为题目测试点（管理员）
+// POST /api/v1/problem/:id/testcase/ai-proposals/accept
+func (h *TestcaseProposalHandler) Accept(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.TestcaseProposalReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	accepted, err := h.service.Accept(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage(fmt.Sprintf("已添加 %d 个测试点", accepted), gin.H{"accepted": accepted}))
+}
+
+// Reject 忽略选中的建议数据（管理员）
+// POST /api/v1/problem/:id/testcase/ai-proposals/reject
+func (h *TestcaseProposalHandler) Reject(c *gin.Context) {
+	id := getUintParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequest("题目 ID 无效"))
+		return
+	}
+
+	var req model.TestcaseProposalReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest("参数错误: "+err.Error()))
+		return
+	}
+
+	rejected, err := h.service.Reject(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessMessage(fmt.Sprintf("已忽略 %d 组数据", rejected), gin.H{"rejected": rejected}))
+}
@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"oj-system/internal/model"
+)
+
+const (
+	anthropicVersion   = "2023-06-01"
+	anthropicMaxTokens = 2048
+)
+
+// AnthropicProvider Anthropic Messages API
+type AnthropicProvider struct{}
+
+// NewAnthropicProvider 创建 Anthropic 提供方
+func NewAnthropicProvider() *AnthropicProvider {
+	return &AnthropicProvider{}
+}
+
+// anthropicRequest Messages API 请求结构（system 为独立字段）
+type anthropicRequest struct {
+	Model       string        `json:"model"`
+	System      string        `json:"system,omitempty"`
+	Messages    []ChatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature float64       `json:"temperature"`
+}
+
+// anthropicResponse Messages API 响应结构
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (p *AnthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+func (p *AnthropicProvider) RequiresAPIKey() bool {
+	return true
+}
+
+// Chat 调用 Messages API。该接口没有 JSON 输出模式，
+// 通过预填 assistant 回复的开头 "{" 约束模型直接输出 JSON 对象
+func (p *AnthropicProvider) Chat(_ ChatTask, messages []ChatMessage, settings *model.AISettings) (*ChatResult, error) {
+	reqBody := anthropicRequest{
+		Model:       settings.Model,
+		MaxTokens:   anthropicMaxTokens,
+		Temperature: temperature(settings),
+	}
+	var system []string
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+			continue
+		}
+		reqBody.Messages = append(reqBody.Messages, msg)
+	}
+	reqBody.System = strings.Join(system, "\n\n")
+	reqBody.Messages = append(reqBody.Messages, ChatMessage{Role: "assistant", Content: "{"})
+
+	headers := map[string]string{
+		"x-api-key":         settings.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var resp anthropicResponse
+	body, err := postJSON(settings.APIURL, headers, reqBody, settings.Timeout, &resp)
+	result := &ChatResult{
+		RawResponse:      string(body),
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if resp.Error != nil {
+		return result, fmt.Errorf("API 错误: %s", resp.Error.Message)
+	}
+
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return result, fmt.Errorf("无响应内容")
+	}
+
+	result.Content = "{" + strings.TrimSpace(sb.String())
+	return result, nil
+}
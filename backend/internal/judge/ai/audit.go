@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"oj-system/internal/model"
+)
+
+// auditAttempt 返回记录每次模型调用（提示词、响应、耗时、用量）的回调，并累加到按天汇总的用量统计
+func (c *Client) auditAttempt(submission *model.Submission, problemID uint, settings *model.AISettings, promptLabel string, messages []ChatMessage) attemptFunc {
+	messagesJSON, _ := json.Marshal(messages)
+	return func(attempt int, result *ChatResult, err error, latency time.Duration) {
+		entry := &model.AICallLog{
+			SubmissionID:   submission.ID,
+			ProblemID:      problemID,
+			Provider:       settings.Provider,
+			Model:          settings.Model,
+			PromptTemplate: promptLabel,
+			Attempt:        attempt,
+			Messages:       string(messagesJSON),
+			LatencyMS:      int(latency / time.Millisecond),
+		}
+		if result != nil {
+			entry.Response = result.Content
+			entry.RawResponse = result.RawResponse
+			entry.PromptTokens = result.PromptTokens
+			entry.CompletionTokens = result.CompletionTokens
+			entry.TotalTokens = result.PromptTokens + result.CompletionTokens
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if err := c.logRepo.Create(entry); err != nil {
+			log.Printf("[AI] 保存调用记录失败: %v", err)
+		}
+		c.usage.Record(problemID, submission.CreatedAt, entry.PromptTokens, entry.CompletionTokens)
+	}
+}
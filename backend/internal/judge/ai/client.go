@@ -0,0 +1,287 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// Client AI 判题客户端，按 ai_provider 设置选择具体的服务提供方
+type Client struct {
+	cacheRepo   *repository.AICacheRepository
+	explainRepo *repository.AICompileExplainRepository
+	promptRepo  *repository.AIPromptRepository
+	logRepo     *repository.AICallLogRepository
+	usage       *service.AIUsageService
+}
+
+// NewClient 创建 AI 判题客户端
+func NewClient() *Client {
+	return &Client{
+		cacheRepo:   repository.NewAICacheRepository(),
+		explainRepo: repository.NewAICompileExplainRepository(),
+		promptRepo:  repository.NewAIPromptRepository(),
+		logRepo:     repository.NewAICallLogRepository(),
+		usage:       service.NewAIUsageService(),
+	}
+}
+
+// getSettings 获取当前 AI 设置
+func (c *Client) getSettings() *model.AISettings {
+	return service.GetSettingService().GetAISettings()
+}
+
+// AIAnalysisResult AI 分析结果
+type AIAnalysisResult struct {
+	AlgorithmAnalysis struct {
+		DetectedAlgorithms []string `json:"detected_algorithms"`
+		PrimaryAlgorithm   string   `json:"primary_algorithm"`
+		Confidence         float64  `json:"confidence"`
+		Evidence           string   `json:"evidence"`
+	} `json:"algorithm_analysis"`
+	LanguageFeatures struct {
+		Language              string   `json:"language"`
+		UsedFeatures          []string `json:"used_features"`
+		ForbiddenFeaturesUsed []string `json:"forbidden_features_used"`
+	} `json:"language_features"`
+	RequirementCheck struct {
+		AlgorithmMatch     bool `json:"algorithm_match"`
+		LanguageMatch      bool `json:"language_match"`
+		AllRequirementsMet bool `json:"all_requirements_met"`
+	} `json:"requirement_check"`
+	Summary string `json:"summary"`
+}
+
+// AnalyzeCode 分析提交的代码；相同代码与配置优先复用缓存的分析结果，force 为 true 时忽略缓存重新分析。
+// 每次实际调用模型都会记录审计日志
+func (c *Client) AnalyzeCode(submission *model.Submission, problem *model.Problem, force bool) (*model.AIJudgeResult, error) {
+	code, language := submission.Code, submission.Language
+	settings := c.getSettings()
+	
+	// 检查是否启用 AI 判题
+	if !settings.Enabled {
+		return &model.AIJudgeResult{
+			Enabled: true,
+			Passed:  true,
+			Reason:  "AI 判题功能未启用",
+			Summary: "AI 判题功能未启用",
+			Source:  model.AIJudgeSourceSkipped,
+		}, nil
+	}
+	
+	provider := NewProvider(settings.Provider)
+
+	// 检查 API Key（本地模型无需 Key）
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return &model.AIJudgeResult{
+			Enabled: true,
+			Passed:  true,
+			Reason:  "AI 判题未配置 API Key，已跳过",
+			Summary: "AI 判题未配置 API Key，已跳过",
+			Source:  model.AIJudgeSourceSkipped,
+		}, nil
+	}
+
+	aiConfig := problem.AIJudgeConfig
+	if aiConfig == nil || !aiConfig.Enabled {
+		return nil, nil
+	}
+
+	// 按提示词模板构建消息；缓存键包含渲染后的提示词，题面或模板修改后不会复用旧的结果
+	messages, tpl := c.buildMessages(c.resolvePromptTemplate(aiConfig, settings),
+		&PromptData{Problem: problem, Code: code, Language: language, Config: aiConfig})
+	promptLabel := promptTemplateLabel(tpl)
+	cacheKey := aiCacheKey(problem.ID, code, language, messages, aiConfig, settings, promptLabel)
+	if !force {
+		if cached := c.loadCache(cacheKey); cached != nil {
+			return cached, nil
+		}
+	}
+
+	// 本月 token 预算用完后不再调用模型，按预算策略处理（命中缓存不消耗预算）
+	if budget, err := c.usage.GetBudgetStatus(settings); err != nil {
+		log.Printf("[AI] 获取本月用量失败: %v", err)
+	} else if budget.Exceeded {
+		reason := fmt.Sprintf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+		result := failureResult(settings.BudgetPolicy, reason, 0)
+		result.PromptTemplate = promptLabel
+		return result, nil
+	}
+
+	// 配置了共识投票时向多个模型（或多次采样）发起调用并合并结果
+	if aiConfig.Consensus != nil {
+		result, attempts, complete, err := c.runConsensus(provider, messages, settings, aiConfig, submission, promptLabel)
+		if errors.Is(err, errVoteBudgetExceeded) {
+			result := failureResult(settings.BudgetPolicy, "本月 AI token 预算不足以发起投票", 0)
+			result.PromptTemplate = promptLabel
+			return result, nil
+		}
+		if err != nil {
+			result := failureResult(settings.FailurePolicy, fmt.Sprintf("AI 分析出错: %v", err), attempts)
+			result.PromptTemplate = promptLabel
+			return result, nil
+		}
+		result.PromptTemplate = promptLabel
+		// 部分投票失败时不缓存，下次重新投票
+		if complete {
+			c.saveCache(cacheKey, problem.ID, settings, result)
+		}
+		result.Attempts = attempts
+		return result, nil
+	}
+
+	// 调用 API
+	response, attempts, err := chatWithRetry(provider, ChatTaskJudge, messages, settings,
+		c.auditAttempt(submission, problem.ID, settings, promptLabel, messages))
+	if err != nil {
+		result := failureResult(settings.FailurePolicy, fmt.Sprintf("AI 分析出错: %v", err), attempts)
+		result.PromptTemplate = promptLabel
+		return result, nil
+	}
+
+	// 解析响应（解析失败的结果不缓存）
+	result, err := parseAIResponse(response, aiConfig)
+	if err != nil {
+		result := failureResult(settings.FailurePolicy, fmt.Sprintf("AI 响应解析失败: %v", err), attempts)
+		result.PromptTemplate = promptLabel
+		return result, nil
+	}
+	result.PromptTemplate = promptLabel
+	c.saveCache(cacheKey, problem.ID, settings, result)
+	result.Attempts = attempts
+	return result, nil
+}
+
+// failureResult 按失败策略（或预算用完后的处理策略）生成未能完成 AI 分析时的结果
+func failureResult(policy string, reason string, attempts int) *model.AIJudgeResult {
+	result := &model.AIJudgeResult{
+		Enabled:       true,
+		Error:         reason,
+		FailurePolicy: policy,
+		Attempts:      attempts,
+	}
+	switch policy {
+	case model.AIFailurePolicyFail:
+		result.Passed = false
+		result.Reason = reason + "，按策略视为未满足要求"
+	case model.AIFailurePolicyPending:
+		result.Passed = true // 暂不影响结果，稍后重新分析
+		result.Reason = reason + "，稍后将自动重新分析"
+	default:
+		result.Passed = true // 出错时默认通过，不影响正常判题
+		result.Reason = reason + "，按策略视为满足要求"
+	}
+	result.Summary = result.Reason
+	return result
+}
+
+// aiCacheKey 计算缓存键：hash(题目, 代码, 语言, 渲染后的提示词, 题目 AI 判题配置, 服务商, 模型, 提示词模板版本)
+func aiCacheKey(problemID uint, code, language string, messages []ChatMessage, aiConfig *model.AIJudgeConfig, settings *model.AISettings, promptLabel string) string {
+	configJSON, _ := json.Marshal(aiConfig)
+	messagesJSON, _ := json.Marshal(messages)
+	h := sha256.New()
+	for _, part := range []string{strconv.FormatUint(uint64(problemID), 10), code, language, string(messagesJSON), string(configJSON), settings.Provider, settings.Model, promptLabel} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadCache 读取缓存的分析结果，未命中返回 nil
+func (c *Client) loadCache(key string) *model.AIJudgeResult {
+	cache, err := c.cacheRepo.GetByKey(key)
+	if err != nil || cache.Result == nil {
+		return nil
+	}
+	if err := c.cacheRepo.IncrementHit(cache.ID); err != nil {
+		log.Printf("[AI] 更新缓存命中次数失败: %v", err)
+	}
+	result := *cache.Result
+	result.Cached = true
+	return &result
+}
+
+// saveCache 保存分析结果到缓存
+func (c *Client) saveCache(key string, problemID uint, settings *model.AISettings, result *model.AIJudgeResult) {
+	stored := *result
+	stored.Cached = false
+	cache := &model.AICache{
+		CacheKey:  key,
+		ProblemID: problemID,
+		Provider:  settings.Provider,
+		Model:     settings.Model,
+		Result:    &stored,
+	}
+	if err := c.cacheRepo.Save(cache); err != nil {
+		log.Printf("[AI] 保存分析缓存失败: %v", err)
+	}
+}
+
+// parseAIResponse 解析 AI 响应；解析失败时返回默认通过的结果与错误
+func parseAIResponse(response string, aiConfig *model.AIJudgeConfig) (*model.AIJudgeResult, error) {
+	analysis, err := parseAIAnalysis(response)
+	if err != nil {
+		return &model.AIJudgeResult{
+			Enabled:     true,
+			Passed:      true, // 解析失败时默认通过
+			Reason:      fmt.Sprintf("AI 响应解析失败: %v", err),
+			Summary:     fmt.Sprintf("AI 响应解析失败: %v", err),
+		}, err
+	}
+	return analysisResult(analysis, aiConfig), nil
+}
+
+// parseAIAnalysis 解析模型输出的 JSON 分析结果
+func parseAIAnalysis(response string) (*AIAnalysisResult, error) {
+	var analysis AIAnalysisResult
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &analysis); err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// analysisResult 将模型的分析结果转换为判题结果
+func analysisResult(analysis *AIAnalysisResult, aiConfig *model.AIJudgeConfig) *model.AIJudgeResult {
+	result := &model.AIJudgeResult{
+		Enabled:           true,
+		Passed:            analysis.RequirementCheck.AllRequirementsMet,
+		AlgorithmDetected: analysis.AlgorithmAnalysis.PrimaryAlgorithm,
+		LanguageCheck:     "passed",
+		Reason:            analysis.Summary,
+		Summary:           analysis.Summary,
+		Confidence:        min(max(analysis.AlgorithmAnalysis.Confidence, 0), 1),
+	}
+
+	if !analysis.RequirementCheck.LanguageMatch {
+		result.LanguageCheck = "failed"
+	}
+
+	if !result.Passed {
+		result.Details = &model.AIJudgeDetails{
+			Required:   aiConfig.RequiredAlgorithm,
+			Detected:   analysis.AlgorithmAnalysis.PrimaryAlgorithm,
+			Confidence: analysis.AlgorithmAnalysis.Confidence,
+		}
+	}
+
+	return result
+}
+
+// extractJSONObject 去掉模型可能附带的 Markdown 代码块等多余内容，只保留最外层 JSON 对象
+func extractJSONObject(response string) string {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return response
+	}
+	return response[start : end+1]
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+)
+
+const (
+	// compileExplainPromptLabel 审计日志中记录的提示词名称
+	compileExplainPromptLabel = "compile_explain"
+	// compileExplainTaskTitle 编译错误解释任务的标题
+	compileExplainTaskTitle = "# 任务：解释编译错误"
+	// maxCompileErrorLength 发送给模型的编译输出长度上限，之后的错误大多由第一个错误引起
+	maxCompileErrorLength = 4000
+	maxExplanationLength  = 500
+)
+
+const compileExplainSystemPrompt = "你是编程入门课程的助教，负责把编译器输出的错误信息翻译成初学者能看懂的中文解释。" +
+	"请严格按照要求的 JSON 格式输出，不要输出其他内容。"
+
+// compileErrorLineRes 从编译输出中定位第一个错误所在的行：gcc/g++ 与 go 为 文件:行:列，javac 为 文件:行: error，Python 为 line N
+var compileErrorLineRes = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\S*?[\w.]+\.(?:c|cc|cpp|cxx|h|hpp):(\d+):\d+:\s*(?:fatal\s+)?error`),
+	regexp.MustCompile(`(?m)^\S*?[\w.]+\.java:(\d+):\s*error`),
+	regexp.MustCompile(`(?m)^\S*?[\w.]+\.go:(\d+):\d+:`),
+	regexp.MustCompile(`line (\d+)`),
+}
+
+// CompileErrorLine 编译输出中第一个错误所在的行，无法确定时返回 0
+func CompileErrorLine(compileError string) int {
+	for _, re := range compileErrorLineRes {
+		if m := re.FindStringSubmatch(compileError); m != nil {
+			line := 0
+			fmt.Sscanf(m[1], "%d", &line)
+			return line
+		}
+	}
+	return 0
+}
+
+// compileExplainResponse 模型输出的解释
+type compileExplainResponse struct {
+	Explanation string `json:"explanation"`
+	Line        int    `json:"line"`
+}
+
+// ExplainCompileError 把编译错误翻译成通俗的解释；相同语言、相同编译输出（服务商与模型也相同）复用缓存的解释。
+// 解释只基于编译输出（其中已包含出错的代码行），因此可以按错误文本缓存
+func (c *Client) ExplainCompileError(submission *model.Submission) (*model.CompileExplanation, error) {
+	settings := c.getSettings()
+	if !settings.Enabled || !settings.CompileExplain {
+		return nil, errors.New("编译错误解释未启用")
+	}
+	compileError := strings.TrimSpace(submission.CompileError)
+	if compileError == "" {
+		return nil, errors.New("没有编译错误信息")
+	}
+	if utf8.RuneCountInString(compileError) > maxCompileErrorLength {
+		compileError = string([]rune(compileError)[:maxCompileErrorLength])
+	}
+
+	cacheKey := compileExplainCacheKey(submission.Language, compileError, settings)
+	if cache, err := c.explainRepo.GetByKey(cacheKey); err == nil {
+		if err := c.explainRepo.IncrementHit(cache.ID); err != nil {
+			log.Printf("[AI] 更新缓存命中次数失败: %v", err)
+		}
+		return &model.CompileExplanation{
+			Content:   cache.Content,
+			Line:      cache.Line,
+			Model:     cache.Model,
+			Cached:    true,
+			CreatedAt: time.Now(),
+		}, nil
+	}
+
+	provider := NewProvider(settings.Provider)
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return nil, errors.New("未配置 API Key")
+	}
+	if budget, err := c.usage.GetBudgetStatus(settings); err == nil && budget.Exceeded {
+		return nil, fmt.Errorf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: compileExplainSystemPrompt},
+		{Role: "user", Content: buildCompileExplainPrompt(submission.Language, compileError)},
+	}
+	response, _, err := chatWithRetry(provider, ChatTaskCompileExplain, messages, settings,
+		c.auditAttempt(submission, submission.ProblemID, settings, compileExplainPromptLabel, messages))
+	if err != nil {
+		return nil, fmt.Errorf("AI 调用失败: %v", err)
+	}
+
+	var parsed compileExplainResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &parsed); err != nil {
+		return nil, fmt.Errorf("AI 响应解析失败: %v", err)
+	}
+	content := strings.TrimSpace(parsed.Explanation)
+	if content == "" {
+		return nil, errors.New("AI 未给出解释")
+	}
+	if utf8.RuneCountInString(content) > maxExplanationLength {
+		content = string([]rune(content)[:maxExplanationLength]) + "…"
+	}
+	// 优先使用从编译输出中解析出的行号，模型给出的行号仅作补充
+	line := CompileErrorLine(compileError)
+	if line == 0 && parsed.Line > 0 {
+		line = parsed.Line
+	}
+
+	if err := c.explainRepo.Save(&model.AICompileExplainCache{
+		CacheKey: cacheKey,
+		Language: submission.Language,
+		Provider: settings.Provider,
+		Model:    settings.Model,
+		Content:  content,
+		Line:     line,
+	}); err != nil {
+		log.Printf("[AI] 保存编译错误解释缓存失败: %v", err)
+	}
+	return &model.CompileExplanation{
+		Content:   content,
+		Line:      line,
+		Model:     settings.Model,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// compileExplainCacheKey 计算缓存键：hash(语言, 编译输出, 服务商, 模型)
+func compileExplainCacheKey(language, compileError string, settings *model.AISettings) string {
+	h := sha256.New()
+	for _, part := range []string{language, compileError, settings.Provider, settings.Model} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func buildCompileExplainPrompt(language, compileError string) string {
+	return compileExplainTaskTitle + "\n" +
+		"一名编程初学者的 " + language + " 代码没有通过编译。请用通俗的中文解释下面的编译错误：\n\n" +
+		"# 编译器输出\n```\n" + compileError + "\n```\n\n" +
+		`# 解释要求
+- 只解释第一个错误（后面的错误往往由它引起），说明它出现在第几行、是什么意思、通常是什么原因造成的
+- 面向初学者，不使用编译器术语，必要时举一个简短的例子说明正确的写法
+- 不要改写或给出学生的完整代码，不超过 200 字
+
+# 输出要求
+请严格按照以下 JSON 格式输出：
+
+{
+    "explanation": "解释内容",
+    "line": 出错的行号（无法确定时为 0）
+}`
+}
@@ -0,0 +1,279 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"oj-system/internal/model"
+)
+
+const (
+	maxConsensusModels  = 5
+	maxConsensusSamples = 5
+	maxConsensusVotes   = 9
+	// consensusSampleTemperature 同一模型多次采样时提高温度，否则各次输出几乎相同
+	consensusSampleTemperature = 0.7
+	// voteCompletionEstimate 预占预算时估算的一票输出 token 数（JSON 分析结果）
+	voteCompletionEstimate = 1024
+)
+
+// errVoteBudgetExceeded 本月剩余预算不足以再发起一票
+var errVoteBudgetExceeded = errors.New("本月 AI token 预算不足，未发起调用")
+
+// voteReservation 进行中的投票预占的 token 数：各票并发发起，只在开始前检查一次预算会让多票同时通过检查后一起超支，
+// 因此每票发起前按估算用量预占，调用结束、实际用量入账后释放
+var voteReservation struct {
+	mu     sync.Mutex
+	tokens int64
+}
+
+// reserveVoteBudget 为一票预占 tokens；本月已用量加上已预占的用量超出预算时返回 errVoteBudgetExceeded。
+// 未设置预算时不预占，返回的 release 在调用结束后调用
+func (c *Client) reserveVoteBudget(settings *model.AISettings, tokens int64) (func(), error) {
+	if settings.MonthlyTokenBudget <= 0 {
+		return func() {}, nil
+	}
+	voteReservation.mu.Lock()
+	defer voteReservation.mu.Unlock()
+
+	budget, err := c.usage.GetBudgetStatus(settings)
+	if err != nil {
+		log.Printf("[AI] 获取本月用量失败: %v", err)
+		return func() {}, nil
+	}
+	if budget.Used+voteReservation.tokens+tokens > budget.Budget {
+		return nil, errVoteBudgetExceeded
+	}
+	voteReservation.tokens += tokens
+	return func() {
+		voteReservation.mu.Lock()
+		voteReservation.tokens -= tokens
+		voteReservation.mu.Unlock()
+	}, nil
+}
+
+// estimateVoteTokens 估算一票的 token 用量：提示词加上预计的输出
+func estimateVoteTokens(messages []ChatMessage) int64 {
+	tokens := voteCompletionEstimate
+	for _, msg := range messages {
+		tokens += estimateTokens(msg.Content)
+	}
+	return int64(tokens)
+}
+
+// voteSpec 一票使用的模型与采样序号
+type voteSpec struct {
+	model  string
+	sample int
+}
+
+// consensusPlan 按共识配置展开每一票，总票数不超过 maxConsensusVotes
+func consensusPlan(cfg *model.AIConsensusConfig, settings *model.AISettings) []voteSpec {
+	var models []string
+	for _, m := range cfg.Models {
+		m = strings.TrimSpace(m)
+		if m != "" && len(models) < maxConsensusModels {
+			models = append(models, m)
+		}
+	}
+	if len(models) == 0 {
+		models = []string{settings.Model}
+	}
+	samples := cfg.Samples
+	if samples < 1 {
+		samples = 1
+	}
+	if samples > maxConsensusSamples {
+		samples = maxConsensusSamples
+	}
+
+	var plan []voteSpec
+	for sample := 1; sample <= samples; sample++ {
+		for _, m := range models {
+			if len(plan) < maxConsensusVotes {
+				plan = append(plan, voteSpec{model: m, sample: sample})
+			}
+		}
+	}
+	return plan
+}
+
+// consensusFailed "未满足"票是否达到判定比例：未配置比例时需超过半数
+func consensusFailed(failVotes, validVotes int, failRatio float64) bool {
+	if validVotes == 0 {
+		return false
+	}
+	if failRatio <= 0 || failRatio > 1 {
+		return failVotes*2 > validVotes
+	}
+	return float64(failVotes) >= failRatio*float64(validVotes)
+}
+
+// runConsensus 并发发起所有投票并合并结果。每票发起前预占预算，预算不足的票不发起、记为失败；
+// 全部投票失败时返回错误（全部因预算不足未发起时为 errVoteBudgetExceeded）；
+// complete 表示所有投票均成功，仅此时结果可缓存
+func (c *Client) runConsensus(provider AIProvider, messages []ChatMessage, settings *model.AISettings,
+	aiConfig *model.AIJudgeConfig, submission *model.Submission, promptLabel string) (*model.AIJudgeResult, int, bool, error) {
+	cfg := aiConfig.Consensus
+	plan := consensusPlan(cfg, settings)
+	voteTokens := estimateVoteTokens(messages)
+
+	votes := make([]model.AIJudgeVote, len(plan))
+	results := make([]*model.AIJudgeResult, len(plan))
+	attempts := make([]int, len(plan))
+	var wg sync.WaitGroup
+	for i, spec := range plan {
+		wg.Add(1)
+		go func(i int, spec voteSpec) {
+			defer wg.Done()
+			voteSettings := *settings
+			voteSettings.Model = spec.model
+			if spec.sample > 1 {
+				voteSettings.Temperature = consensusSampleTemperature
+			}
+
+			vote := model.AIJudgeVote{Model: spec.model, Sample: spec.sample}
+			release, err := c.reserveVoteBudget(settings, voteTokens)
+			if err != nil {
+				vote.Error = err.Error()
+				votes[i] = vote
+				return
+			}
+			defer release()
+			response, n, err := chatWithRetry(provider, ChatTaskJudge, messages, &voteSettings,
+				c.auditAttempt(submission, submission.ProblemID, &voteSettings, promptLabel, messages))
+			attempts[i] = n
+			if err != nil {
+				vote.Error = err.Error()
+				votes[i] = vote
+				return
+			}
+			analysis, err := parseAIAnalysis(response)
+			if err != nil {
+				vote.Error = fmt.Sprintf("AI 响应解析失败: %v", err)
+				votes[i] = vote
+				return
+			}
+			result := analysisResult(analysis, aiConfig)
+			vote.Passed = result.Passed
+			vote.Confidence = analysis.AlgorithmAnalysis.Confidence
+			vote.Counted = !vote.Passed && vote.Confidence >= cfg.MinConfidence
+			vote.AlgorithmDetected = result.AlgorithmDetected
+			vote.Summary = result.Summary
+			votes[i] = vote
+			results[i] = result
+		}(i, spec)
+	}
+	wg.Wait()
+
+	totalAttempts := 0
+	for _, n := range attempts {
+		totalAttempts += n
+	}
+	if totalAttempts == 0 {
+		skipped := 0
+		for _, vote := range votes {
+			if vote.Error == errVoteBudgetExceeded.Error() {
+				skipped++
+			}
+		}
+		if skipped == len(votes) {
+			return nil, 0, false, errVoteBudgetExceeded
+		}
+	}
+
+	result, err := combineVotes(votes, results, cfg)
+	if err != nil {
+		return nil, totalAttempts, false, err
+	}
+	complete := true
+	for _, vote := range votes {
+		if vote.Error != "" {
+			complete = false
+		}
+	}
+	return result, totalAttempts, complete, nil
+}
+
+// combineVotes 合并投票：达到判定比例时采用置信度最高的"未满足"票，否则判定满足要求
+func combineVotes(votes []model.AIJudgeVote, results []*model.AIJudgeResult, cfg *model.AIConsensusConfig) (*model.AIJudgeResult, error) {
+	valid, failVotes, lowConfidence := 0, 0, 0
+	var firstErr string
+	for _, vote := range votes {
+		switch {
+		case vote.Error != "":
+			if firstErr == "" {
+				firstErr = vote.Error
+			}
+		case vote.Counted:
+			valid++
+			failVotes++
+		default:
+			valid++
+			if !vote.Passed {
+				lowConfidence++
+			}
+		}
+	}
+	if valid == 0 {
+		return nil, fmt.Errorf("全部 %d 票调用失败: %s", len(votes), firstErr)
+	}
+
+	consensus := fmt.Sprintf("%d/%d 票认为未满足要求", failVotes, valid)
+	if lowConfidence > 0 {
+		consensus += fmt.Sprintf("（另有 %d 票置信度低于 %.2f 未计入）", lowConfidence, cfg.MinConfidence)
+	}
+	if failed := len(votes) - valid; failed > 0 {
+		consensus += fmt.Sprintf("，%d 票调用失败", failed)
+	}
+
+	// 候选结果：判定未满足时取置信度最高的计入票，否则取置信度最高的满足票
+	order := make([]int, 0, len(votes))
+	for i := range votes {
+		if results[i] != nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return votes[order[a]].Confidence > votes[order[b]].Confidence
+	})
+
+	var result model.AIJudgeResult
+	if consensusFailed(failVotes, valid, cfg.FailRatio) {
+		for _, i := range order {
+			if votes[i].Counted {
+				result = *results[i]
+				break
+			}
+		}
+		result.Consensus = "判定未满足要求：" + consensus
+	} else {
+		picked := false
+		for _, i := range order {
+			if votes[i].Passed {
+				result = *results[i]
+				picked = true
+				break
+			}
+		}
+		if !picked {
+			// 只有置信度不足的"未满足"票
+			result = *results[order[0]]
+			result.Reason = "判定未满足要求的置信度不足，视为满足要求"
+			result.Summary = result.Reason
+		}
+		result.Passed = true
+		result.Details = nil
+		if failVotes+lowConfidence > 0 {
+			result.Consensus = "未达到判定比例，视为满足要求：" + consensus
+		} else {
+			result.Consensus = "判定满足要求：" + consensus
+		}
+	}
+	result.Votes = votes
+	return &result, nil
+}
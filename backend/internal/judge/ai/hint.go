@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+)
+
+const (
+	// hintPromptLabel 审计日志中记录的提示词名称
+	hintPromptLabel = "hint"
+	// hintTaskTitle 学习提示任务的标题
+	hintTaskTitle = "# 任务：学习提示"
+	maxHintLength = 300
+)
+
+const hintSystemPrompt = "你是编程课程的助教，负责在学生的提交未通过时给出启发式的学习提示。" +
+	"你不能给出正确代码、代码片段或完整解法，只能引导学生自己发现问题。请严格按照要求的 JSON 格式输出，不要输出其他内容。"
+
+// hintCategoryDescriptions 各失败类型的说明，帮助模型把提示聚焦在对应的问题方向上
+var hintCategoryDescriptions = map[string]string{
+	model.StatusWrongAnswer:         "答案错误：程序输出与期望不符，常见原因有边界情况、特殊输入、数据范围溢出或思路错误",
+	model.StatusTimeLimitExceeded:   "运行超时：程序在时间限制内没有结束，常见原因有算法复杂度过高、死循环或输入输出过慢",
+	model.StatusMemoryLimitExceeded: "内存超限：程序使用的内存超过限制，常见原因有数组开得过大、递归过深或存储了不必要的数据",
+	model.StatusRuntimeError:        "运行错误：程序异常退出，常见原因有数组越界、除以零、空指针或栈溢出",
+}
+
+// HintCategorySupported 是否为可以生成学习提示的失败类型（编译错误、系统错误等不生成）
+func HintCategorySupported(status string) bool {
+	_, ok := hintCategoryDescriptions[status]
+	return ok
+}
+
+// hintResponse 模型输出的提示
+type hintResponse struct {
+	Hint string `json:"hint"`
+}
+
+// codeBlockRe 模型违反要求输出的代码块
+var codeBlockRe = regexp.MustCompile("(?s)```.*?(```|$)")
+
+// GenerateHint 根据题目、代码与失败类型生成学习提示。只提供失败类型与通过的测试点数，
+// 不向模型提供测试数据。每次实际调用模型都会记录审计日志并计入用量
+func (c *Client) GenerateHint(submission *model.Submission, problem *model.Problem, category string, passed, total int) (*model.AIHint, error) {
+	settings := c.getSettings()
+	if !settings.Enabled {
+		return nil, errors.New("AI 功能未启用")
+	}
+	provider := NewProvider(settings.Provider)
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return nil, errors.New("未配置 API Key")
+	}
+	if budget, err := c.usage.GetBudgetStatus(settings); err == nil && budget.Exceeded {
+		return nil, fmt.Errorf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: hintSystemPrompt},
+		{Role: "user", Content: buildHintPrompt(submission, problem, category, passed, total)},
+	}
+	response, _, err := chatWithRetry(provider, ChatTaskHint, messages, settings,
+		c.auditAttempt(submission, problem.ID, settings, hintPromptLabel, messages))
+	if err != nil {
+		return nil, fmt.Errorf("AI 调用失败: %v", err)
+	}
+
+	var parsed hintResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &parsed); err != nil {
+		return nil, fmt.Errorf("AI 响应解析失败: %v", err)
+	}
+	content := sanitizeHint(parsed.Hint)
+	if content == "" {
+		return nil, errors.New("AI 未给出提示")
+	}
+	return &model.AIHint{
+		Category:  category,
+		Passed:    passed,
+		Total:     total,
+		Content:   content,
+		Model:     settings.Model,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func buildHintPrompt(submission *model.Submission, problem *model.Problem, category string, passed, total int) string {
+	var b strings.Builder
+	b.WriteString(hintTaskTitle + "\n")
+	b.WriteString("学生的提交没有通过评测。请给出一条简短的学习提示，帮助学生自己找到问题所在。\n\n")
+	b.WriteString("# 题目信息\n")
+	fmt.Fprintf(&b, "- 题目标题：%s\n", problem.Title)
+	fmt.Fprintf(&b, "- 题目描述：%s\n", problem.Description)
+	if problem.InputFormat != "" {
+		fmt.Fprintf(&b, "- 输入格式：%s\n", problem.InputFormat)
+	}
+	if problem.OutputFormat != "" {
+		fmt.Fprintf(&b, "- 输出格式：%s\n", problem.OutputFormat)
+	}
+	fmt.Fprintf(&b, "- 时间限制：%d ms，内存限制：%d MB\n\n", problem.TimeLimit, problem.MemoryLimit)
+	b.WriteString("# 评测结果\n")
+	fmt.Fprintf(&b, "- 失败类型：%s\n", hintCategoryDescriptions[category])
+	fmt.Fprintf(&b, "- 通过测试点：%d/%d\n\n", passed, total)
+	fmt.Fprintf(&b, "# 学生代码（%s）\n```%s\n%s\n```\n\n", submission.Language, submission.Language, submission.Code)
+	b.WriteString(`# 提示要求
+- 不要给出正确代码、代码片段、伪代码或完整解法，不要直接指出需要修改的具体行
+- 围绕失败类型提示可能的问题方向（如需要考虑的边界情况、复杂度与数据范围），可以用提问的方式引导
+- 使用中文，不超过 150 字
+
+# 输出要求
+请严格按照以下 JSON 格式输出：
+
+{
+    "hint": "提示内容"
+}`)
+	return b.String()
+}
+
+// sanitizeHint 去掉模型违反要求给出的代码块，并限制提示长度
+func sanitizeHint(hint string) string {
+	hint = strings.TrimSpace(codeBlockRe.ReplaceAllString(hint, ""))
+	if utf8.RuneCountInString(hint) > maxHintLength {
+		hint = string([]rune(hint)[:maxHintLength]) + "…"
+	}
+	return hint
+}
@@ -0,0 +1,411 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+)
+
+// ProviderMock 确定性的模拟提供方，不访问网络，用于离线测试
+const ProviderMock = "mock"
+
+// MockRule 模拟分析规则：代码匹配 Pattern 时视为使用了 Algorithm
+type MockRule struct {
+	Pattern   string   `json:"pattern"`
+	Algorithm string   `json:"algorithm"`
+	Aliases   []string `json:"aliases,omitempty"` // 与题目要求的算法比对时使用的别名
+	Passed    *bool    `json:"passed,omitempty"`  // 直接指定是否满足要求，为空时按题目要求判断
+	Summary   string   `json:"summary,omitempty"` // 固定的总结内容
+	Error     string   `json:"error,omitempty"`   // 非空时模拟调用失败
+
+	re *regexp.Regexp
+}
+
+// defaultMockRules 内置规则，覆盖常见的算法特征
+var defaultMockRules = []MockRule{
+	{Pattern: `(?i)\bdp\s*\[|\bmemo\b|lru_cache`, Algorithm: "动态规划", Aliases: []string{"dp", "dynamic programming", "记忆化搜索"}},
+	{Pattern: `lower_bound|upper_bound|bisect|sort\.Search|Arrays\.binarySearch|\(\s*(l|lo|left)\s*\+\s*(r|hi|right)\s*\)\s*(/\s*2|>>\s*1)`, Algorithm: "二分查找", Aliases: []string{"二分", "binary search"}},
+	{Pattern: `(?i)\bbfs\b|popleft|std::queue|\bqueue<`, Algorithm: "广度优先搜索", Aliases: []string{"bfs", "宽度优先搜索"}},
+	{Pattern: `(?i)\bdfs\b`, Algorithm: "深度优先搜索", Aliases: []string{"dfs", "回溯"}},
+	{Pattern: `priority_queue|heapq|container/heap|PriorityQueue`, Algorithm: "堆", Aliases: []string{"优先队列", "heap", "dijkstra"}},
+	{Pattern: `(?i)\bfind\s*\(.*\)\s*[^;\n]*\bparent\b|\bunion\s*\(`, Algorithm: "并查集", Aliases: []string{"union find", "dsu"}},
+	{Pattern: `\bsort\s*\(|\bsorted\s*\(|Arrays\.sort|sort\.(Ints|Slice)`, Algorithm: "排序", Aliases: []string{"sort"}},
+}
+
+var (
+	mockPromptAlgorithm = regexp.MustCompile(`必须使用的算法[：:]\s*(.+)`)
+	mockPromptLanguages = regexp.MustCompile(`必须使用的编程语言[：:]\s*(.+)`)
+	mockPromptForbidden = regexp.MustCompile(`禁止使用的特性[：:]\s*(.+)`)
+	mockPromptCode      = regexp.MustCompile("(?s)```(\\w*)\\n(.*)\\n```")
+)
+
+// MockProvider 基于规则的模拟提供方：解析 Prompt 中的题目要求与代码，按正则规则给出分析结果
+type MockProvider struct {
+	rules []MockRule // 为空时从 api_url 指定的规则文件加载，未配置则使用内置规则
+}
+
+// NewMockProvider 创建模拟提供方，rules 需先经 LoadMockRules 加载
+func NewMockProvider(rules []MockRule) *MockProvider {
+	return &MockProvider{rules: rules}
+}
+
+func (p *MockProvider) Name() string {
+	return ProviderMock
+}
+
+func (p *MockProvider) RequiresAPIKey() bool {
+	return false
+}
+
+// Chat 返回与真实模型相同格式的 JSON 分析结果，token 用量按字符数估算
+func (p *MockProvider) Chat(task ChatTask, messages []ChatMessage, settings *model.AISettings) (*ChatResult, error) {
+	rules := p.rules
+	if len(rules) == 0 {
+		var err error
+		if rules, err = LoadMockRules(settings.APIURL); err != nil {
+			return nil, err
+		}
+	}
+
+	var prompt strings.Builder
+	promptTokens := 0
+	for _, msg := range messages {
+		promptTokens += estimateTokens(msg.Content)
+		if msg.Role == "user" {
+			prompt.WriteString(msg.Content)
+			prompt.WriteString("\n")
+		}
+	}
+
+	var payload interface{}
+	switch task {
+	case ChatTaskHint:
+		payload = mockHint(prompt.String())
+	case ChatTaskCompileExplain:
+		payload = mockCompileExplain(prompt.String())
+	case ChatTaskTestcase:
+		payload = mockTestcases(prompt.String())
+	case ChatTaskPlagiarism:
+		payload = mockPlagiarism(prompt.String())
+	case ChatTaskTranslate:
+		payload = mockTranslate(prompt.String())
+	default:
+		analysis, err := mockAnalyze(prompt.String(), rules)
+		if err != nil {
+			return nil, err
+		}
+		payload = analysis
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatResult{
+		Content:          string(data),
+		RawResponse:      string(data),
+		PromptTokens:     promptTokens,
+		CompletionTokens: estimateTokens(string(data)),
+	}, nil
+}
+
+// DetectChatTask 按提示词中的任务标题推断任务类型，只用于通过 HTTP 接收请求、拿不到任务类型的
+// 独立模拟服务（cmd/mockai）；进程内调用由调用方直接传入任务类型
+func DetectChatTask(messages []ChatMessage) ChatTask {
+	titles := []struct {
+		title string
+		task  ChatTask
+	}{
+		{hintTaskTitle, ChatTaskHint},
+		{compileExplainTaskTitle, ChatTaskCompileExplain},
+		{testcaseTaskTitle, ChatTaskTestcase},
+		{plagiarismTaskTitle, ChatTaskPlagiarism},
+		{translateTaskTitle, ChatTaskTranslate},
+	}
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		for _, t := range titles {
+			if strings.HasPrefix(msg.Content, t.title) {
+				return t.task
+			}
+		}
+	}
+	return ChatTaskJudge
+}
+
+// estimateTokens 粗略估算 token 数（约 4 个字符一个 token）
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+var mockRuleCache = struct {
+	mu    sync.Mutex
+	rules map[string][]MockRule
+}{rules: make(map[string][]MockRule)}
+
+// LoadMockRules 加载规则文件（JSON 数组）；path 为空时返回内置规则
+func LoadMockRules(path string) ([]MockRule, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "file://")
+
+	mockRuleCache.mu.Lock()
+	defer mockRuleCache.mu.Unlock()
+	if rules, ok := mockRuleCache.rules[path]; ok {
+		return rules, nil
+	}
+
+	rules := defaultMockRules
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取模拟规则文件失败: %v", err)
+		}
+		rules = nil
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("解析模拟规则文件失败: %v", err)
+		}
+	}
+	compiled, err := compileMockRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	mockRuleCache.rules[path] = compiled
+	return compiled, nil
+}
+
+func compileMockRules(rules []MockRule) ([]MockRule, error) {
+	compiled := make([]MockRule, len(rules))
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("模拟规则 %d 的正则无效: %v", i+1, err)
+		}
+		rule.re = re
+		compiled[i] = rule
+	}
+	return compiled, nil
+}
+
+// mockAnalyze 依次匹配规则，第一个命中的规则决定主要算法
+func mockAnalyze(prompt string, rules []MockRule) (*AIAnalysisResult, error) {
+	required := mockPromptField(mockPromptAlgorithm, prompt)
+	language, code := "", prompt
+	if m := mockPromptCode.FindStringSubmatch(prompt); m != nil {
+		language, code = m[1], m[2]
+	}
+
+	var analysis AIAnalysisResult
+	analysis.LanguageFeatures.Language = language
+	analysis.AlgorithmAnalysis.DetectedAlgorithms = []string{}
+	analysis.LanguageFeatures.UsedFeatures = []string{}
+	analysis.LanguageFeatures.ForbiddenFeaturesUsed = []string{}
+
+	var primary *MockRule
+	algorithmMatch := required == ""
+	for i := range rules {
+		rule := &rules[i]
+		if rule.re == nil || !rule.re.MatchString(code) {
+			continue
+		}
+		if rule.Error != "" {
+			return nil, errors.New(rule.Error)
+		}
+		analysis.AlgorithmAnalysis.DetectedAlgorithms = append(analysis.AlgorithmAnalysis.DetectedAlgorithms, rule.Algorithm)
+		if primary == nil {
+			primary = rule
+		}
+		if !algorithmMatch && mockAlgorithmMatches(required, rule) {
+			algorithmMatch = true
+		}
+	}
+
+	languageMatch := true
+	if langs := mockPromptField(mockPromptLanguages, prompt); langs != "" {
+		languageMatch = false
+		for _, lang := range strings.Split(langs, "、") {
+			if strings.EqualFold(strings.TrimSpace(lang), language) {
+				languageMatch = true
+			}
+		}
+	}
+
+	if features := mockPromptField(mockPromptForbidden, prompt); features != "" {
+		for _, feature := range strings.Split(features, ",") {
+			feature = strings.TrimSpace(feature)
+			if feature != "" && strings.Contains(code, feature) {
+				analysis.LanguageFeatures.ForbiddenFeaturesUsed = append(analysis.LanguageFeatures.ForbiddenFeaturesUsed, feature)
+			}
+		}
+	}
+
+	met := algorithmMatch && languageMatch && len(analysis.LanguageFeatures.ForbiddenFeaturesUsed) == 0
+	summary := ""
+	if primary != nil {
+		analysis.AlgorithmAnalysis.PrimaryAlgorithm = primary.Algorithm
+		analysis.AlgorithmAnalysis.Confidence = 1
+		analysis.AlgorithmAnalysis.Evidence = fmt.Sprintf("代码匹配规则 %s", primary.Pattern)
+		if primary.Passed != nil {
+			met = *primary.Passed
+		}
+		summary = primary.Summary
+	} else {
+		analysis.AlgorithmAnalysis.PrimaryAlgorithm = "未识别"
+		analysis.AlgorithmAnalysis.Evidence = "没有匹配的规则"
+	}
+
+	if summary == "" {
+		switch {
+		case met:
+			summary = "代码满足题目要求（模拟分析）"
+		case !languageMatch:
+			summary = "未使用题目要求的编程语言（模拟分析）"
+		case len(analysis.LanguageFeatures.ForbiddenFeaturesUsed) > 0:
+			summary = "使用了禁止的特性：" + strings.Join(analysis.LanguageFeatures.ForbiddenFeaturesUsed, ", ") + "（模拟分析）"
+		default:
+			summary = fmt.Sprintf("未检测到要求的算法 %s（模拟分析）", required)
+		}
+	}
+
+	analysis.RequirementCheck.AlgorithmMatch = algorithmMatch
+	analysis.RequirementCheck.LanguageMatch = languageMatch
+	analysis.RequirementCheck.AllRequirementsMet = met
+	analysis.Summary = summary
+	return &analysis, nil
+}
+
+// mockHints 各失败类型对应的固定学习提示
+var mockHints = map[string]string{
+	model.StatusWrongAnswer:         "想一想题目中的边界情况：最小、最大的输入和特殊值下，你的程序输出是否正确？（模拟提示）",
+	model.StatusTimeLimitExceeded:   "估算一下你的算法在最大数据范围下的运算次数，是否能在时间限制内完成？（模拟提示）",
+	model.StatusMemoryLimitExceeded: "检查一下程序中最大的数组或容器，是否真的需要存下所有数据？（模拟提示）",
+	model.StatusRuntimeError:        "检查数组下标与除数的取值范围，以及递归的深度是否可能过大。（模拟提示）",
+}
+
+// mockHint 按 Prompt 中的失败类型返回固定的学习提示
+func mockHint(prompt string) *hintResponse {
+	for status, description := range hintCategoryDescriptions {
+		if strings.Contains(prompt, description) {
+			return &hintResponse{Hint: mockHints[status]}
+		}
+	}
+	return &hintResponse{Hint: "再仔细读一遍题目，对照样例检查你的思路。（模拟提示）"}
+}
+
+// mockCompileExplain 复述第一条错误信息作为解释
+func mockCompileExplain(prompt string) *compileExplainResponse {
+	line := CompileErrorLine(prompt)
+	message := "编译器没有给出明确的错误位置"
+	for _, text := range strings.Split(prompt, "\n") {
+		if i := strings.Index(text, "error:"); i >= 0 {
+			message = strings.TrimSpace(text[i+len("error:"):])
+			break
+		}
+	}
+	return &compileExplainResponse{
+		Explanation: fmt.Sprintf("第 %d 行有编译错误：%s。请检查这一行及其上一行的语法。（模拟解释）", line, message),
+		Line:        line,
+	}
+}
+
+var (
+	mockSampleInput = regexp.MustCompile("(?s)## 样例输入 1\n```\n(.*?)\n```")
+	mockInteger     = regexp.MustCompile(`-?\d+`)
+)
+
+// mockTestcases 把第一组样例输入中的整数分别替换为 0、1、-1 与 10^9 作为边界数据，并给出固定的 Python 生成器
+func mockTestcases(prompt string) *testcaseResponse {
+	var resp testcaseResponse
+	sample := "1 1"
+	if m := mockSampleInput.FindStringSubmatch(prompt); m != nil {
+		sample = m[1]
+	}
+	for _, v := range []struct{ value, description string }{
+		{"0", "全部为 0"},
+		{"1", "全部为 1（最小正数）"},
+		{"-1", "全部为 -1（负数）"},
+		{"1000000000", "全部为 10^9（检查溢出）"},
+	} {
+		resp.Cases = append(resp.Cases, proposedCase{
+			Description: v.description + "（模拟数据）",
+			Input:       mockInteger.ReplaceAllString(sample, v.value),
+		})
+	}
+	resp.Generator = &proposedGenerator{
+		Language: "python",
+		Code:     "import random\nimport sys\n\nrandom.seed(int(sys.argv[1]))\nprint(random.randint(-10**9, 10**9), random.randint(-10**9, 10**9))\n",
+		Args:     []string{"1", "2", "3"},
+	}
+	return &resp
+}
+
+var mockSimilarity = regexp.MustCompile(`指纹相似度：(\d+)%`)
+
+// mockPlagiarism 只按指纹相似度给出结论：不低于 90% 认定抄袭，不低于 70% 为可疑，其余视为独立完成
+func mockPlagiarism(prompt string) *plagiarismResponse {
+	similarity := 0
+	if m := mockSimilarity.FindStringSubmatch(prompt); m != nil {
+		similarity, _ = strconv.Atoi(m[1])
+	}
+	switch {
+	case similarity >= 90:
+		return &plagiarismResponse{Verdict: model.PlagiarismVerdictPlagiarism, Confidence: 0.9, Reason: fmt.Sprintf("指纹相似度 %d%%，代码结构基本一致（模拟复核）", similarity)}
+	case similarity >= 70:
+		return &plagiarismResponse{Verdict: model.PlagiarismVerdictSuspicious, Confidence: 0.6, Reason: fmt.Sprintf("指纹相似度 %d%%，建议人工确认（模拟复核）", similarity)}
+	default:
+		return &plagiarismResponse{Verdict: model.PlagiarismVerdictIndependent, Confidence: 0.5, Reason: fmt.Sprintf("指纹相似度 %d%%，相似可能来自题目本身（模拟复核）", similarity)}
+	}
+}
+
+var (
+	mockTranslateSource = regexp.MustCompile("(?s)# 原文（JSON）\n```json\n(.*?)\n```")
+	mockTranslateLocale = regexp.MustCompile(`目标语言代码[：:]\s*(.+)`)
+)
+
+// mockTranslate 在原文每个非空字段前加上目标语言代码，便于验证译文的保存与展示
+func mockTranslate(prompt string) *translationFields {
+	var source translationFields
+	if m := mockTranslateSource.FindStringSubmatch(prompt); m != nil {
+		_ = json.Unmarshal([]byte(m[1]), &source)
+	}
+	tag := "[" + mockPromptField(mockTranslateLocale, prompt) + "] "
+	mark := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return tag + text
+	}
+	return &translationFields{
+		Title:        mark(source.Title),
+		Description:  mark(source.Description),
+		InputFormat:  mark(source.InputFormat),
+		OutputFormat: mark(source.OutputFormat),
+		Hint:         mark(source.Hint),
+	}
+}
+
+func mockPromptField(re *regexp.Regexp, prompt string) string {
+	if m := re.FindStringSubmatch(prompt); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
+
+// mockAlgorithmMatches 要求的算法与规则的名称或别名互相包含即视为匹配
+func mockAlgorithmMatches(required string, rule *MockRule) bool {
+	required = strings.ToLower(required)
+	for _, name := range append([]string{rule.Algorithm}, rule.Aliases...) {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && (strings.Contains(required, name) || strings.Contains(name, required)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"oj-system/internal/model"
+)
+
+func mockChat(t *testing.T, task ChatTask, messages []ChatMessage, v interface{}) {
+	t.Helper()
+	rules, err := LoadMockRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewMockProvider(rules).Chat(task, messages, &model.AISettings{Provider: ProviderMock})
+	if err != nil {
+		t.Fatalf("Chat 返回错误: %v", err)
+	}
+	if err := json.Unmarshal([]byte(result.Content), v); err != nil {
+		t.Fatalf("解析模拟响应失败: %v\n%s", err, result.Content)
+	}
+}
+
+// 判题请求中即使代码或题面包含其他任务的标题，也按调用方给出的任务类型返回分析结果
+func TestMockChatRoutesByTask(t *testing.T) {
+	messages := []ChatMessage{{
+		Role:    "user",
+		Content: hintTaskTitle + "\n必须使用的算法：二分查找\n```cpp\nint main() { lower_bound(a, a + n, x); }\n```",
+	}}
+
+	var analysis AIAnalysisResult
+	mockChat(t, ChatTaskJudge, messages, &analysis)
+	if analysis.AlgorithmAnalysis.PrimaryAlgorithm != "二分查找" || !analysis.RequirementCheck.AllRequirementsMet {
+		t.Errorf("判题请求应返回算法分析结果: %+v", analysis)
+	}
+
+	var hint hintResponse
+	mockChat(t, ChatTaskHint, messages, &hint)
+	if hint.Hint == "" {
+		t.Error("学习提示请求应返回提示内容")
+	}
+}
+
+func TestDetectChatTask(t *testing.T) {
+	tests := []struct {
+		content string
+		want    ChatTask
+	}{
+		{hintTaskTitle + "\n题目", ChatTaskHint},
+		{compileExplainTaskTitle + "\n编译输出", ChatTaskCompileExplain},
+		{testcaseTaskTitle + "\n题目", ChatTaskTestcase},
+		{plagiarismTaskTitle + "\n代码", ChatTaskPlagiarism},
+		{translateTaskTitle + "\n原文", ChatTaskTranslate},
+		{"# 题目\n代码中提到了 " + hintTaskTitle, ChatTaskJudge},
+	}
+	for _, tt := range tests {
+		messages := []ChatMessage{
+			{Role: "system", Content: translateTaskTitle},
+			{Role: "user", Content: tt.content},
+		}
+		if got := DetectChatTask(messages); got != tt.want {
+			t.Errorf("DetectChatTask(%q) = %s，期望 %s", tt.content, got, tt.want)
+		}
+	}
+}
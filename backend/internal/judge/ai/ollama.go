@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"oj-system/internal/model"
+)
+
+// OllamaProvider 本地 Ollama 服务（/api/chat）
+type OllamaProvider struct{}
+
+// NewOllamaProvider 创建 Ollama 提供方
+func NewOllamaProvider() *OllamaProvider {
+	return &OllamaProvider{}
+}
+
+// ollamaRequest /api/chat 请求结构
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+	} `json:"options"`
+}
+
+// ollamaResponse /api/chat 非流式响应结构
+type ollamaResponse struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error,omitempty"`
+}
+
+func (p *OllamaProvider) Name() string {
+	return ProviderOllama
+}
+
+// RequiresAPIKey 本地服务无需 API Key
+func (p *OllamaProvider) RequiresAPIKey() bool {
+	return false
+}
+
+// Chat 调用 /api/chat（非流式，JSON 格式输出）
+func (p *OllamaProvider) Chat(_ ChatTask, messages []ChatMessage, settings *model.AISettings) (*ChatResult, error) {
+	reqBody := ollamaRequest{
+		Model:    settings.Model,
+		Messages: messages,
+		Stream:   false,
+		Format:   "json",
+	}
+	reqBody.Options.Temperature = temperature(settings)
+
+	// 经反向代理暴露的 Ollama 可能需要鉴权
+	headers := map[string]string{}
+	if settings.APIKey != "" {
+		headers["Authorization"] = "Bearer " + settings.APIKey
+	}
+
+	var resp ollamaResponse
+	body, err := postJSON(settings.APIURL, headers, reqBody, settings.Timeout, &resp)
+	result := &ChatResult{
+		RawResponse:      string(body),
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if resp.Error != "" {
+		return result, fmt.Errorf("API 错误: %s", resp.Error)
+	}
+
+	result.Content = strings.TrimSpace(resp.Message.Content)
+	if result.Content == "" {
+		return result, fmt.Errorf("无响应内容")
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"oj-system/internal/model"
+)
+
+// OpenAICompatibleProvider OpenAI 兼容的 chat/completions 接口（DeepSeek、OpenAI、Moonshot 等）
+type OpenAICompatibleProvider struct {
+	name string
+}
+
+// NewOpenAICompatibleProvider 创建 OpenAI 兼容提供方
+func NewOpenAICompatibleProvider(name string) *OpenAICompatibleProvider {
+	if name == "" {
+		name = ProviderDeepSeek
+	}
+	return &OpenAICompatibleProvider{name: name}
+}
+
+// ChatRequest API 请求结构
+type ChatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []ChatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat 响应格式
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// ChatResponse API 响应结构
+type ChatResponse struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (p *OpenAICompatibleProvider) Name() string {
+	return p.name
+}
+
+func (p *OpenAICompatibleProvider) RequiresAPIKey() bool {
+	return true
+}
+
+// Chat 调用 chat/completions 接口，要求以 json_object 格式输出
+func (p *OpenAICompatibleProvider) Chat(_ ChatTask, messages []ChatMessage, settings *model.AISettings) (*ChatResult, error) {
+	reqBody := ChatRequest{
+		Model:       settings.Model,
+		Messages:    messages,
+		Temperature: temperature(settings),
+		ResponseFormat: &ResponseFormat{
+			Type: "json_object",
+		},
+	}
+
+	headers := map[string]string{}
+	if settings.APIKey != "" {
+		headers["Authorization"] = "Bearer " + settings.APIKey
+	}
+
+	var chatResp ChatResponse
+	body, err := postJSON(settings.APIURL, headers, reqBody, settings.Timeout, &chatResp)
+	result := &ChatResult{
+		RawResponse:      string(body),
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if chatResp.Error != nil {
+		return result, fmt.Errorf("API 错误: %s", chatResp.Error.Message)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return result, fmt.Errorf("无响应内容")
+	}
+
+	result.Content = strings.TrimSpace(chatResp.Choices[0].Message.Content)
+	return result, nil
+}
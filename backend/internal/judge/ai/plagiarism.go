@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+)
+
+const (
+	// plagiarismPromptLabel 审计日志中记录的提示词名称
+	plagiarismPromptLabel = "plagiarism"
+	// plagiarismTaskTitle 查重复核任务的标题
+	plagiarismTaskTitle = "# 任务：代码查重复核"
+	// maxPlagiarismCodeLength 每份代码放入提示词的最大字符数
+	maxPlagiarismCodeLength = 8000
+	maxPlagiarismReason     = 300
+)
+
+const plagiarismSystemPrompt = "你是编程课程的助教，负责复核查重系统标记出的相似代码，判断相似是否超出了题目本身导致的自然相似。" +
+	"请客观谨慎，不要仅凭相似度下结论。请严格按照要求的 JSON 格式输出，不要输出其他内容。"
+
+// plagiarismResponse 模型输出的复核意见
+type plagiarismResponse struct {
+	Verdict    string  `json:"verdict"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// ReviewSimilarPair 请模型复核一对相似代码，给出是否抄袭的意见。
+// 调用记录关联到代码对中较早的提交 A
+func (c *Client) ReviewSimilarPair(problem *model.Problem, pair *model.PlagiarismPair, codeA, codeB string) (*model.PlagiarismAIReview, string, error) {
+	settings := c.getSettings()
+	if !settings.Enabled {
+		return nil, "", errors.New("AI 功能未启用")
+	}
+	provider := NewProvider(settings.Provider)
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return nil, "", errors.New("未配置 API Key")
+	}
+	if budget, err := c.usage.GetBudgetStatus(settings); err == nil && budget.Exceeded {
+		return nil, settings.Model, fmt.Errorf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: plagiarismSystemPrompt},
+		{Role: "user", Content: buildPlagiarismPrompt(problem, pair, codeA, codeB)},
+	}
+	owner := &model.Submission{ID: pair.SubmissionA, ProblemID: problem.ID, CreatedAt: time.Now()}
+	response, _, err := chatWithRetry(provider, ChatTaskPlagiarism, messages, settings,
+		c.auditAttempt(owner, problem.ID, settings, plagiarismPromptLabel, messages))
+	if err != nil {
+		return nil, settings.Model, fmt.Errorf("AI 调用失败: %v", err)
+	}
+
+	var parsed plagiarismResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &parsed); err != nil {
+		return nil, settings.Model, fmt.Errorf("AI 响应解析失败: %v", err)
+	}
+	verdict := strings.ToLower(strings.TrimSpace(parsed.Verdict))
+	switch verdict {
+	case model.PlagiarismVerdictPlagiarism, model.PlagiarismVerdictSuspicious, model.PlagiarismVerdictIndependent:
+	default:
+		return nil, settings.Model, fmt.Errorf("AI 给出了无效的结论: %s", parsed.Verdict)
+	}
+	reason := strings.TrimSpace(parsed.Reason)
+	if utf8.RuneCountInString(reason) > maxPlagiarismReason {
+		reason = string([]rune(reason)[:maxPlagiarismReason]) + "…"
+	}
+	return &model.PlagiarismAIReview{
+		Verdict:    verdict,
+		Confidence: min(max(parsed.Confidence, 0), 1),
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}, settings.Model, nil
+}
+
+func buildPlagiarismPrompt(problem *model.Problem, pair *model.PlagiarismPair, codeA, codeB string) string {
+	var b strings.Builder
+	b.WriteString(plagiarismTaskTitle + "\n")
+	b.WriteString("查重系统按词法指纹（忽略变量名、常量、空白与注释）发现下面两份提交高度相似。请判断两份代码是否存在抄袭。\n\n")
+	b.WriteString("# 题目信息\n")
+	fmt.Fprintf(&b, "- 题目标题：%s\n", problem.Title)
+	fmt.Fprintf(&b, "- 题目描述：%s\n\n", problem.Description)
+	b.WriteString("# 查重结果\n")
+	fmt.Fprintf(&b, "- 指纹相似度：%.0f%%\n", pair.Similarity*100)
+	for _, m := range pair.Matches {
+		fmt.Fprintf(&b, "- 相同片段：代码 A 第 %d-%d 行 ↔ 代码 B 第 %d-%d 行\n", m.AStart, m.AEnd, m.BStart, m.BEnd)
+	}
+	fmt.Fprintf(&b, "\n# 代码 A（%s）\n```%s\n%s\n```\n\n", pair.Language, pair.Language, numberLines(truncateCode(codeA)))
+	fmt.Fprintf(&b, "# 代码 B（%s）\n```%s\n%s\n```\n\n", pair.Language, pair.Language, numberLines(truncateCode(codeB)))
+	b.WriteString(`# 判断要求
+- 题目简单、解法固定（如输入输出模板、标准算法的常见写法）导致的相似不算抄袭
+- 重点关注非必要的相同之处：相同的特殊写法、相同的冗余代码或错误、相同的代码结构与顺序、仅改名或调整格式
+- verdict 取值：plagiarism（基本可以认定抄袭）、suspicious（可疑，需要人工确认）、independent（相似来自题目本身，倾向独立完成）
+- reason 使用中文，不超过 150 字，尽量引用具体行号
+
+# 输出要求
+请严格按照以下 JSON 格式输出：
+
+{
+    "verdict": "plagiarism/suspicious/independent",
+    "confidence": 0.0-1.0,
+    "reason": "判断理由"
+}`)
+	return b.String()
+}
+
+func truncateCode(code string) string {
+	if utf8.RuneCountInString(code) <= maxPlagiarismCodeLength {
+		return code
+	}
+	return string([]rune(code)[:maxPlagiarismCodeLength]) + "\n... (已截断)"
+}
+
+// numberLines 为代码加上行号，便于模型引用具体位置
+func numberLines(code string) string {
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&b, "%4d | %s\n", i+1, line)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
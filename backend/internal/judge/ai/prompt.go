@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"oj-system/internal/model"
+)
+
+// builtinSystemPrompt 内置系统提示词
+const builtinSystemPrompt = "你是一个专业的代码分析专家，擅长识别代码中使用的算法和编程技术。请严格按照用户要求的 JSON 格式输出分析结果，不要输出其他内容。"
+
+// builtinUserPrompt 内置分析提示词，未配置模板时使用
+const builtinUserPrompt = "# 任务\n" +
+	"分析用户提交的代码，判断是否符合题目的特定要求。\n\n" +
+	"# 题目信息\n" +
+	"- 题目标题：{{.Problem.Title}}\n" +
+	"- 题目描述：{{.Problem.Description}}\n\n" +
+	"# 题目要求\n" +
+	"{{- if .Config.RequiredAlgorithm}}\n- 必须使用的算法：{{.Config.RequiredAlgorithm}}{{end}}\n" +
+	"{{- if .Config.RequiredLanguage}}\n- 必须使用的编程语言：{{join .Config.RequiredLanguage \"、\"}}{{end}}\n" +
+	"{{- if .Config.ForbiddenFeatures}}\n- 禁止使用的特性：{{join .Config.ForbiddenFeatures \", \"}}{{end}}\n" +
+	"{{- if .Config.CustomPrompt}}\n- 额外要求：{{.Config.CustomPrompt}}{{end}}\n\n" +
+	"# 用户提交的代码（{{.Language}}）\n" +
+	"```{{.Language}}\n" +
+	"{{.Code}}\n" +
+	"```\n\n" +
+	`# 输出要求
+请严格按照以下 JSON 格式输出分析结果：
+
+{
+    "algorithm_analysis": {
+        "detected_algorithms": ["检测到的算法列表"],
+        "primary_algorithm": "主要使用的算法",
+        "confidence": 0.0到1.0的置信度,
+        "evidence": "判断依据说明"
+    },
+    "language_features": {
+        "language": "编程语言",
+        "used_features": ["使用的语言特性"],
+        "forbidden_features_used": ["使用了的禁止特性"]
+    },
+    "requirement_check": {
+        "algorithm_match": true或false,
+        "language_match": true或false,
+        "all_requirements_met": true或false
+    },
+    "summary": "一句话总结"
+}`
+
+// BuiltinPromptTemplate 内置模板（ID 为 0），可作为自定义模板的起点
+func BuiltinPromptTemplate() *model.AIPromptTemplate {
+	return &model.AIPromptTemplate{
+		Name:         "内置模板",
+		SystemPrompt: builtinSystemPrompt,
+		UserPrompt:   builtinUserPrompt,
+	}
+}
+
+// PromptData 模板可用的字段：.Problem（题目）、.Code、.Language、.Config（题目 AI 判题配置）
+type PromptData struct {
+	Problem  *model.Problem
+	Code     string
+	Language string
+	Config   *model.AIJudgeConfig
+}
+
+var promptFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// RenderPrompt 渲染模板，返回系统提示词与分析提示词；系统提示词为空时使用内置系统提示词
+func RenderPrompt(tpl *model.AIPromptTemplate, data *PromptData) (string, string, error) {
+	systemPrompt := tpl.SystemPrompt
+	if strings.TrimSpace(systemPrompt) == "" {
+		systemPrompt = builtinSystemPrompt
+	}
+	system, err := executePrompt("system", systemPrompt, data)
+	if err != nil {
+		return "", "", fmt.Errorf("系统提示词: %v", err)
+	}
+	user, err := executePrompt("user", tpl.UserPrompt, data)
+	if err != nil {
+		return "", "", fmt.Errorf("分析提示词: %v", err)
+	}
+	return system, user, nil
+}
+
+func executePrompt(name, text string, data *PromptData) (string, error) {
+	t, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// ValidatePromptTemplate 用示例数据试渲染，检查模板语法与字段引用
+func ValidatePromptTemplate(systemPrompt, userPrompt string) error {
+	if strings.TrimSpace(userPrompt) == "" {
+		return fmt.Errorf("分析提示词不能为空")
+	}
+	maxScore := 50
+	sample := &PromptData{
+		Problem:  &model.Problem{Title: "示例题目", Description: "示例描述"},
+		Code:     "int main() { return 0; }",
+		Language: "cpp",
+		Config: &model.AIJudgeConfig{
+			Enabled:           true,
+			RequiredAlgorithm: "动态规划",
+			RequiredLanguage:  []string{"cpp"},
+			ForbiddenFeatures: []string{"std::sort"},
+			CustomPrompt:      "示例要求",
+			StrictMode:        true,
+			MaxScoreIfNotMet:  &maxScore,
+		},
+	}
+	_, _, err := RenderPrompt(&model.AIPromptTemplate{SystemPrompt: systemPrompt, UserPrompt: userPrompt}, sample)
+	return err
+}
+
+// resolvePromptTemplate 选择生效的模板：题目指定 > 系统默认 > 内置；模板不存在时依次回退到系统默认与内置模板
+func (c *Client) resolvePromptTemplate(aiConfig *model.AIJudgeConfig, settings *model.AISettings) *model.AIPromptTemplate {
+	if aiConfig != nil && aiConfig.PromptTemplateID != 0 && aiConfig.PromptTemplateID != settings.PromptTemplateID {
+		tpl, err := c.promptRepo.GetByID(aiConfig.PromptTemplateID)
+		if err == nil {
+			return tpl
+		}
+		log.Printf("[AI] 提示词模板 %d 不存在，使用系统默认模板", aiConfig.PromptTemplateID)
+	}
+	if settings.PromptTemplateID == 0 {
+		return BuiltinPromptTemplate()
+	}
+	tpl, err := c.promptRepo.GetByID(settings.PromptTemplateID)
+	if err != nil {
+		log.Printf("[AI] 提示词模板 %d 不存在，使用内置模板", settings.PromptTemplateID)
+		return BuiltinPromptTemplate()
+	}
+	return tpl
+}
+
+// buildMessages 渲染生效的模板并构造对话消息；自定义模板渲染失败时回退到内置模板
+func (c *Client) buildMessages(tpl *model.AIPromptTemplate, data *PromptData) ([]ChatMessage, *model.AIPromptTemplate) {
+	system, user, err := RenderPrompt(tpl, data)
+	if err != nil && tpl.ID != 0 {
+		log.Printf("[AI] 提示词模板 %s 渲染失败，使用内置模板: %v", tpl.Name, err)
+		tpl = BuiltinPromptTemplate()
+		system, user, err = RenderPrompt(tpl, data)
+	}
+	if err != nil {
+		log.Printf("[AI] 内置提示词模板渲染失败: %v", err)
+	}
+	return []ChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}, tpl
+}
+
+// PreviewPrompt 按提交渲染提示词但不调用模型；tpl 为 nil 时使用该题实际生效的模板
+func (c *Client) PreviewPrompt(problem *model.Problem, code, language string, tpl *model.AIPromptTemplate) (*model.AIPromptPreview, error) {
+	aiConfig := problem.AIJudgeConfig
+	if aiConfig == nil {
+		aiConfig = &model.AIJudgeConfig{}
+	}
+	if tpl == nil {
+		tpl = c.resolvePromptTemplate(aiConfig, c.getSettings())
+	}
+
+	system, user, err := RenderPrompt(tpl, &PromptData{Problem: problem, Code: code, Language: language, Config: aiConfig})
+	if err != nil {
+		return nil, err
+	}
+	return &model.AIPromptPreview{
+		TemplateID:   tpl.ID,
+		TemplateName: tpl.Name,
+		Version:      tpl.Version,
+		SystemPrompt: system,
+		UserPrompt:   user,
+	}, nil
+}
+
+// promptTemplateLabel 记录在分析结果与缓存键中的模板标识
+func promptTemplateLabel(tpl *model.AIPromptTemplate) string {
+	if tpl.ID == 0 {
+		return "builtin"
+	}
+	return fmt.Sprintf("%s#%d@v%d", tpl.Name, tpl.ID, tpl.Version)
+}
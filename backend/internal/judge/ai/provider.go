@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"oj-system/internal/model"
+)
+
+// 服务提供方名称（ai_provider 设置）
+const (
+	ProviderDeepSeek  = "deepseek"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// chatTemperature 判题分析需要稳定输出，默认使用较低温度
+const chatTemperature = 0.1
+
+// temperature 本次调用的采样温度，未指定时使用 chatTemperature
+func temperature(settings *model.AISettings) float64 {
+	if settings.Temperature > 0 {
+		return settings.Temperature
+	}
+	return chatTemperature
+}
+
+// ChatTask 对话调用的任务类型；真实模型只看提示词，模拟提供方按任务类型决定返回内容
+type ChatTask string
+
+const (
+	ChatTaskJudge          ChatTask = "judge"           // AI 判题分析（含共识投票）
+	ChatTaskHint           ChatTask = "hint"            // 学习提示
+	ChatTaskCompileExplain ChatTask = "compile_explain" // 编译错误解释
+	ChatTaskTestcase       ChatTask = "testcase"        // 测试数据建议
+	ChatTaskPlagiarism     ChatTask = "plagiarism"      // 查重复核
+	ChatTaskTranslate      ChatTask = "translate"       // 题面翻译
+)
+
+// ChatMessage 消息结构
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatResult 一次对话调用的结果
+type ChatResult struct {
+	Content          string // 模型输出的文本
+	RawResponse      string // 原始响应体，用于审计
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// AIProvider AI 服务提供方接口，屏蔽各家接口的请求/响应格式差异
+type AIProvider interface {
+	// Name 提供方名称
+	Name() string
+	// RequiresAPIKey 是否必须配置 API Key
+	RequiresAPIKey() bool
+	// Chat 发送对话并返回模型输出；出错时若已收到响应，仍返回带 RawResponse 的结果
+	Chat(task ChatTask, messages []ChatMessage, settings *model.AISettings) (*ChatResult, error)
+}
+
+// NewProvider 按 ai_provider 设置创建服务提供方；
+// 除 anthropic、ollama、mock 外的取值（deepseek、openai、moonshot、自定义等）均按 OpenAI 兼容接口处理
+func NewProvider(name string) AIProvider {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case ProviderAnthropic:
+		return NewAnthropicProvider()
+	case ProviderOllama:
+		return NewOllamaProvider()
+	case ProviderMock:
+		return NewMockProvider(nil)
+	default:
+		return NewOpenAICompatibleProvider(name)
+	}
+}
+
+// postJSON 发送 JSON 请求并将响应体解析到 out，返回原始响应体
+func postJSON(url string, headers map[string]string, reqBody interface{}, timeout int, out interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// 网络错误与超时可重试
+		return nil, &retryableError{err: fmt.Errorf("请求失败: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &retryableError{err: fmt.Errorf("读取响应失败: %v", err)}
+	}
+
+	// 限流与服务端错误可重试
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return body, &retryableError{
+			err:        fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateBody(body)),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return body, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateBody(body))
+		}
+		return body, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return body, nil
+}
+
+func truncateBody(body []byte) string {
+	const maxLen = 200
+	s := strings.TrimSpace(string(body))
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
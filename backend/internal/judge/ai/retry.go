@@ -0,0 +1,156 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"oj-system/internal/model"
+)
+
+const (
+	retryBaseDelay       = time.Second
+	retryMaxDelay        = 30 * time.Second
+	breakerFailThreshold = 5 // 连续失败次数达到该值后熔断
+	breakerCooldown      = time.Minute
+)
+
+// retryableError 可重试的错误（网络错误、429、5xx）
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// parseRetryAfter 解析 Retry-After 头（仅支持秒数）
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+// backoffDelay 第 attempt 次重试前的等待时间：1s、2s、4s……，服务端要求更久时以其为准
+func backoffDelay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := retryBaseDelay << uint(attempt-1)
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	if retryAfter > delay {
+		delay = retryAfter
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// circuitBreaker 熔断器：连续出现临时性故障（网络错误、超时、429、5xx）达到阈值后在冷却期内直接拒绝调用，
+// 冷却期结束后放行一次试探调用，成功则恢复，失败则重新熔断。4xx 等非临时性错误说明服务可达，不计入
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+var breaker = &circuitBreaker{}
+
+var errCircuitOpen = errors.New("AI 服务连续调用失败，已暂时熔断")
+
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerFailThreshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return errCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= breakerFailThreshold {
+		log.Printf("[AI] 调用恢复，解除熔断")
+	}
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= breakerFailThreshold {
+		b.openUntil = time.Now().Add(breakerCooldown)
+		log.Printf("[AI] 连续失败 %d 次，熔断 %s", b.failures, breakerCooldown)
+	}
+}
+
+// BreakerOpen 返回熔断器当前是否处于熔断状态
+func BreakerOpen() bool {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	return breaker.failures >= breakerFailThreshold && time.Now().Before(breaker.openUntil)
+}
+
+// attemptFunc 每次实际调用提供方后的回调，用于记录审计日志与用量
+type attemptFunc func(attempt int, result *ChatResult, err error, latency time.Duration)
+
+// chatWithRetry 经熔断器调用提供方，可重试的错误按指数退避重试 settings.MaxRetries 次；
+// 返回模型输出与实际调用次数
+func chatWithRetry(provider AIProvider, task ChatTask, messages []ChatMessage, settings *model.AISettings, onAttempt attemptFunc) (string, int, error) {
+	attempts := 0
+	for {
+		if err := breaker.allow(); err != nil {
+			return "", attempts, err
+		}
+
+		attempts++
+		start := time.Now()
+		result, err := provider.Chat(task, messages, settings)
+		if onAttempt != nil {
+			onAttempt(attempts, result, err, time.Since(start))
+		}
+		if err == nil {
+			breaker.success()
+			return result.Content, attempts, nil
+		}
+
+		var retryable *retryableError
+		if errors.As(err, &retryable) {
+			breaker.failure()
+		} else {
+			// 请求参数、鉴权等错误由配置或请求本身导致，服务仍然可达，不应熔断其他调用
+			breaker.success()
+		}
+		if retryable == nil || attempts > settings.MaxRetries {
+			if attempts > 1 {
+				err = fmt.Errorf("%v（已尝试 %d 次）", err, attempts)
+			}
+			return "", attempts, err
+		}
+
+		delay := backoffDelay(attempts, retryable.retryAfter)
+		log.Printf("[AI] 调用失败，%s 后重试（第 %d 次）: %v", delay, attempts, err)
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	"oj-system/internal/model"
+)
+
+// failingProvider 每次调用都返回同一个错误
+type failingProvider struct {
+	err error
+}
+
+func (p *failingProvider) Name() string         { return "failing" }
+func (p *failingProvider) RequiresAPIKey() bool { return false }
+
+func (p *failingProvider) Chat(ChatTask, []ChatMessage, *model.AISettings) (*ChatResult, error) {
+	return nil, p.err
+}
+
+func resetBreaker(t *testing.T) {
+	t.Helper()
+	breaker = &circuitBreaker{}
+	t.Cleanup(func() { breaker = &circuitBreaker{} })
+}
+
+func callRepeatedly(provider AIProvider, times int) {
+	settings := &model.AISettings{MaxRetries: 0}
+	for i := 0; i < times; i++ {
+		chatWithRetry(provider, ChatTaskJudge, nil, settings, nil)
+	}
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	resetBreaker(t)
+	callRepeatedly(&failingProvider{err: errors.New("HTTP 400: invalid model")}, breakerFailThreshold*2)
+	if BreakerOpen() {
+		t.Fatal("4xx 等非临时性错误不应触发熔断")
+	}
+}
+
+func TestBreakerOpensOnTransientErrors(t *testing.T) {
+	resetBreaker(t)
+	callRepeatedly(&failingProvider{err: &retryableError{err: errors.New("HTTP 503: unavailable")}}, breakerFailThreshold)
+	if !BreakerOpen() {
+		t.Fatal("连续的临时性错误应触发熔断")
+	}
+	if _, attempts, err := chatWithRetry(&failingProvider{}, ChatTaskJudge, nil, &model.AISettings{}, nil); !errors.Is(err, errCircuitOpen) || attempts != 0 {
+		t.Errorf("熔断期间应直接拒绝调用: attempts=%d err=%v", attempts, err)
+	}
+}
+
+func TestBreakerClientErrorEndsStreak(t *testing.T) {
+	resetBreaker(t)
+	callRepeatedly(&failingProvider{err: &retryableError{err: errors.New("timeout")}}, breakerFailThreshold-1)
+	callRepeatedly(&failingProvider{err: errors.New("HTTP 401: unauthorized")}, 1)
+	callRepeatedly(&failingProvider{err: &retryableError{err: errors.New("timeout")}}, 1)
+	if BreakerOpen() {
+		t.Fatal("服务有响应后应重新计算连续故障次数")
+	}
+}
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oj-system/internal/model"
+)
+
+const (
+	// testcasePromptLabel 审计日志中记录的提示词名称
+	testcasePromptLabel = "testcase"
+	// testcaseTaskTitle 测试数据建议任务的标题
+	testcaseTaskTitle = "# 任务：设计测试数据"
+)
+
+const testcaseSystemPrompt = "你是经验丰富的算法竞赛出题人，擅长根据题面与数据范围设计能卡掉错误解法的边界测试数据。" +
+	"请严格按照要求的 JSON 格式输出，不要输出其他内容。"
+
+// testcaseResponse 模型输出的测试数据建议
+type testcaseResponse struct {
+	Cases     []proposedCase     `json:"cases"`
+	Generator *proposedGenerator `json:"generator"`
+}
+
+type proposedCase struct {
+	Description string `json:"description"`
+	Input       string `json:"input"`
+}
+
+type proposedGenerator struct {
+	Language string   `json:"language"`
+	Code     string   `json:"code"`
+	Args     []string `json:"args"`
+}
+
+// ProposeTestcases 根据题面、数据范围与样例请模型建议边界测试输入与一份数据生成器。
+// 只返回输入，输出由标准程序生成；调用记录计入该题目的用量
+func (c *Client) ProposeTestcases(problem *model.Problem, count int, focus string) ([]model.TestcaseProposal, *model.ProblemProgram, string, error) {
+	settings := c.getSettings()
+	if !settings.Enabled {
+		return nil, nil, "", errors.New("AI 功能未启用")
+	}
+	provider := NewProvider(settings.Provider)
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return nil, nil, "", errors.New("未配置 API Key")
+	}
+	if budget, err := c.usage.GetBudgetStatus(settings); err == nil && budget.Exceeded {
+		return nil, nil, "", fmt.Errorf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: testcaseSystemPrompt},
+		{Role: "user", Content: buildTestcasePrompt(problem, count, focus)},
+	}
+	// 审计记录不关联提交，提交时间用于按天统计用量
+	owner := &model.Submission{ProblemID: problem.ID, CreatedAt: time.Now()}
+	response, _, err := chatWithRetry(provider, ChatTaskTestcase, messages, settings,
+		c.auditAttempt(owner, problem.ID, settings, testcasePromptLabel, messages))
+	if err != nil {
+		return nil, nil, settings.Model, fmt.Errorf("AI 调用失败: %v", err)
+	}
+
+	var parsed testcaseResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &parsed); err != nil {
+		return nil, nil, settings.Model, fmt.Errorf("AI 响应解析失败: %v", err)
+	}
+
+	proposals := make([]model.TestcaseProposal, 0, len(parsed.Cases))
+	for _, tc := range parsed.Cases {
+		if strings.TrimSpace(tc.Input) == "" || len(proposals) >= count {
+			continue
+		}
+		proposals = append(proposals, model.TestcaseProposal{
+			Description: strings.TrimSpace(tc.Description),
+			Input:       tc.Input,
+		})
+	}
+	if len(proposals) == 0 {
+		return nil, nil, settings.Model, errors.New("AI 未给出测试数据")
+	}
+
+	var generator *model.ProblemProgram
+	if g := parsed.Generator; g != nil && strings.TrimSpace(g.Code) != "" {
+		generator = &model.ProblemProgram{
+			ProblemID: problem.ID,
+			Role:      model.ProgramRoleGenerator,
+			Language:  strings.ToLower(strings.TrimSpace(g.Language)),
+			Code:      g.Code,
+			Args:      model.StringList(g.Args),
+		}
+	}
+	return proposals, generator, settings.Model, nil
+}
+
+func buildTestcasePrompt(problem *model.Problem, count int, focus string) string {
+	var b strings.Builder
+	b.WriteString(testcaseTaskTitle + "\n")
+	fmt.Fprintf(&b, "请为下面的题目设计 %d 组测试输入，重点覆盖边界情况与容易出错的特殊情况。\n\n", count)
+	b.WriteString("# 题目信息\n")
+	fmt.Fprintf(&b, "- 题目标题：%s\n", problem.Title)
+	fmt.Fprintf(&b, "- 题目描述：%s\n", problem.Description)
+	if problem.InputFormat != "" {
+		fmt.Fprintf(&b, "- 输入格式：%s\n", problem.InputFormat)
+	}
+	if problem.OutputFormat != "" {
+		fmt.Fprintf(&b, "- 输出格式：%s\n", problem.OutputFormat)
+	}
+	if problem.Hint != "" {
+		fmt.Fprintf(&b, "- 提示与数据范围：%s\n", problem.Hint)
+	}
+	fmt.Fprintf(&b, "- 时间限制：%d ms，内存限制：%d MB\n", problem.TimeLimit, problem.MemoryLimit)
+	for i, sample := range problem.Samples {
+		fmt.Fprintf(&b, "\n## 样例输入 %d\n```\n%s\n```\n", i+1, strings.TrimRight(sample.Input, "\n"))
+	}
+	if focus != "" {
+		fmt.Fprintf(&b, "\n# 出题人补充说明\n%s\n", focus)
+	}
+	b.WriteString(`
+# 设计要求
+- 每组输入必须严格符合输入格式与数据范围，不要设计非法输入
+- 覆盖最小规模、最大值/最小值、全部相同、已排序/逆序、答案为 0 或溢出边界等情况，并说明每组数据针对的情况
+- 直接写出的输入应较小（每组不超过几 KB）；需要大规模数据时放在数据生成器中
+- 另外给出一份数据生成器（c、cpp、python、java 或 go），从命令行参数读取规模与随机种子，把一组输入输出到标准输出；args 为若干组建议的命令行参数
+
+# 输出要求
+请严格按照以下 JSON 格式输出：
+
+{
+    "cases": [
+        {"description": "这组数据针对的情况", "input": "完整的输入内容"}
+    ],
+    "generator": {
+        "language": "python",
+        "code": "生成器代码",
+        "args": ["10 1", "100000 2"]
+    }
+}`)
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oj-system/internal/model"
+)
+
+const (
+	// translatePromptLabel 审计日志中记录的提示词名称
+	translatePromptLabel = "translate"
+	// translateTaskTitle 题面翻译任务的标题
+	translateTaskTitle = "# 任务：翻译题面"
+)
+
+const translateSystemPrompt = "你是熟悉算法竞赛的专业译者，负责把中文编程题的题面翻译成其他语言。" +
+	"译文要准确、自然，符合目标语言算法竞赛题面的习惯表达。请严格按照要求的 JSON 格式输出，不要输出其他内容。"
+
+// translationFields 题面中需要翻译的字段，同时作为原文与模型输出的格式
+type translationFields struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Hint         string `json:"hint"`
+}
+
+// TranslateProblem 请模型将题面原文翻译为指定语言，返回译文（未设置状态）与使用的模型；
+// 调用记录计入该题目的用量
+func (c *Client) TranslateProblem(problem *model.Problem, locale model.ProblemLocale) (*model.ProblemTranslation, string, error) {
+	settings := c.getSettings()
+	if !settings.Enabled {
+		return nil, "", errors.New("AI 功能未启用")
+	}
+	provider := NewProvider(settings.Provider)
+	if provider.RequiresAPIKey() && settings.APIKey == "" {
+		return nil, "", errors.New("未配置 API Key")
+	}
+	if budget, err := c.usage.GetBudgetStatus(settings); err == nil && budget.Exceeded {
+		return nil, settings.Model, fmt.Errorf("本月 AI token 预算已用完（%d/%d）", budget.Used, budget.Budget)
+	}
+
+	source := translationFields{
+		Title:        problem.Title,
+		Description:  problem.Description,
+		InputFormat:  problem.InputFormat,
+		OutputFormat: problem.OutputFormat,
+		Hint:         problem.Hint,
+	}
+	messages := []ChatMessage{
+		{Role: "system", Content: translateSystemPrompt},
+		{Role: "user", Content: buildTranslatePrompt(&source, locale)},
+	}
+	// 审计记录不关联提交，提交时间用于按天统计用量
+	owner := &model.Submission{ProblemID: problem.ID, CreatedAt: time.Now()}
+	response, _, err := chatWithRetry(provider, ChatTaskTranslate, messages, settings,
+		c.auditAttempt(owner, problem.ID, settings, translatePromptLabel, messages))
+	if err != nil {
+		return nil, settings.Model, fmt.Errorf("AI 调用失败: %v", err)
+	}
+
+	var parsed translationFields
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &parsed); err != nil {
+		return nil, settings.Model, fmt.Errorf("AI 响应解析失败: %v", err)
+	}
+	parsed.Title = strings.TrimSpace(parsed.Title)
+	if parsed.Title == "" || strings.TrimSpace(parsed.Description) == "" {
+		return nil, settings.Model, errors.New("AI 返回的译文缺少标题或描述")
+	}
+	// 原文为空的字段不采用模型可能补写的内容
+	if source.InputFormat == "" {
+		parsed.InputFormat = ""
+	}
+	if source.OutputFormat == "" {
+		parsed.OutputFormat = ""
+	}
+	if source.Hint == "" {
+		parsed.Hint = ""
+	}
+	return &model.ProblemTranslation{
+		Title:        parsed.Title,
+		Description:  parsed.Description,
+		InputFormat:  parsed.InputFormat,
+		OutputFormat: parsed.OutputFormat,
+		Hint:         parsed.Hint,
+	}, settings.Model, nil
+}
+
+func buildTranslatePrompt(source *translationFields, locale model.ProblemLocale) string {
+	sourceJSON, _ := json.MarshalIndent(source, "", "    ")
+	var b strings.Builder
+	b.WriteString(translateTaskTitle + "\n")
+	fmt.Fprintf(&b, "- 目标语言：%s\n", locale.Name)
+	fmt.Fprintf(&b, "- 目标语言代码：%s\n\n", locale.Code)
+	b.WriteString("# 原文（JSON）\n```json\n")
+	b.Write(sourceJSON)
+	b.WriteString("\n```\n\n")
+	b.WriteString(`# 翻译要求
+- 逐字段翻译，保持原有的 Markdown 结构（标题、列表、表格、代码块）与换行
+- $...$ 与 $$...$$ 中的 LaTeX 公式、代码块与行内代码、图片与链接地址、变量名、数值和数据范围原样保留，不要翻译或改动
+- 使用目标语言算法竞赛题面的常用术语（如输入格式、输出格式、数据范围、样例）
+- 原文为空的字段输出空字符串，不要补写内容
+
+# 输出要求
+请严格按照以下 JSON 格式输出：
+
+{
+    "title": "译文标题",
+    "description": "译文描述",
+    "input_format": "译文输入格式",
+    "output_format": "译文输出格式",
+    "hint": "译文提示"
+}`)
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package judge
+
+import (
+	"log"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+// hintRequested 是否为该提交生成 AI 学习提示：题目开启了学习提示、AI 功能已启用、
+// 测试点评测结果为可提示的失败类型，且不是进行中比赛的赛时提交
+func (j *Judger) hintRequested(submission *model.Submission, problem *model.Problem, traditionalStatus string) bool {
+	if !problem.AIHintEnabled || !ai.HintCategorySupported(traditionalStatus) {
+		return false
+	}
+	if !service.GetSettingService().GetAISettings().Enabled {
+		return false
+	}
+	return !j.contestService.IsContestSubmission(submission.ProblemID, submission.UserID, submission.CreatedAt)
+}
+
+// enqueueHint 标记提交等待生成学习提示并加入 AI 队列；队列已满时由定期扫描补充入队
+func (j *Judger) enqueueHint(submissionID uint) {
+	if updated, err := j.submissionService.UpdateAIHint(submissionID, "", model.AIHintStatusGenerating, nil); err != nil || !updated {
+		return
+	}
+	if !j.aiQueue.Enqueue(aiTask{Kind: aiTaskHint, SubmissionID: submissionID}) {
+		log.Printf("[Judger] AI 队列繁忙，学习提示稍后由定期扫描入队: submission_id=%d", submissionID)
+	}
+}
+
+func (j *Judger) enqueuePendingHints() {
+	ids, err := j.submissionService.ListIDsByAIHintStatus(model.AIHintStatusGenerating, aiScanBatchSize)
+	if err != nil {
+		log.Printf("[Judger] 获取待生成学习提示的提交失败: %v", err)
+		return
+	}
+	for _, id := range ids {
+		j.aiQueue.Enqueue(aiTask{Kind: aiTaskHint, SubmissionID: id})
+	}
+}
+
+// generateHint 为等待生成学习提示的提交调用 AI；失败时记录原因，不再重试
+func (j *Judger) generateHint(task aiTask) {
+	submission, err := j.submissionService.GetByIDForJudge(task.SubmissionID)
+	if err != nil || submission.AIHintStatus != model.AIHintStatusGenerating {
+		return
+	}
+	problem, err := j.problemRepo.GetByID(submission.ProblemID)
+	if err != nil {
+		return
+	}
+
+	category := calculateTraditionalStatus(submission.TestcaseResults)
+	passed := 0
+	for _, r := range submission.TestcaseResults {
+		if r.Status == model.StatusAccepted {
+			passed++
+		}
+	}
+	total := len(submission.TestcaseResults)
+
+	status := model.AIHintStatusDone
+	hint, err := j.aiClient.GenerateHint(submission, problem, category, passed, total)
+	if err != nil {
+		log.Printf("[Judger] 生成学习提示失败: submission_id=%d, err=%v", submission.ID, err)
+		status = model.AIHintStatusFailed
+		hint = &model.AIHint{Category: category, Passed: passed, Total: total, Error: err.Error()}
+	}
+	if _, err := j.submissionService.UpdateAIHint(submission.ID, model.AIHintStatusGenerating, status, hint); err != nil {
+		log.Printf("[Judger] 保存学习提示失败: submission_id=%d, err=%v", submission.ID, err)
+	}
+}
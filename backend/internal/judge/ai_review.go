@@ -0,0 +1,68 @@
+package judge
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// ReviewAIResult 教师复核置信度不足的 AI 判定：确认或改判是否满足题目要求并记录复核人，
+// 再按题目配置（严格模式、未满足要求时的最高得分）重新计算最终状态与得分
+func ReviewAIResult(submissionID uint, req *model.AIReviewRequest, reviewerID uint, reviewer string) (*model.Submission, error) {
+	submissionService := service.NewSubmissionService()
+	submission, err := submissionService.GetByIDForJudge(submissionID)
+	if err != nil {
+		return nil, errors.New("提交不存在")
+	}
+	result := submission.AIJudgeResult
+	if submission.AIStatus != model.AIStatusManualReview || !result.AwaitingReview() {
+		return nil, errors.New("该提交不在人工复核队列中")
+	}
+	problem, err := repository.NewProblemRepository().GetByID(submission.ProblemID)
+	if err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	if problem.AIJudgeConfig == nil {
+		// 进入复核后题目关闭了 AI 判题，按非严格模式、默认封顶处理
+		problem.AIJudgeConfig = &model.AIJudgeConfig{}
+	}
+
+	now := time.Now()
+	review := result.Review
+	review.Status = model.AIReviewConfirmed
+	if *req.Passed != review.AIPassed {
+		review.Status = model.AIReviewOverridden
+	}
+	review.ReviewerID = reviewerID
+	review.Reviewer = reviewer
+	review.Comment = strings.TrimSpace(req.Comment)
+	review.ReviewedAt = &now
+	result.Passed = *req.Passed
+
+	traditionalStatus := calculateTraditionalStatus(submission.TestcaseResults)
+	submission.Status = traditionalStatus
+	submission.FinalMessage = ""
+	applyAIResult(submission, problem, traditionalStatus, result)
+	// 改判为未满足要求时，AI 给出的理由与结论相反，改为展示教师意见
+	if review.Status == model.AIReviewOverridden && !result.Passed &&
+		traditionalStatus == model.StatusAccepted && !problem.AIJudgeConfig.StrictMode {
+		submission.FinalMessage = "测试点全部通过。教师复核认定未满足题目的算法/语言要求"
+		if review.Comment != "" {
+			submission.FinalMessage += "：" + review.Comment
+		}
+	}
+	submission.Score = finalScore(submission, problem)
+
+	updated, err := submissionService.UpdateAIResult(submission, model.AIStatusManualReview)
+	if err != nil {
+		return nil, errors.New("保存复核结果失败")
+	}
+	if !updated {
+		return nil, errors.New("提交状态已变化（可能已被重测），请刷新后重试")
+	}
+	return submission, nil
+}
@@ -0,0 +1,184 @@
+package judge
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/model"
+)
+
+const (
+	aiQueueSize     = 1000
+	aiScanInterval  = time.Minute
+	aiScanBatchSize = 20
+)
+
+// AI 任务类型
+const (
+	aiTaskAnalyze = "analyze" // 按题目要求分析代码
+	aiTaskHint    = "hint"    // 为非 AC 提交生成学习提示
+	// aiTaskCompileExplain 解释编译错误
+	aiTaskCompileExplain = "compile_explain"
+)
+
+// aiTask AI 分析任务
+type aiTask struct {
+	Kind         string // 为空时按 aiTaskAnalyze 处理
+	SubmissionID uint
+	Force        bool // 跳过缓存，强制重新调用 AI
+}
+
+// aiTaskKey 去重键：同一提交的不同类型任务可以同时排队
+type aiTaskKey struct {
+	kind         string
+	submissionID uint
+}
+
+func (t aiTask) key() aiTaskKey {
+	kind := t.Kind
+	if kind == "" {
+		kind = aiTaskAnalyze
+	}
+	return aiTaskKey{kind: kind, submissionID: t.SubmissionID}
+}
+
+// aiQueue AI 分析队列：测试点结果发布后提交进入此队列，由独立的 worker 分析，不占用判题 worker
+type aiQueue struct {
+	tasks chan aiTask
+
+	mu     sync.Mutex
+	queued map[aiTaskKey]bool // 已在队列中或正在处理的任务，避免重复入队
+}
+
+func newAIQueue(size int) *aiQueue {
+	return &aiQueue{
+		tasks:  make(chan aiTask, size),
+		queued: make(map[aiTaskKey]bool),
+	}
+}
+
+// Enqueue 提交 AI 分析任务；已在队列中或队列已满时返回 false，由定期扫描补充入队
+func (q *aiQueue) Enqueue(task aiTask) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queued[task.key()] {
+		return false
+	}
+	select {
+	case q.tasks <- task:
+		q.queued[task.key()] = true
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *aiQueue) done(task aiTask) {
+	q.mu.Lock()
+	delete(q.queued, task.key())
+	q.mu.Unlock()
+}
+
+// startAIWorkers 启动 AI 分析 worker 与定期扫描
+func (j *Judger) startAIWorkers(workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range j.aiQueue.tasks {
+				switch task.Kind {
+				case aiTaskHint:
+					j.generateHint(task)
+				case aiTaskCompileExplain:
+					j.explainCompileError(task)
+				default:
+					j.analyzeSubmission(task)
+				}
+				j.aiQueue.done(task)
+			}
+		}()
+	}
+	log.Printf("[Judger] 启动 AI 分析队列，workers=%d", workers)
+
+	go j.runAIScanLoop()
+}
+
+// enqueueAI 将测试点评测完成的提交加入 AI 分析队列
+func (j *Judger) enqueueAI(submissionID uint, force bool) {
+	if !j.aiQueue.Enqueue(aiTask{SubmissionID: submissionID, Force: force}) {
+		log.Printf("[Judger] AI 分析队列繁忙，稍后由定期扫描入队: submission_id=%d", submissionID)
+	}
+}
+
+// runAIScanLoop 启动时及之后定期扫描：恢复等待 AI 审核、等待生成学习提示或解释编译错误的提交（服务重启或队列已满时遗留），
+// 并重新分析 AI 调用失败、处于待分析状态的提交（失败策略为 pending 时产生）
+func (j *Judger) runAIScanLoop() {
+	ticker := time.NewTicker(aiScanInterval)
+	defer ticker.Stop()
+
+	for {
+		j.enqueueByAIStatus(model.AIStatusReviewing)
+		j.enqueuePendingHints()
+		j.enqueuePendingCompileExplains()
+		if !ai.BreakerOpen() {
+			j.enqueueByAIStatus(model.AIStatusPending)
+		}
+		<-ticker.C
+	}
+}
+
+func (j *Judger) enqueueByAIStatus(aiStatus string) {
+	ids, err := j.submissionService.ListIDsByAIStatus(aiStatus, aiScanBatchSize)
+	if err != nil {
+		log.Printf("[Judger] 获取待 AI 分析的提交失败: %v", err)
+		return
+	}
+	for _, id := range ids {
+		j.aiQueue.Enqueue(aiTask{SubmissionID: id})
+	}
+}
+
+// analyzeSubmission 对等待 AI 审核或待重新分析的提交执行 AI 分析，并按严格模式更新最终状态与得分
+func (j *Judger) analyzeSubmission(task aiTask) {
+	submission, err := j.submissionService.GetByIDForJudge(task.SubmissionID)
+	if err != nil {
+		return
+	}
+	fromAIStatus := submission.AIStatus
+	if fromAIStatus != model.AIStatusReviewing && fromAIStatus != model.AIStatusPending {
+		return
+	}
+	problem, err := j.problemRepo.GetByID(submission.ProblemID)
+	if err != nil {
+		return
+	}
+
+	traditionalStatus := calculateTraditionalStatus(submission.TestcaseResults)
+	var aiResult *model.AIJudgeResult
+	if problem.AIJudgeConfig != nil && problem.AIJudgeConfig.Enabled {
+		log.Printf("[Judger] 执行 AI 判题: submission_id=%d", submission.ID)
+		aiResult, err = j.aiClient.AnalyzeCode(submission, problem, task.Force)
+		if err != nil {
+			log.Printf("[Judger] AI 判题出错: %v", err)
+		}
+		// 重新分析时 AI 仍不可用，保持待分析状态
+		if fromAIStatus == model.AIStatusPending && aiResult.Failed() && aiResult.FailurePolicy == model.AIFailurePolicyPending {
+			return
+		}
+	}
+
+	submission.Status = traditionalStatus
+	submission.FinalMessage = ""
+	applyAIResult(submission, problem, traditionalStatus, aiResult)
+	submission.Score = finalScore(submission, problem)
+
+	updated, err := j.submissionService.UpdateAIResult(submission, fromAIStatus)
+	if err != nil {
+		log.Printf("[Judger] 保存 AI 分析结果失败: submission_id=%d, err=%v", submission.ID, err)
+		return
+	}
+	if updated {
+		log.Printf("[Judger] AI 分析完成: submission_id=%d, status=%s, score=%d",
+			submission.ID, submission.Status, submission.Score)
+	}
+}
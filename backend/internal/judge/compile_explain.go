@@ -0,0 +1,61 @@
+package judge
+
+import (
+	"log"
+
+	"oj-system/internal/model"
+	"oj-system/internal/service"
+)
+
+// compileExplainRequested 是否为该提交生成编译错误解释：开启了编译错误解释、有编译输出，
+// 且不是进行中比赛的赛时提交
+func (j *Judger) compileExplainRequested(submission *model.Submission) bool {
+	if submission.Status != model.StatusCompileError || submission.CompileError == "" {
+		return false
+	}
+	settings := service.GetSettingService().GetAISettings()
+	if !settings.Enabled || !settings.CompileExplain {
+		return false
+	}
+	return !j.contestService.IsContestSubmission(submission.ProblemID, submission.UserID, submission.CreatedAt)
+}
+
+// enqueueCompileExplain 标记提交等待解释编译错误并加入 AI 队列；队列已满时由定期扫描补充入队
+func (j *Judger) enqueueCompileExplain(submissionID uint) {
+	if updated, err := j.submissionService.UpdateCompileExplanation(submissionID, "", model.CompileExplainStatusGenerating, nil); err != nil || !updated {
+		return
+	}
+	if !j.aiQueue.Enqueue(aiTask{Kind: aiTaskCompileExplain, SubmissionID: submissionID}) {
+		log.Printf("[Judger] AI 队列繁忙，编译错误解释稍后由定期扫描入队: submission_id=%d", submissionID)
+	}
+}
+
+func (j *Judger) enqueuePendingCompileExplains() {
+	ids, err := j.submissionService.ListIDsByCompileExplainStatus(model.CompileExplainStatusGenerating, aiScanBatchSize)
+	if err != nil {
+		log.Printf("[Judger] 获取待解释编译错误的提交失败: %v", err)
+		return
+	}
+	for _, id := range ids {
+		j.aiQueue.Enqueue(aiTask{Kind: aiTaskCompileExplain, SubmissionID: id})
+	}
+}
+
+// explainCompileError 为等待解释的提交调用 AI；失败时记录原因，不再重试
+func (j *Judger) explainCompileError(task aiTask) {
+	submission, err := j.submissionService.GetByIDForJudge(task.SubmissionID)
+	if err != nil || submission.CompileExplainStatus != model.CompileExplainStatusGenerating {
+		return
+	}
+
+	status := model.CompileExplainStatusDone
+	explanation, err := j.aiClient.ExplainCompileError(submission)
+	if err != nil {
+		log.Printf("[Judger] 解释编译错误失败: submission_id=%d, err=%v", submission.ID, err)
+		status = model.CompileExplainStatusFailed
+		explanation = &model.CompileExplanation{Error: err.Error()}
+	}
+	if _, err := j.submissionService.UpdateCompileExplanation(submission.ID, model.CompileExplainStatusGenerating, status, explanation); err != nil {
+		log.Printf("[Judger] 保存编译错误解释失败: submission_id=%d, err=%v", submission.ID, err)
+	}
+}
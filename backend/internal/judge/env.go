@@ -0,0 +1,65 @@
+package judge
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"oj-system/internal/config"
+	"oj-system/internal/judge/sandbox"
+	"oj-system/internal/model"
+)
+
+var judgeInfo = struct {
+	mu   sync.RWMutex
+	info model.JudgeInfo
+}{}
+
+// initJudgeInfo 探测当前节点的沙箱与各语言工具链版本（启动时调用一次）
+func initJudgeInfo(cfg *config.Config) {
+	info := model.JudgeInfo{
+		NodeID:           cfg.Judge.NodeID,
+		Sandbox:          cfg.Judge.Sandbox,
+		MemoryAccounting: sandbox.MemoryAccounting(),
+		Workers:          cfg.Judge.Workers,
+		Toolchains:       sandbox.ProbeToolchainVersions(),
+		ProbedAt:         time.Now(),
+	}
+	if info.Sandbox == "" {
+		info.Sandbox = "simple"
+	}
+
+	judgeInfo.mu.Lock()
+	judgeInfo.info = info
+	judgeInfo.mu.Unlock()
+
+	for language, version := range info.Toolchains {
+		log.Printf("[Judger] 工具链 %s: %s", language, version)
+	}
+}
+
+// GetJudgeInfo 获取判题节点信息
+func GetJudgeInfo() model.JudgeInfo {
+	judgeInfo.mu.RLock()
+	defer judgeInfo.mu.RUnlock()
+
+	info := judgeInfo.info
+	info.Toolchains = make(map[string]string, len(judgeInfo.info.Toolchains))
+	for language, version := range judgeInfo.info.Toolchains {
+		info.Toolchains[language] = version
+	}
+	return info
+}
+
+// currentJudgeEnv 生成指定语言的评测环境指纹
+func currentJudgeEnv(language string) *model.JudgeEnv {
+	judgeInfo.mu.RLock()
+	defer judgeInfo.mu.RUnlock()
+
+	return &model.JudgeEnv{
+		NodeID:           judgeInfo.info.NodeID,
+		Sandbox:          judgeInfo.info.Sandbox,
+		MemoryAccounting: judgeInfo.info.MemoryAccounting,
+		Toolchain:        judgeInfo.info.Toolchains[language],
+	}
+}
@@ -0,0 +1,274 @@
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"oj-system/internal/config"
+	"oj-system/internal/judge/sandbox"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const (
+	hackProgramTimeLimit   = 10000 // ms，校验器与标准程序单次运行时限
+	hackProgramMemoryLimit = 1024  // MB
+	hackMessageMaxLength   = 2000
+)
+
+// hackMu 串行执行 hack，避免同一目标提交被并发改写
+var hackMu sync.Mutex
+
+// EnqueueHack 将 hack 加入后台任务队列
+func EnqueueHack(hackID uint) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("hack_id=%d", hackID),
+		run:  func() { runHack(hackID) },
+		abort: func(message string) {
+			repo := repository.NewHackRepository()
+			hack, err := repo.GetByID(hackID)
+			if err != nil {
+				return
+			}
+			now := time.Now()
+			hack.Status = model.HackStatusError
+			hack.Message = message
+			hack.FinishedAt = &now
+			if err := repo.Save(hack); err != nil {
+				log.Printf("[Hack] 保存 hack 结果失败: %v", err)
+			}
+		},
+	})
+}
+
+// runHack 执行 hack：校验输入 → 标准程序生成答案 → 评测目标提交；
+// 目标未通过时将其判为对应状态并把该输入加入题目的 hack 测试点
+func runHack(hackID uint) {
+	hackMu.Lock()
+	defer hackMu.Unlock()
+
+	repo := repository.NewHackRepository()
+	problemRepo := repository.NewProblemRepository()
+	programRepo := repository.NewProblemProgramRepository()
+	submissionRepo := repository.NewSubmissionRepository()
+
+	hack, err := repo.GetByID(hackID)
+	if err != nil {
+		log.Printf("[Hack] hack 不存在: hack_id=%d", hackID)
+		return
+	}
+
+	finish := func(status, targetStatus, message string) {
+		now := time.Now()
+		hack.Status = status
+		hack.TargetStatus = targetStatus
+		hack.Message = message
+		hack.FinishedAt = &now
+		if err := repo.Save(hack); err != nil {
+			log.Printf("[Hack] 保存 hack 结果失败: %v", err)
+		}
+		log.Printf("[Hack] hack 完成: hack_id=%d, status=%s, target_status=%s", hack.ID, status, targetStatus)
+	}
+
+	problem, err := problemRepo.GetByID(hack.ProblemID)
+	if err != nil {
+		finish(model.HackStatusError, "", "题目不存在")
+		return
+	}
+	target, err := submissionRepo.GetByID(hack.TargetSubmissionID)
+	if err != nil {
+		finish(model.HackStatusError, "", "目标提交不存在")
+		return
+	}
+	if target.Status != model.StatusAccepted {
+		finish(model.HackStatusFailed, target.Status, "目标提交已不是 Accepted 状态")
+		return
+	}
+	validator, err := programRepo.GetByProblemAndRole(problem.ID, model.ProgramRoleValidator)
+	if err != nil {
+		finish(model.HackStatusError, "", "该题未配置输入校验器")
+		return
+	}
+	reference, err := programRepo.GetByProblemAndRole(problem.ID, model.ProgramRoleReference)
+	if err != nil {
+		finish(model.HackStatusError, "", "该题未配置标准程序")
+		return
+	}
+
+	taskName := fmt.Sprintf("hack-%d", hack.ID)
+	dataDir := sandbox.GetTaskWorkDir(taskName + "-data")
+	validatorDir := sandbox.GetTaskWorkDir(taskName + "-validator")
+	referenceDir := sandbox.GetTaskWorkDir(taskName + "-reference")
+	targetDir := sandbox.GetTaskWorkDir(taskName + "-target")
+	defer sandbox.CleanWorkDir(dataDir)
+	defer sandbox.CleanWorkDir(validatorDir)
+	defer sandbox.CleanWorkDir(referenceDir)
+	defer sandbox.CleanWorkDir(targetDir)
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		finish(model.HackStatusError, "", "创建工作目录失败")
+		return
+	}
+	inputPath := filepath.Join(dataDir, "input.txt")
+	answerPath := filepath.Join(dataDir, "answer.txt")
+	if err := os.WriteFile(inputPath, []byte(hack.Input), 0644); err != nil {
+		finish(model.HackStatusError, "", "写入 hack 输入失败")
+		return
+	}
+
+	sb := sandbox.NewSimpleSandbox()
+
+	// 1. 输入校验器：非 0 退出即视为输入不合法
+	if err := runHackProgram(sb, validatorDir, validator, inputPath, filepath.Join(dataDir, "validator.out")); err != nil {
+		finish(model.HackStatusInvalid, "", "输入未通过校验器: "+err.Error())
+		return
+	}
+
+	// 2. 标准程序生成答案
+	if err := runHackProgram(sb, referenceDir, reference, inputPath, answerPath); err != nil {
+		finish(model.HackStatusError, "", "标准程序运行失败: "+err.Error())
+		return
+	}
+
+	// 3. 按题目限制评测目标提交
+	testcases := []model.Testcase{{ProblemID: problem.ID, InputFile: inputPath, OutputFile: answerPath}}
+	results, _ := runProgramOnTestcases(sb, targetDir, 0, target.Language, target.Code, problem, testcases)
+	targetStatus := calculateTraditionalStatus(results)
+	switch targetStatus {
+	case model.StatusAccepted:
+		finish(model.HackStatusFailed, targetStatus, "目标提交通过了该输入")
+		return
+	case model.StatusSystemError:
+		finish(model.HackStatusError, targetStatus, "评测目标提交时发生系统错误")
+		return
+	}
+
+	testcase, err := addHackTestcase(problemRepo, problem.ID, hack.ID, inputPath, answerPath)
+	if err != nil {
+		finish(model.HackStatusError, targetStatus, "保存 hack 测试点失败")
+		return
+	}
+	hack.TestcaseID = testcase.ID
+
+	target.Status = targetStatus
+	target.Score = 0
+	if target.AIStatus == model.AIStatusReviewing || target.AIStatus == model.AIStatusPending || target.AIStatus == model.AIStatusManualReview {
+		// 已被 hack，不再等待 AI 分析或教师复核
+		target.AIStatus = model.AIStatusDone
+	}
+	target.FinalMessage = fmt.Sprintf("该提交已被 hack（#%d），hack 数据上的结果为 %s", hack.ID, targetStatus)
+	if err := submissionRepo.Update(target); err != nil {
+		log.Printf("[Hack] 更新目标提交失败: submission_id=%d, err=%v", target.ID, err)
+	}
+
+	finish(model.HackStatusSuccess, targetStatus, "hack 成功")
+}
+
+// runHackProgram 编译并以文件重定向方式运行校验器/标准程序
+func runHackProgram(sb sandbox.Sandbox, workDir string, program *model.ProblemProgram, inputPath, outputPath string) error {
+	prepareResult, err := sb.Prepare(workDir, program.Language, program.Code)
+	if err != nil {
+		return err
+	}
+	if prepareResult == nil || prepareResult.Status != "OK" {
+		if prepareResult != nil && prepareResult.Error != "" {
+			return errors.New(truncateHackMessage(prepareResult.Error))
+		}
+		return errors.New("预处理失败")
+	}
+
+	result, err := sb.RunWithFiles(workDir, program.Language, nil, inputPath, outputPath, hackProgramTimeLimit, hackProgramMemoryLimit, 0)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return errors.New("运行结果为空")
+	}
+	if result.Status != "OK" {
+		if result.Error != "" {
+			return fmt.Errorf("%s: %s", result.Status, truncateHackMessage(result.Error))
+		}
+		return errors.New(result.Status)
+	}
+	return nil
+}
+
+// addHackTestcase 将 hack 数据追加为题目的测试点
+func addHackTestcase(problemRepo *repository.ProblemRepository, problemID, hackID uint, inputPath, answerPath string) (*model.Testcase, error) {
+	problemDir := filepath.Join(config.GlobalConfig.Paths.Problems, fmt.Sprintf("%d", problemID))
+	if err := os.MkdirAll(problemDir, 0755); err != nil {
+		return nil, err
+	}
+	inputFile := filepath.Join(problemDir, fmt.Sprintf("hack-%d.in", hackID))
+	outputFile := filepath.Join(problemDir, fmt.Sprintf("hack-%d.out", hackID))
+	if err := copyHackFile(inputPath, inputFile); err != nil {
+		return nil, err
+	}
+	if err := copyHackFile(answerPath, outputFile); err != nil {
+		return nil, err
+	}
+
+	orderNum := 1
+	if testcases, err := problemRepo.GetTestcases(problemID); err == nil {
+		for _, tc := range testcases {
+			if tc.OrderNum >= orderNum {
+				orderNum = tc.OrderNum + 1
+			}
+		}
+	}
+
+	testcase := &model.Testcase{
+		ProblemID:  problemID,
+		InputFile:  inputFile,
+		OutputFile: outputFile,
+		Score:      0,
+		IsSample:   false,
+		IsHack:     true,
+		OrderNum:   orderNum,
+	}
+	if err := problemRepo.CreateTestcase(testcase); err != nil {
+		return nil, err
+	}
+	return testcase, nil
+}
+
+func copyHackFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func truncateHackMessage(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if len(msg) > hackMessageMaxLength {
+		return msg[:hackMessageMaxLength] + "..."
+	}
+	return msg
+}
+
+// recoverInterruptedHacks 服务重启后，将中断的 hack 标记为异常
+func recoverInterruptedHacks() {
+	repo := repository.NewHackRepository()
+	if err := repo.MarkPendingAsError("服务重启，hack 已中断"); err != nil {
+		log.Printf("[Hack] 恢复中断的 hack 失败: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package judge
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -17,18 +18,22 @@ import (
 // Judger 判题器
 type Judger struct {
 	sandbox           sandbox.Sandbox
-	aiClient          *ai.DeepSeekClient
+	aiClient          *ai.Client
 	submissionService *service.SubmissionService
+	contestService    *service.ContestService
 	problemRepo       *repository.ProblemRepository
+	aiQueue           *aiQueue
 }
 
 // NewJudger 创建判题器
 func NewJudger(cfg *config.Config) *Judger {
 	return &Judger{
 		sandbox:           sandbox.NewSimpleSandbox(),
-		aiClient:          ai.NewDeepSeekClient(),
+		aiClient:          ai.NewClient(),
 		submissionService: service.NewSubmissionService(),
+		contestService:    service.NewContestService(),
 		problemRepo:       repository.NewProblemRepository(),
+		aiQueue:           newAIQueue(aiQueueSize),
 	}
 }
 
@@ -36,6 +41,19 @@ func NewJudger(cfg *config.Config) *Judger {
 func Start(cfg *config.Config) {
 	judger := NewJudger(cfg)
 
+	// 初始化 cgroup 内存统计，失败时回退到 /proc 轮询
+	if err := sandbox.InitCgroup(cfg.Judge.CgroupRoot); err != nil {
+		log.Printf("[Judger] cgroup 内存统计不可用，回退到 /proc 轮询: %v", err)
+	} else {
+		log.Printf("[Judger] 使用 cgroup v2 统计内存")
+	}
+	// 探测判题环境（工具链版本等），随评测结果记录
+	initJudgeInfo(cfg)
+	// AI 分析独立排队，测试点结果先行发布
+	judger.startAIWorkers(cfg.Judge.AIWorkers)
+	// 题目校验等耗时的后台任务共用一个有界队列（见 task_queue.go）
+	startTaskWorkers(cfg.Judge.TaskWorkers)
+
 	// 初始化队列
 	queue.Init(100)
 	q := queue.GetQueue()
@@ -46,6 +64,13 @@ func Start(cfg *config.Config) {
 	// 启动 worker
 	q.Start(cfg.Judge.Workers)
 
+	recoverInterruptedVerifications()
+	recoverInterruptedGenerations()
+	recoverInterruptedHacks()
+	recoverInterruptedProposals()
+	recoverInterruptedPlagiarismChecks()
+	recoverInterruptedTranslations()
+
 	log.Printf("[Judger] 判题服务已启动")
 }
 
@@ -72,6 +97,7 @@ func (j *Judger) Handle(task *queue.JudgeTask) {
 
 	// 更新状态为 Judging
 	submission.Status = model.StatusJudging
+	submission.JudgeEnv = currentJudgeEnv(submission.Language)
 	j.submissionService.UpdateResult(submission)
 
 	// 1. 传统评测
@@ -79,7 +105,7 @@ func (j *Judger) Handle(task *queue.JudgeTask) {
 	submission.TestcaseResults = testcaseResults
 
 	// 计算传统评测结果
-	traditionalStatus := j.calculateTraditionalStatus(testcaseResults)
+	traditionalStatus := calculateTraditionalStatus(testcaseResults)
 	submission.Status = traditionalStatus
 
 	// 计算最大时间和内存
@@ -106,57 +132,144 @@ func (j *Judger) Handle(task *queue.JudgeTask) {
 		return
 	}
 
-	// 2. AI 评测（如果启用）
-	if problem.AIJudgeConfig != nil && problem.AIJudgeConfig.Enabled {
-		log.Printf("[Judger] 执行 AI 判题: submission_id=%d", submission.ID)
-		aiResult, err := j.aiClient.AnalyzeCode(problem, submission.Code, submission.Language)
-		if err != nil {
-			log.Printf("[Judger] AI 判题出错: %v", err)
-		}
-		submission.AIJudgeResult = aiResult
-
-		// 如果传统评测通过但 AI 判定不通过
-		if aiResult != nil && !aiResult.Passed && traditionalStatus == model.StatusAccepted {
-			if problem.AIJudgeConfig.StrictMode {
-				// 严格模式：AI 不通过则 WA
-				submission.Status = model.StatusWrongAnswer
-				submission.FinalMessage = "测试点全部通过，但未满足题目的算法/语言要求，判定为 Wrong Answer"
-			} else {
-				// 非严格模式：仅记录 AI 结果，不影响最终状态
-				submission.FinalMessage = "测试点全部通过。AI 分析提示：" + aiResult.Reason
-			}
+	// 2. AI 评测（如果启用）：先做静态规则检查，触发规则（或仅使用静态规则）时直接给出结果，不再调用 AI；
+	// 否则先发布测试点结果，AI 分析在独立队列中进行，完成后再修正状态与得分
+	aiEnabled := problem.AIJudgeConfig != nil && problem.AIJudgeConfig.Enabled
+	if aiEnabled {
+		if staticResult := staticCheckResult(submission, problem.AIJudgeConfig); staticResult != nil {
+			applyAIResult(submission, problem, traditionalStatus, staticResult)
+			aiEnabled = false
+		} else {
+			submission.AIStatus = model.AIStatusReviewing
 		}
 	}
 
 	// 计算得分
-	baseScore := j.calculateScore(testcaseResults, submission.Status == model.StatusAccepted)
-	if submission.AIJudgeResult != nil && !submission.AIJudgeResult.Passed {
-		cap := problem.AIJudgeConfig.GetMaxScoreIfNotMet()
-		if baseScore > cap {
-			baseScore = cap
-		}
-	}
-	submission.Score = baseScore
+	submission.Score = finalScore(submission, problem)
 
 	// 保存结果
 	if err := j.submissionService.UpdateResult(submission); err != nil {
 		log.Printf("[Judger] 保存结果失败: %v", err)
+		return
 	}
 
 	log.Printf("[Judger] 判题完成: submission_id=%d, status=%s, score=%d",
 		submission.ID, submission.Status, submission.Score)
+
+	if aiEnabled {
+		j.enqueueAI(submission.ID, task.ForceAI)
+	}
+	// 非 AC 提交按题目设置生成学习提示（比赛赛时提交不生成）
+	if j.hintRequested(submission, problem, traditionalStatus) {
+		j.enqueueHint(submission.ID)
+	}
+	// 编译错误按设置生成通俗解释（比赛赛时提交不生成）
+	if j.compileExplainRequested(submission) {
+		j.enqueueCompileExplain(submission.ID)
+	}
+}
+
+// applyAIResult 记录 AI 分析结果，并据此修正传统评测已通过的提交的最终状态
+func applyAIResult(submission *model.Submission, problem *model.Problem, traditionalStatus string, aiResult *model.AIJudgeResult) {
+	submission.AIJudgeResult = aiResult
+	submission.AIStatus = model.AIStatusDone
+	if aiResult == nil {
+		return
+	}
+
+	// AI 暂不可用：先按传统评测结果给出，稍后自动重新分析
+	if aiResult.Failed() && aiResult.FailurePolicy == model.AIFailurePolicyPending {
+		submission.AIStatus = model.AIStatusPending
+		if traditionalStatus == model.StatusAccepted {
+			submission.FinalMessage = "测试点全部通过。AI 分析暂不可用，稍后将自动重新分析"
+		}
+		return
+	}
+
+	// 置信度低于题目阈值：先按传统评测结果给出，等待教师确认或改判
+	if aiResult.Review == nil && needsManualReview(problem.AIJudgeConfig, aiResult) {
+		submission.AIStatus = model.AIStatusManualReview
+		aiResult.Review = &model.AIManualReview{
+			Status:    model.AIReviewPending,
+			Threshold: problem.AIJudgeConfig.ReviewThreshold,
+			AIPassed:  aiResult.Passed,
+		}
+		if traditionalStatus == model.StatusAccepted {
+			submission.FinalMessage = fmt.Sprintf("测试点全部通过。AI 判定置信度 %.2f 低于阈值 %.2f，等待教师复核",
+				aiResult.Confidence, problem.AIJudgeConfig.ReviewThreshold)
+		}
+		return
+	}
+
+	// 如果传统评测通过但 AI 判定不通过
+	if !aiResult.Passed && traditionalStatus == model.StatusAccepted {
+		if problem.AIJudgeConfig.StrictMode {
+			// 严格模式：AI 不通过则 WA
+			submission.Status = model.StatusWrongAnswer
+			submission.FinalMessage = "测试点全部通过，但未满足题目的算法/语言要求，判定为 Wrong Answer"
+		} else {
+			// 非严格模式：仅记录 AI 结果，不影响最终状态
+			submission.FinalMessage = "测试点全部通过。AI 分析提示：" + aiResult.Reason
+		}
+	}
+}
+
+// needsManualReview 模型给出的判定置信度低于题目配置的阈值时需要教师复核；
+// 静态规则的结论、未进行分析以及调用失败后按策略生成的结果不需要复核
+func needsManualReview(aiConfig *model.AIJudgeConfig, aiResult *model.AIJudgeResult) bool {
+	if aiConfig == nil || aiConfig.ReviewThreshold <= 0 {
+		return false
+	}
+	if aiResult.Failed() || aiResult.Source != "" {
+		return false
+	}
+	return aiResult.Confidence < aiConfig.ReviewThreshold
+}
+
+// finalScore 计算得分，AI 判定未满足要求时按题目配置封顶（等待教师复核期间不封顶）
+func finalScore(submission *model.Submission, problem *model.Problem) int {
+	baseScore := calculateScore(submission.TestcaseResults, submission.Status == model.StatusAccepted)
+	if submission.AIJudgeResult != nil && !submission.AIJudgeResult.Passed && !submission.AIJudgeResult.AwaitingReview() {
+		cap := problem.AIJudgeConfig.GetMaxScoreIfNotMet()
+		if baseScore > cap {
+			baseScore = cap
+		}
+	}
+	return baseScore
 }
 
 // runTestcases 运行所有测试点
 func (j *Judger) runTestcases(submission *model.Submission, problem *model.Problem, testcases []model.Testcase) []model.TestcaseResult {
+	results, compileError := runProgramOnTestcases(
+		j.sandbox,
+		sandbox.GetWorkDir(submission.ID),
+		submission.ID,
+		submission.Language,
+		submission.Code,
+		problem,
+		testcases,
+	)
+	if compileError != "" {
+		submission.CompileError = compileError
+	}
+	for i := range results {
+		if i < len(testcases) {
+			results[i].Hack = testcases[i].IsHack
+		}
+	}
+	return results
+}
+
+// runProgramOnTestcases 在 workDir 中编译并逐个测试点运行代码，返回测试点结果与编译错误信息。
+// abortID 为可被管理员终止的提交 ID，非提交类任务（如题目校验）传 0。
+func runProgramOnTestcases(sb sandbox.Sandbox, workDir string, abortID uint, language string, code string, problem *model.Problem, testcases []model.Testcase) ([]model.TestcaseResult, string) {
 	var results []model.TestcaseResult
-	workDir := sandbox.GetWorkDir(submission.ID)
 	fileIOEnabled := problem.FileIOEnabled && problem.FileInputName != "" && problem.FileOutputName != ""
 	inputName := filepath.Base(problem.FileInputName)
 	outputName := filepath.Base(problem.FileOutputName)
 
 	// 预处理仅执行一次（写入代码并按需编译），避免每个测试点重复编译。
-	prepareResult, err := j.sandbox.Prepare(workDir, submission.Language, submission.Code)
+	prepareResult, err := sb.Prepare(workDir, language, code)
 	if err != nil {
 		for i := range testcases {
 			results = append(results, model.TestcaseResult{
@@ -165,7 +278,7 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 				Message: err.Error(),
 			})
 		}
-		return results
+		return results, ""
 	}
 	if prepareResult == nil {
 		for i := range testcases {
@@ -175,10 +288,9 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 				Message: "预处理失败",
 			})
 		}
-		return results
+		return results, ""
 	}
 	if prepareResult.Status == model.StatusCompileError {
-		submission.CompileError = prepareResult.Error
 		for i := range testcases {
 			tcResult := model.TestcaseResult{
 				ID:     i + 1,
@@ -189,7 +301,7 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 			}
 			results = append(results, tcResult)
 		}
-		return results
+		return results, prepareResult.Error
 	}
 	if prepareResult.Status != "OK" {
 		msg := prepareResult.Error
@@ -203,11 +315,11 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 				Message: msg,
 			})
 		}
-		return results
+		return results, ""
 	}
 
 	for i, tc := range testcases {
-		if sandbox.IsSubmissionAbortRequested(submission.ID) {
+		if sandbox.IsSubmissionAbortRequested(abortID) {
 			results = append(results, model.TestcaseResult{
 				ID:      i + 1,
 				Status:  model.StatusSystemError,
@@ -263,13 +375,13 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 		if fileIOEnabled {
 			execInput = ""
 		}
-		execResult, err := j.sandbox.Run(
+		execResult, err := sb.Run(
 			workDir,
-			submission.Language,
+			language,
 			execInput,
 			problem.TimeLimit,
 			problem.MemoryLimit,
-			submission.ID,
+			abortID,
 		)
 
 		if err != nil {
@@ -287,7 +399,7 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 			Time:   execResult.Time,
 			Memory: execResult.Memory,
 		}
-		if sandbox.IsSubmissionAbortRequested(submission.ID) {
+		if sandbox.IsSubmissionAbortRequested(abortID) {
 			result.Status = model.StatusSystemError
 			result.Message = "管理员已终止评测"
 			results = append(results, result)
@@ -326,11 +438,11 @@ func (j *Judger) runTestcases(submission *model.Submission, problem *model.Probl
 		results = append(results, result)
 	}
 
-	return results
+	return results, ""
 }
 
 // calculateTraditionalStatus 计算传统评测状态
-func (j *Judger) calculateTraditionalStatus(results []model.TestcaseResult) string {
+func calculateTraditionalStatus(results []model.TestcaseResult) string {
 	if len(results) == 0 {
 		return model.StatusSystemError
 	}
@@ -373,29 +485,45 @@ func (j *Judger) calculateTraditionalStatus(results []model.TestcaseResult) stri
 	return worstStatus
 }
 
-// calculateScore 计算得分
-func (j *Judger) calculateScore(results []model.TestcaseResult, allPassed bool) int {
+// calculateScore 计算得分：按通过的普通测试点数计分；hack 测试点不计入得分，
+// 未通过任一 hack 测试点视为已被 hack，得分清零（与 hack 成功时的处理一致）
+func calculateScore(results []model.TestcaseResult, allPassed bool) int {
 	if allPassed {
 		return 100
 	}
 
-	// 按通过的测试点数计算得分
-	if len(results) == 0 {
-		return 0
-	}
-
+	total := 0
 	passed := 0
 	for _, r := range results {
+		if r.Hack {
+			if r.Status != model.StatusAccepted {
+				return 0
+			}
+			continue
+		}
+		total++
 		if r.Status == model.StatusAccepted {
 			passed++
 		}
 	}
+	if total == 0 {
+		return 0
+	}
 
-	return passed * 100 / len(results)
+	return passed * 100 / total
 }
 
 // SubmitToQueue 提交到判题队列
 func SubmitToQueue(submission *model.Submission) error {
+	return submitToQueue(submission, false)
+}
+
+// SubmitRejudgeToQueue 重测提交到判题队列；forceAI 为 true 时忽略 AI 分析缓存
+func SubmitRejudgeToQueue(submission *model.Submission, forceAI bool) error {
+	return submitToQueue(submission, forceAI)
+}
+
+func submitToQueue(submission *model.Submission, forceAI bool) error {
 	problemRepo := repository.NewProblemRepository()
 
 	// 获取题目
@@ -415,6 +543,7 @@ func SubmitToQueue(submission *model.Submission) error {
 		Submission: submission,
 		Problem:    problem,
 		Testcases:  testcases,
+		ForceAI:    forceAI,
 	}
 
 	// 加入队列
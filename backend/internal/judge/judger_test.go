@@ -0,0 +1,356 @@
+package judge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 测试使用的模拟规则：lower_bound 识别为二分查找，含 MOCK_FAIL 的代码模拟调用失败
+const testMockRules = `[
+	{"pattern": "MOCK_FAIL", "algorithm": "失败", "error": "模拟调用失败"},
+	{"pattern": "lower_bound", "algorithm": "二分查找", "aliases": ["二分", "binary search"]},
+	{"pattern": "for\\s*\\(", "algorithm": "枚举"}
+]`
+
+const (
+	binarySearchCode = "int main() { auto it = lower_bound(a, a + n, x); }"
+	bruteForceCode   = "int main() { for (int i = 0; i < n; i++) {} }"
+	failingCode      = "int main() { /* MOCK_FAIL */ }"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "oj-judge-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := setupTestEnv(dir)
+	if code == 0 {
+		code = m.Run()
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// setupTestEnv 初始化临时数据库，并配置使用模拟提供方的 AI 判题
+func setupTestEnv(dir string) int {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	repository.DB = db
+	if err := db.AutoMigrate(
+		&model.Setting{},
+		&model.AICache{},
+		&model.AIPromptTemplate{},
+		&model.AIPromptTemplateVersion{},
+		&model.AICallLog{},
+		&model.AIUsageDaily{},
+	); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	rulesPath := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(rulesPath, []byte(testMockRules), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	settings := map[string]string{
+		model.SettingAIEnabled:       "true",
+		model.SettingAIProvider:      ai.ProviderMock,
+		model.SettingAIAPIURL:        rulesPath,
+		model.SettingAIMaxRetries:    "0",
+		model.SettingAIFailurePolicy: model.AIFailurePolicyPass,
+	}
+	for key, value := range settings {
+		if err := service.GetSettingService().Set(key, value); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// setFailurePolicy 修改 AI 调用失败策略，测试结束后恢复为默认的 pass
+func setFailurePolicy(t *testing.T, policy string) {
+	t.Helper()
+	if err := service.GetSettingService().Set(model.SettingAIFailurePolicy, policy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		service.GetSettingService().Set(model.SettingAIFailurePolicy, model.AIFailurePolicyPass)
+	})
+}
+
+func newTestProblem(id uint, config *model.AIJudgeConfig) *model.Problem {
+	return &model.Problem{
+		ID:            id,
+		Title:         fmt.Sprintf("测试题目 %d", id),
+		Description:   "给定有序数组，查询 x 的位置",
+		AIJudgeConfig: config,
+	}
+}
+
+// acceptedSubmission 传统评测全部通过的提交
+func acceptedSubmission(code string) *model.Submission {
+	return &model.Submission{
+		Code:     code,
+		Language: "cpp",
+		Status:   model.StatusAccepted,
+		TestcaseResults: []model.TestcaseResult{
+			{ID: 1, Status: model.StatusAccepted},
+			{ID: 2, Status: model.StatusAccepted},
+		},
+	}
+}
+
+// judgeWithAI 按判题流程对传统评测已通过的提交进行 AI 分析并计算最终得分
+func judgeWithAI(t *testing.T, submission *model.Submission, problem *model.Problem, force bool) *model.AIJudgeResult {
+	t.Helper()
+	submission.ProblemID = problem.ID
+	result, err := ai.NewClient().AnalyzeCode(submission, problem, force)
+	if err != nil {
+		t.Fatalf("AnalyzeCode 返回错误: %v", err)
+	}
+	applyAIResult(submission, problem, model.StatusAccepted, result)
+	submission.Score = finalScore(submission, problem)
+	return result
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestStrictModeRejectsUnmetRequirement(t *testing.T) {
+	problem := newTestProblem(101, &model.AIJudgeConfig{
+		Enabled:           true,
+		RequiredAlgorithm: "二分查找",
+		StrictMode:        true,
+	})
+
+	submission := acceptedSubmission(bruteForceCode)
+	result := judgeWithAI(t, submission, problem, false)
+	if result.Passed {
+		t.Fatalf("未使用二分查找的代码不应通过 AI 判定: %+v", result)
+	}
+	if submission.Status != model.StatusWrongAnswer {
+		t.Errorf("严格模式下状态应为 %s，实际为 %s", model.StatusWrongAnswer, submission.Status)
+	}
+
+	submission = acceptedSubmission(binarySearchCode)
+	result = judgeWithAI(t, submission, problem, false)
+	if !result.Passed || submission.Status != model.StatusAccepted || submission.Score != 100 {
+		t.Errorf("使用二分查找的代码应通过: passed=%v status=%s score=%d", result.Passed, submission.Status, submission.Score)
+	}
+}
+
+func TestNonStrictModeKeepsAccepted(t *testing.T) {
+	problem := newTestProblem(102, &model.AIJudgeConfig{
+		Enabled:           true,
+		RequiredAlgorithm: "二分查找",
+	})
+
+	submission := acceptedSubmission(bruteForceCode)
+	judgeWithAI(t, submission, problem, false)
+	if submission.Status != model.StatusAccepted {
+		t.Errorf("非严格模式下状态应保持 %s，实际为 %s", model.StatusAccepted, submission.Status)
+	}
+	if !strings.Contains(submission.FinalMessage, "AI 分析提示") {
+		t.Errorf("非严格模式应记录 AI 提示，实际为 %q", submission.FinalMessage)
+	}
+}
+
+func TestMaxScoreIfNotMetCapsScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		problemID uint
+		cap       *int
+		code      string
+		want      int
+	}{
+		{"默认封顶 50 分", 103, nil, bruteForceCode, 50},
+		{"自定义封顶分数", 104, intPtr(30), bruteForceCode, 30},
+		{"封顶为 0", 105, intPtr(0), bruteForceCode, 0},
+		{"满足要求不封顶", 106, intPtr(30), binarySearchCode, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := newTestProblem(tt.problemID, &model.AIJudgeConfig{
+				Enabled:           true,
+				RequiredAlgorithm: "二分查找",
+				MaxScoreIfNotMet:  tt.cap,
+			})
+			submission := acceptedSubmission(tt.code)
+			judgeWithAI(t, submission, problem, false)
+			if submission.Score != tt.want {
+				t.Errorf("得分应为 %d，实际为 %d", tt.want, submission.Score)
+			}
+		})
+	}
+}
+
+func TestFailurePolicy(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantPassed bool
+		wantStatus string
+		wantAI     string
+		wantScore  int
+	}{
+		{model.AIFailurePolicyPass, true, model.StatusAccepted, model.AIStatusDone, 100},
+		{model.AIFailurePolicyFail, false, model.StatusWrongAnswer, model.AIStatusDone, 50},
+		{model.AIFailurePolicyPending, true, model.StatusAccepted, model.AIStatusPending, 100},
+	}
+	for i, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			setFailurePolicy(t, tt.policy)
+			problem := newTestProblem(uint(110+i), &model.AIJudgeConfig{
+				Enabled:           true,
+				RequiredAlgorithm: "二分查找",
+				StrictMode:        true,
+			})
+
+			submission := acceptedSubmission(failingCode)
+			result := judgeWithAI(t, submission, problem, false)
+			if !result.Failed() {
+				t.Fatalf("模拟调用失败时结果应标记为失败: %+v", result)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("passed 应为 %v，实际为 %v", tt.wantPassed, result.Passed)
+			}
+			if submission.Status != tt.wantStatus {
+				t.Errorf("状态应为 %s，实际为 %s", tt.wantStatus, submission.Status)
+			}
+			if submission.AIStatus != tt.wantAI {
+				t.Errorf("AI 状态应为 %s，实际为 %s", tt.wantAI, submission.AIStatus)
+			}
+			if submission.Score != tt.wantScore {
+				t.Errorf("得分应为 %d，实际为 %d", tt.wantScore, submission.Score)
+			}
+
+			// 调用失败的结果不缓存，下次重新分析
+			var cached int64
+			repository.DB.Model(&model.AICache{}).Where("problem_id = ?", problem.ID).Count(&cached)
+			if cached != 0 {
+				t.Errorf("调用失败的结果不应被缓存，实际缓存了 %d 条", cached)
+			}
+		})
+	}
+}
+
+func TestAnalysisCache(t *testing.T) {
+	config := &model.AIJudgeConfig{
+		Enabled:           true,
+		RequiredAlgorithm: "二分查找",
+	}
+	problem := newTestProblem(120, config)
+
+	first := judgeWithAI(t, acceptedSubmission(binarySearchCode), problem, false)
+	if first.Cached {
+		t.Fatal("首次分析不应命中缓存")
+	}
+
+	second := judgeWithAI(t, acceptedSubmission(binarySearchCode), problem, false)
+	if !second.Cached {
+		t.Error("相同题目与代码的第二次分析应命中缓存")
+	}
+	if second.Passed != first.Passed {
+		t.Errorf("缓存结果应与首次分析一致: first=%v second=%v", first.Passed, second.Passed)
+	}
+
+	forced := judgeWithAI(t, acceptedSubmission(binarySearchCode), problem, true)
+	if forced.Cached {
+		t.Error("强制重新分析时不应使用缓存")
+	}
+
+	other := newTestProblem(121, config)
+	if result := judgeWithAI(t, acceptedSubmission(binarySearchCode), other, false); result.Cached {
+		t.Error("不同题目的相同代码不应命中缓存")
+	}
+
+	changed := newTestProblem(120, config)
+	changed.Description = "给定有序数组，查询第一个不小于 x 的位置"
+	if result := judgeWithAI(t, acceptedSubmission(binarySearchCode), changed, false); result.Cached {
+		t.Error("题面修改后不应命中旧的缓存")
+	}
+}
+
+// setBudget 设置本月 token 预算与预算用完后的策略，测试结束后恢复为不限
+func setBudget(t *testing.T, budget int64, policy string) {
+	t.Helper()
+	settings := service.GetSettingService()
+	if err := settings.Set(model.SettingAIMonthlyTokenBudget, fmt.Sprintf("%d", budget)); err != nil {
+		t.Fatal(err)
+	}
+	if err := settings.Set(model.SettingAIBudgetPolicy, policy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		settings.Set(model.SettingAIMonthlyTokenBudget, "0")
+		settings.Set(model.SettingAIBudgetPolicy, model.AIFailurePolicyPass)
+	})
+}
+
+func countCallLogs(problemID uint) int64 {
+	var count int64
+	repository.DB.Model(&model.AICallLog{}).Where("problem_id = ?", problemID).Count(&count)
+	return count
+}
+
+func TestConsensusVotes(t *testing.T) {
+	problem := newTestProblem(130, &model.AIJudgeConfig{
+		Enabled:           true,
+		RequiredAlgorithm: "二分查找",
+		Consensus:         &model.AIConsensusConfig{Samples: 3},
+	})
+
+	result := judgeWithAI(t, acceptedSubmission(bruteForceCode), problem, false)
+	if len(result.Votes) != 3 || result.Passed {
+		t.Fatalf("应有 3 票且判定未满足要求: votes=%d passed=%v", len(result.Votes), result.Passed)
+	}
+	if n := countCallLogs(problem.ID); n != 3 {
+		t.Errorf("应调用 3 次，实际 %d 次", n)
+	}
+}
+
+// 预算只在开始前检查一次时，并发的各票会一起超支；剩余预算不足一票时不应发起任何调用
+func TestConsensusRespectsBudget(t *testing.T) {
+	used, err := service.NewAIUsageService().GetBudgetStatus(service.GetSettingService().GetAISettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setBudget(t, used.Used+1, model.AIFailurePolicyFail)
+
+	problem := newTestProblem(131, &model.AIJudgeConfig{
+		Enabled:           true,
+		RequiredAlgorithm: "二分查找",
+		Consensus:         &model.AIConsensusConfig{Samples: 3},
+	})
+	submission := acceptedSubmission(binarySearchCode)
+	result := judgeWithAI(t, submission, problem, false)
+	if n := countCallLogs(problem.ID); n != 0 {
+		t.Errorf("预算不足时不应发起调用，实际调用 %d 次", n)
+	}
+	if !result.Failed() || result.FailurePolicy != model.AIFailurePolicyFail || result.Passed {
+		t.Errorf("应按预算策略 fail 处理: %+v", result)
+	}
+	if submission.Score != 50 {
+		t.Errorf("得分应封顶为 50，实际为 %d", submission.Score)
+	}
+}
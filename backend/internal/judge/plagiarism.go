@@ -0,0 +1,134 @@
+package judge
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/judge/similarity"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// EnqueuePlagiarismCheck 将代码查重任务加入后台任务队列
+func EnqueuePlagiarismCheck(reportID uint) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("report_id=%d", reportID),
+		run:  func() { runPlagiarismCheck(reportID) },
+		abort: func(message string) {
+			plagiarismService := service.NewPlagiarismService()
+			report, err := plagiarismService.Get(reportID)
+			if err != nil {
+				return
+			}
+			if err := plagiarismService.Fail(report, message); err != nil {
+				log.Printf("[Plagiarism] 保存查重任务失败: %v", err)
+			}
+		},
+	})
+}
+
+// runPlagiarismCheck 执行代码查重：对范围内每个用户每题的最后一次提交，按题目与语言分组两两计算指纹相似度，
+// 需要时请 AI 复核相似度最高的若干对
+func runPlagiarismCheck(reportID uint) {
+	plagiarismService := service.NewPlagiarismService()
+	report, err := plagiarismService.Get(reportID)
+	if err != nil {
+		log.Printf("[Plagiarism] 查重任务不存在: report_id=%d", reportID)
+		return
+	}
+	finishWithError := func(message string) {
+		if err := plagiarismService.Fail(report, message); err != nil {
+			log.Printf("[Plagiarism] 保存查重任务失败: %v", err)
+		}
+	}
+
+	sources, err := plagiarismService.ListSources(report)
+	if err != nil {
+		finishWithError(err.Error())
+		return
+	}
+	log.Printf("[Plagiarism] 开始查重: report_id=%d, %s=%d, submissions=%d", report.ID, report.Scope, report.TargetID, len(sources))
+
+	docs := make([]similarity.Document, len(sources))
+	for i, src := range sources {
+		docs[i] = similarity.Document{
+			Group:    fmt.Sprintf("%d/%s", src.ProblemID, src.Language),
+			Language: src.Language,
+			Code:     src.Code,
+		}
+	}
+	found := similarity.Detect(docs, report.Threshold)
+
+	pairs := make([]model.PlagiarismPair, len(found))
+	for i, p := range found {
+		a, b := sources[p.A], sources[p.B]
+		pairs[i] = model.PlagiarismPair{
+			ProblemID:   a.ProblemID,
+			Language:    a.Language,
+			SubmissionA: a.SubmissionID,
+			UserA:       a.UserID,
+			UsernameA:   a.Username,
+			SubmissionB: b.SubmissionID,
+			UserB:       b.UserID,
+			UsernameB:   b.Username,
+			Similarity:  p.Similarity,
+			Matches:     p.Matches,
+		}
+	}
+
+	if report.AIReview {
+		codes := make(map[uint]string, len(sources))
+		for _, src := range sources {
+			codes[src.SubmissionID] = src.Code
+		}
+		reviewSimilarPairs(report, pairs, codes)
+	}
+
+	if err := plagiarismService.Complete(report, len(sources), pairs); err != nil {
+		log.Printf("[Plagiarism] 保存查重结果失败: %v", err)
+		return
+	}
+	log.Printf("[Plagiarism] 查重完成: report_id=%d, %s", report.ID, report.Message)
+}
+
+// reviewSimilarPairs 请 AI 依次复核相似度最高的 AIReviewLimit 对代码，失败原因记录在对应代码对上
+func reviewSimilarPairs(report *model.PlagiarismReport, pairs []model.PlagiarismPair, codes map[uint]string) {
+	client := ai.NewClient()
+	problemRepo := repository.NewProblemRepository()
+	problems := make(map[uint]*model.Problem)
+
+	for i := range pairs {
+		if i >= report.AIReviewLimit {
+			break
+		}
+		pair := &pairs[i]
+		problem, ok := problems[pair.ProblemID]
+		if !ok {
+			p, err := problemRepo.GetByID(pair.ProblemID)
+			if err != nil {
+				p = &model.Problem{ID: pair.ProblemID}
+			}
+			problem = p
+			problems[pair.ProblemID] = problem
+		}
+
+		review, modelName, err := client.ReviewSimilarPair(problem, pair, codes[pair.SubmissionA], codes[pair.SubmissionB])
+		if modelName != "" {
+			report.Model = modelName
+		}
+		if err != nil {
+			review = &model.PlagiarismAIReview{Error: err.Error(), CreatedAt: time.Now()}
+		}
+		pair.AIReview = review
+	}
+}
+
+// recoverInterruptedPlagiarismChecks 服务重启后，将中断的查重任务标记为异常
+func recoverInterruptedPlagiarismChecks() {
+	if err := service.NewPlagiarismService().RecoverInterrupted(); err != nil {
+		log.Printf("[Plagiarism] 恢复中断的查重任务失败: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package judge
+
+import (
+	"fmt"
+	"log"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// EnqueueProblemTranslation 将题面翻译任务加入后台任务队列
+func EnqueueProblemTranslation(problemID uint, locales []string) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("problem_id=%d, locales=%v", problemID, locales),
+		run:  func() { runProblemTranslation(problemID, locales) },
+		abort: func(message string) {
+			translationService := service.NewProblemTranslationService()
+			for _, code := range locales {
+				if err := translationService.FailMachineTranslation(problemID, code, message); err != nil {
+					log.Printf("[Translate] 保存翻译结果失败: %v", err)
+				}
+			}
+		},
+	})
+}
+
+// runProblemTranslation 依次将题面机器翻译为各目标语言，结果保存为待审核的草稿
+func runProblemTranslation(problemID uint, locales []string) {
+	translationService := service.NewProblemTranslationService()
+	client := ai.NewClient()
+
+	for _, code := range locales {
+		fail := func(message string) {
+			if err := translationService.FailMachineTranslation(problemID, code, message); err != nil {
+				log.Printf("[Translate] 保存翻译结果失败: %v", err)
+			}
+		}
+		locale, ok := model.FindProblemLocale(code)
+		if !ok {
+			fail("不支持的语言")
+			continue
+		}
+		// 每种语言翻译前重新读取题目，使用最新的原文
+		problem, err := repository.NewProblemRepository().GetByID(problemID)
+		if err != nil {
+			fail("题目不存在")
+			continue
+		}
+
+		log.Printf("[Translate] 开始翻译题面: problem_id=%d, locale=%s", problemID, code)
+		result, modelName, err := client.TranslateProblem(problem, locale)
+		if err != nil {
+			log.Printf("[Translate] 翻译失败: problem_id=%d, locale=%s, err=%v", problemID, code, err)
+			fail(err.Error())
+			continue
+		}
+		if err := translationService.CompleteMachineTranslation(problemID, code, result, problem.StatementHash(), modelName); err != nil {
+			log.Printf("[Translate] 保存翻译结果失败: %v", err)
+			continue
+		}
+		log.Printf("[Translate] 翻译完成: problem_id=%d, locale=%s", problemID, code)
+	}
+}
+
+// recoverInterruptedTranslations 服务重启后，将中断的题面翻译标记为失败
+func recoverInterruptedTranslations() {
+	if err := service.NewProblemTranslationService().RecoverInterrupted(); err != nil {
+		log.Printf("[Translate] 恢复中断的题面翻译失败: %v", err)
+	}
+}
@@ -15,6 +15,7 @@ type JudgeTask struct {
 	Submission *model.Submission
 	Problem    *model.Problem
 	Testcases  []model.Testcase
+	ForceAI    bool // 忽略 AI 分析缓存，重新调用 AI
 }
 
 // JudgeQueue 判题队列
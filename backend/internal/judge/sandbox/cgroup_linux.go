@@ -0,0 +1,171 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultCgroupRoot  = "/sys/fs/cgroup/oj-judge"
+	cgroup2SuperMagic  = 0x63677270
+	cgroupCleanupTries = 50
+)
+
+var (
+	cgroupRoot    string
+	cgroupEnabled atomic.Bool
+	cgroupSeq     atomic.Uint64
+)
+
+// InitCgroup 初始化 cgroup v2 内存统计。
+// root 为判题专用的 cgroup 目录（为空时使用 /sys/fs/cgroup/oj-judge，"off" 表示关闭）；
+// 初始化失败时返回错误，沙箱回退到 /proc 轮询方式。
+func InitCgroup(root string) error {
+	cgroupEnabled.Store(false)
+
+	root = strings.TrimSpace(root)
+	if root == "off" {
+		return errors.New("已在配置中关闭")
+	}
+	if root == "" {
+		root = defaultCgroupRoot
+	}
+	root = filepath.Clean(root)
+
+	parent := filepath.Dir(root)
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(parent, &fs); err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", parent, err)
+	}
+	if fs.Type != cgroup2SuperMagic {
+		return fmt.Errorf("%s 不是 cgroup v2 挂载点", parent)
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("创建 %s 失败: %v", root, err)
+	}
+	// 父级需向下委派 memory 控制器；已开启时写入失败可忽略，以下方检查为准
+	_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory"), 0644)
+	controllers, err := os.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err != nil || !containsField(string(controllers), "memory") {
+		return fmt.Errorf("%s 未启用 memory 控制器", root)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte("+memory"), 0644); err != nil {
+		return fmt.Errorf("为 %s 开启 memory 控制器失败: %v", root, err)
+	}
+
+	cgroupRoot = root
+	cgroupEnabled.Store(true)
+	return nil
+}
+
+// CgroupEnabled 返回是否使用 cgroup 统计内存
+func CgroupEnabled() bool {
+	return cgroupEnabled.Load()
+}
+
+// cgroupRun 单次运行专用的子 cgroup
+type cgroupRun struct {
+	path string
+	dir  *os.File
+}
+
+// newCgroupRun 为一次运行创建子 cgroup 并设置内存上限（MB，<=0 表示不限制）
+func newCgroupRun(memoryLimit int) (*cgroupRun, error) {
+	if !CgroupEnabled() {
+		return nil, errors.New("cgroup 未启用")
+	}
+
+	name := fmt.Sprintf("run-%d-%d", os.Getpid(), cgroupSeq.Add(1))
+	path := filepath.Join(cgroupRoot, name)
+	if err := os.Mkdir(path, 0755); err != nil {
+		return nil, err
+	}
+	run := &cgroupRun{path: path}
+
+	if memoryLimit > 0 {
+		limitBytes := strconv.FormatInt(int64(memoryLimit)*1024*1024, 10)
+		if err := os.WriteFile(filepath.Join(path, "memory.max"), []byte(limitBytes), 0644); err != nil {
+			run.cleanup()
+			return nil, err
+		}
+		// 禁止使用 swap 绕过内存上限（未开启 swap 统计时忽略）
+		_ = os.WriteFile(filepath.Join(path, "memory.swap.max"), []byte("0"), 0644)
+	}
+	// OOM 时结束整个进程树
+	_ = os.WriteFile(filepath.Join(path, "memory.oom.group"), []byte("1"), 0644)
+
+	dir, err := os.Open(path)
+	if err != nil {
+		run.cleanup()
+		return nil, err
+	}
+	run.dir = dir
+	return run, nil
+}
+
+// attach 让子进程在创建时直接进入该 cgroup（clone3 CLONE_INTO_CGROUP）
+func (c *cgroupRun) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(c.dir.Fd())
+}
+
+// stats 读取整个进程树的内存峰值（KB）以及是否发生过 OOM kill
+func (c *cgroupRun) stats() (int, bool) {
+	peakKB := 0
+	if data, err := os.ReadFile(filepath.Join(c.path, "memory.peak")); err == nil {
+		if bytes, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil && bytes > 0 {
+			peakKB = int(bytes / 1024)
+		}
+	}
+
+	oomKilled := false
+	if data, err := os.ReadFile(filepath.Join(c.path, "memory.events")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == "oom_kill" {
+				if count, err := strconv.Atoi(fields[1]); err == nil && count > 0 {
+					oomKilled = true
+				}
+			}
+		}
+	}
+	return peakKB, oomKilled
+}
+
+// cleanup 结束 cgroup 内残留进程并删除该 cgroup
+func (c *cgroupRun) cleanup() {
+	if c.dir != nil {
+		c.dir.Close()
+		c.dir = nil
+	}
+	_ = os.WriteFile(filepath.Join(c.path, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < cgroupCleanupTries; i++ {
+		if err := os.Remove(c.path); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func containsField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// InitCgroup 非 Linux 平台不支持 cgroup，沙箱始终使用回退方式统计内存
+func InitCgroup(root string) error {
+	return errors.New("仅 Linux 支持 cgroup")
+}
+
+// CgroupEnabled 返回是否使用 cgroup 统计内存
+func CgroupEnabled() bool {
+	return false
+}
+
+type cgroupRun struct{}
+
+func newCgroupRun(memoryLimit int) (*cgroupRun, error) {
+	return nil, errors.New("仅 Linux 支持 cgroup")
+}
+
+func (c *cgroupRun) attach(cmd *exec.Cmd) {}
+
+func (c *cgroupRun) stats() (int, bool) {
+	return 0, false
+}
+
+func (c *cgroupRun) cleanup() {}
@@ -3,7 +3,10 @@ package sandbox
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -37,6 +40,7 @@ type LanguageConfig struct {
 	SourceFile  string
 	CompileCmd  []string
 	ExecuteCmd  []string
+	VersionCmd  []string // 查询编译器/运行时版本
 	NeedCompile bool
 }
 
@@ -46,30 +50,35 @@ var languageConfigs = map[string]LanguageConfig{
 		SourceFile:  "main.c",
 		CompileCmd:  []string{"gcc", "-o", "main", "main.c", "-O2", "-Wall", "-lm", "-std=c11"},
 		ExecuteCmd:  []string{"./main"},
+		VersionCmd:  []string{"gcc", "--version"},
 		NeedCompile: true,
 	},
 	"cpp": {
 		SourceFile:  "main.cpp",
 		CompileCmd:  []string{"g++", "-o", "main", "main.cpp", "-O2", "-Wall", "-std=c++17"},
 		ExecuteCmd:  []string{"./main"},
+		VersionCmd:  []string{"g++", "--version"},
 		NeedCompile: true,
 	},
 	"python": {
 		SourceFile:  "main.py",
 		CompileCmd:  nil,
 		ExecuteCmd:  []string{"python3", "main.py"},
+		VersionCmd:  []string{"python3", "--version"},
 		NeedCompile: false,
 	},
 	"java": {
 		SourceFile:  "Main.java",
 		CompileCmd:  []string{"javac", "Main.java"},
 		ExecuteCmd:  []string{"java", "Main"},
+		VersionCmd:  []string{"java", "-version"},
 		NeedCompile: true,
 	},
 	"go": {
 		SourceFile:  "main.go",
 		CompileCmd:  []string{"go", "build", "-o", "main", "main.go"},
 		ExecuteCmd:  []string{"./main"},
+		VersionCmd:  []string{"go", "version"},
 		NeedCompile: true,
 	},
 }
@@ -79,8 +88,12 @@ type Sandbox interface {
 	Prepare(workDir string, language string, code string) (*PrepareResult, error)
 	Run(workDir string, language string, input string, timeLimit int, memoryLimit int, submissionID uint) (*ExecuteResult, error)
 	Execute(workDir string, language string, code string, input string, timeLimit int, memoryLimit int, submissionID uint) (*ExecuteResult, error)
+	RunWithFiles(workDir string, language string, args []string, inputPath string, outputPath string, timeLimit int, memoryLimit int, outputLimit int64) (*ExecuteResult, error)
 }
 
+// ErrOutputLimitExceeded RunWithFiles 的输出超过 outputLimit
+var ErrOutputLimitExceeded = errors.New("输出超过大小限制")
+
 // SimpleSandbox 简单沙箱（开发测试用）
 type SimpleSandbox struct{}
 
@@ -159,6 +172,67 @@ func (s *SimpleSandbox) Run(workDir string, language string, input string, timeL
 	return s.run(workDir, config.ExecuteCmd, input, timeLimit, memoryLimit, submissionID)
 }
 
+// RunWithFiles 执行已预处理好的程序，附加命令行参数，并将标准输入/输出重定向到文件。
+// inputPath 为空时标准输入为空；输出直接写入 outputPath，不会缓存在内存中，适合大数据生成。
+// outputLimit 大于 0 时限制输出的字节数，超过时中止写入并返回 ErrOutputLimitExceeded。
+func (s *SimpleSandbox) RunWithFiles(workDir string, language string, args []string, inputPath string, outputPath string, timeLimit int, memoryLimit int, outputLimit int64) (*ExecuteResult, error) {
+	config, ok := languageConfigs[language]
+	if !ok {
+		return &ExecuteResult{
+			Status: model.StatusSystemError,
+			Error:  "不支持的编程语言",
+		}, nil
+	}
+
+	var stdin io.Reader = strings.NewReader("")
+	if inputPath != "" {
+		inputFile, err := os.Open(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("打开输入文件失败: %v", err)
+		}
+		defer inputFile.Close()
+		stdin = inputFile
+	}
+
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建输出文件失败: %v", err)
+	}
+	defer outputFile.Close()
+
+	cmd := make([]string, 0, len(config.ExecuteCmd)+len(args))
+	cmd = append(cmd, config.ExecuteCmd...)
+	cmd = append(cmd, args...)
+	if outputLimit <= 0 {
+		return s.runWithIO(workDir, cmd, stdin, outputFile, timeLimit, memoryLimit, 0)
+	}
+
+	// 通过管道写入，超过上限后关闭管道，程序继续写出时收到 SIGPIPE
+	stdout := &limitedWriter{w: outputFile, remaining: outputLimit}
+	result, err := s.runWithIO(workDir, cmd, stdin, stdout, timeLimit, memoryLimit, 0)
+	if stdout.exceeded {
+		return nil, ErrOutputLimitExceeded
+	}
+	return result, err
+}
+
+// limitedWriter 最多写入 remaining 字节，超过后返回 ErrOutputLimitExceeded
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		l.exceeded = true
+		return 0, ErrOutputLimitExceeded
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
 // Execute 执行代码
 func (s *SimpleSandbox) Execute(workDir string, language string, code string, input string, timeLimit int, memoryLimit int, submissionID uint) (*ExecuteResult, error) {
 	prepareResult, err := s.Prepare(workDir, language, code)
@@ -211,17 +285,41 @@ func (s *SimpleSandbox) compile(workDir string, cmd []string) *ExecuteResult {
 
 // run 运行程序
 func (s *SimpleSandbox) run(workDir string, cmd []string, input string, timeLimit int, memoryLimit int, submissionID uint) (*ExecuteResult, error) {
+	var stdout bytes.Buffer
+	result, err := s.runWithIO(workDir, cmd, strings.NewReader(input), &stdout, timeLimit, memoryLimit, submissionID)
+	if result != nil {
+		result.Output = stdout.String()
+	}
+	return result, err
+}
+
+// runWithIO 以指定的标准输入/输出运行程序
+func (s *SimpleSandbox) runWithIO(workDir string, cmd []string, stdin io.Reader, stdout io.Writer, timeLimit int, memoryLimit int, submissionID uint) (*ExecuteResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeLimit+1000)*time.Millisecond)
 	defer cancel()
 
-	execCmd := buildRunCommand(ctx, cmd, memoryLimit)
+	// 优先使用 cgroup v2 统计整个进程树的真实内存峰值；不可用时回退到 /proc 轮询
+	var cg *cgroupRun
+	if CgroupEnabled() {
+		if run, err := newCgroupRun(memoryLimit); err == nil {
+			cg = run
+			defer cg.cleanup()
+		} else {
+			log.Printf("[Sandbox] 创建 cgroup 失败，本次运行回退到 /proc 轮询: %v", err)
+		}
+	}
+
+	execCmd := buildRunCommand(ctx, cmd, memoryLimit, cg == nil)
 	execCmd.Dir = workDir
+	if cg != nil {
+		cg.attach(execCmd)
+	}
 
-	// 设置输入
-	execCmd.Stdin = strings.NewReader(input)
+	// 设置输入输出
+	execCmd.Stdin = stdin
 
-	var stdout, stderr bytes.Buffer
-	execCmd.Stdout = &stdout
+	var stderr bytes.Buffer
+	execCmd.Stdout = stdout
 	execCmd.Stderr = &stderr
 
 	if err := execCmd.Start(); err != nil {
@@ -237,27 +335,42 @@ func (s *SimpleSandbox) run(workDir string, cmd []string, input string, timeLimi
 	if memoryLimit > 0 {
 		memoryLimitKB = memoryLimit * 1024
 	}
-	monitorStop, monitorResult := startProcessMemoryMonitor(execCmd.Process, memoryLimitKB)
+	monitorStop := func() {}
+	var monitorResult <-chan processMemorySample
+	if cg == nil {
+		monitorStop, monitorResult = startProcessMemoryMonitor(execCmd.Process, memoryLimitKB)
+	}
 
 	startTime := time.Now()
 	err := execCmd.Wait()
 	monitorStop()
-	sample := <-monitorResult
 	elapsed := time.Since(startTime)
 	timeUsed := int(elapsed.Milliseconds())
-	memoryUsed := sample.vmPeakKB
-	if memoryUsed <= 0 {
-		memoryUsed = sample.vmCurrentKB
-	}
-	if memoryUsed <= 0 {
-		memoryUsed = getProcessMaxRSSKB(execCmd.ProcessState)
+
+	var memoryUsed int
+	var memoryExceeded bool
+	if cg != nil {
+		peakKB, oomKilled := cg.stats()
+		memoryUsed = peakKB
+		if memoryUsed <= 0 {
+			memoryUsed = getProcessMaxRSSKB(execCmd.ProcessState)
+		}
+		memoryExceeded = oomKilled || (memoryLimitKB > 0 && memoryUsed > memoryLimitKB)
+	} else {
+		sample := <-monitorResult
+		memoryUsed = sample.vmPeakKB
+		if memoryUsed <= 0 {
+			memoryUsed = sample.vmCurrentKB
+		}
+		if memoryUsed <= 0 {
+			memoryUsed = getProcessMaxRSSKB(execCmd.ProcessState)
+		}
+		memoryExceeded = sample.exceeded || (memoryLimitKB > 0 && memoryUsed > memoryLimitKB)
 	}
-	memoryExceeded := sample.exceeded || (memoryLimitKB > 0 && memoryUsed > memoryLimitKB)
 
 	result := &ExecuteResult{
 		Time:   timeUsed,
 		Memory: memoryUsed,
-		Output: stdout.String(),
 	}
 
 	// 检查超时
@@ -276,7 +389,8 @@ func (s *SimpleSandbox) run(workDir string, cmd []string, input string, timeLimi
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
-			if memoryExceeded || looksLikeMemoryLimitError(stderr.String()) {
+			// cgroup 模式下以 OOM kill 事件为准，不再依赖 stderr 关键字
+			if memoryExceeded || (cg == nil && looksLikeMemoryLimitError(stderr.String())) {
 				result.Status = model.StatusMemoryLimitExceeded
 				return result, nil
 			}
@@ -305,8 +419,9 @@ func (s *SimpleSandbox) run(workDir string, cmd []string, input string, timeLimi
 }
 
 // buildRunCommand 构建运行命令。
-// Linux 下将进程栈上限与虚拟内存上限都设置为 memoryLimit（MB）对应的 KB。
-func buildRunCommand(ctx context.Context, cmd []string, memoryLimit int) *exec.Cmd {
+// Linux 下将进程栈上限设置为 memoryLimit（MB）对应的 KB；limitVirtual 为 true 时（未使用 cgroup）
+// 同时限制虚拟内存。cgroup 模式下由 memory.max 按实际占用限制，避免误杀预留大量虚拟地址的 Go/Java 运行时。
+func buildRunCommand(ctx context.Context, cmd []string, memoryLimit int, limitVirtual bool) *exec.Cmd {
 	if len(cmd) == 0 {
 		return exec.CommandContext(ctx, "")
 	}
@@ -322,9 +437,13 @@ func buildRunCommand(ctx context.Context, cmd []string, memoryLimit int) *exec.C
 	if memoryLimit > 0 {
 		limitKB = memoryLimit * 1024
 	}
+	script := "if [ \"$1\" -gt 0 ]; then ulimit -s \"$1\"; ulimit -v \"$1\"; fi; shift; exec \"$@\""
+	if !limitVirtual {
+		script = "if [ \"$1\" -gt 0 ]; then ulimit -s \"$1\"; fi; shift; exec \"$@\""
+	}
 	args := []string{
 		"-c",
-		"if [ \"$1\" -gt 0 ]; then ulimit -s \"$1\"; ulimit -v \"$1\"; fi; shift; exec \"$@\"",
+		script,
 		"sandbox",
 		strconv.Itoa(limitKB),
 	}
@@ -413,6 +532,7 @@ func parseProcStatusKB(data []byte, key string) int {
 	return 0
 }
 
+// looksLikeMemoryLimitError 回退模式（无 cgroup）下根据 stderr 关键字推测是否因内存不足退出
 func looksLikeMemoryLimitError(stderr string) bool {
 	if stderr == "" {
 		return false
@@ -460,6 +580,11 @@ func GetWorkDir(submissionID uint) string {
 	return fmt.Sprintf("./data/sandbox/%d", submissionID)
 }
 
+// GetTaskWorkDir 获取非提交类任务（数据生成、题目校验等）的工作目录
+func GetTaskWorkDir(name string) string {
+	return fmt.Sprintf("./data/sandbox/%s", name)
+}
+
 // CleanWorkDir 清理工作目录
 func CleanWorkDir(workDir string) {
 	os.RemoveAll(workDir)
@@ -0,0 +1,47 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const versionProbeTimeout = 5 * time.Second
+
+// ProbeToolchainVersions 探测各语言编译器/运行时版本（取版本输出的第一行）
+func ProbeToolchainVersions() map[string]string {
+	versions := make(map[string]string, len(languageConfigs))
+	for language, config := range languageConfigs {
+		if len(config.VersionCmd) == 0 {
+			continue
+		}
+		versions[language] = probeVersion(config.VersionCmd)
+	}
+	return versions
+}
+
+// MemoryAccounting 返回当前的内存统计方式
+func MemoryAccounting() string {
+	if CgroupEnabled() {
+		return "cgroup-v2"
+	}
+	return "proc-poll"
+}
+
+func probeVersion(command []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), versionProbeTimeout)
+	defer cancel()
+
+	// java -version 等会把版本信息写到 stderr
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return "unavailable"
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "unknown"
+}
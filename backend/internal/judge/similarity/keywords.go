@@ -0,0 +1,31 @@
+package similarity
+
+import "strings"
+
+// keywords 归一化时保留原文的关键字与基本类型（C/C++、Java、Python、Go 合并），
+// 其余标识符视为可随意改名的变量、函数名
+var keywords = makeKeywordSet(
+	// C / C++
+	"auto break case char const continue default do double else enum extern float for goto if inline int long "+
+		"register return short signed sizeof static struct switch typedef union unsigned void volatile while "+
+		"bool class delete false friend namespace new nullptr operator private protected public template this "+
+		"throw true try catch typename using virtual include define",
+	// Java
+	"abstract boolean byte extends final finally implements import instanceof interface native null package "+
+		"super synchronized throws transient var",
+	// Python
+	"and as assert async await def del elif except from global in is lambda nonlocal not or pass raise with yield "+
+		"None True False",
+	// Go
+	"chan defer fallthrough func go map range select type",
+)
+
+func makeKeywordSet(groups ...string) map[string]bool {
+	set := make(map[string]bool)
+	for _, group := range groups {
+		for _, word := range strings.Fields(group) {
+			set[word] = true
+		}
+	}
+	return set
+}
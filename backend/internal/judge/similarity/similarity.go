@@ -0,0 +1,231 @@
+// Package similarity 基于词法标记与 winnowing 指纹（MOSS 使用的算法）计算代码相似度。
+// 标识符统一替换、字面量归一化，因此改变量名、改常量、调整空白与注释都不会降低相似度
+package similarity
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"oj-system/internal/judge/staticcheck"
+	"oj-system/internal/model"
+)
+
+const (
+	// kgramSize 每个指纹覆盖的连续标记数，越大越不容易把常见写法当作相同片段
+	kgramSize = 12
+	// windowSize winnowing 窗口大小：长度不小于 windowSize+kgramSize-1 个标记的相同片段一定会被发现
+	windowSize = 8
+	// commonMinDocuments 同组文档达到该数量时才过滤公共指纹
+	commonMinDocuments = 4
+	// commonRatio 出现在超过该比例文档中的指纹视为模板代码（头文件、输入输出框架等），不计入相似度
+	commonRatio = 0.5
+)
+
+// Document 参与比较的一份代码
+type Document struct {
+	Group    string // 只在同组内两两比较，例如同一题目的同一语言
+	Language string
+	Code     string
+}
+
+// Pair 一对相似的文档，A、B 为 Detect 输入中的下标（A < B）
+type Pair struct {
+	A, B       int
+	Similarity float64
+	Matches    []model.PlagiarismMatch
+}
+
+// fingerprint 选中的指纹及其覆盖的行
+type fingerprint struct {
+	hash      uint64
+	startLine int
+	endLine   int
+}
+
+type fingerprints struct {
+	list  []fingerprint
+	first map[uint64]int // 指纹 -> 在 list 中首次出现的下标
+}
+
+// Detect 在每组内两两比较，返回相似度不低于 threshold 的文档对（按相似度降序）
+func Detect(docs []Document, threshold float64) []Pair {
+	groups := make(map[string][]int)
+	var groupOrder []string
+	for i, doc := range docs {
+		if _, ok := groups[doc.Group]; !ok {
+			groupOrder = append(groupOrder, doc.Group)
+		}
+		groups[doc.Group] = append(groups[doc.Group], i)
+	}
+
+	var pairs []Pair
+	for _, group := range groupOrder {
+		pairs = append(pairs, detectGroup(docs, groups[group], threshold)...)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Similarity > pairs[j].Similarity
+	})
+	return pairs
+}
+
+func detectGroup(docs []Document, members []int, threshold float64) []Pair {
+	if len(members) < 2 {
+		return nil
+	}
+	prints := make([]*fingerprints, len(members))
+	frequency := make(map[uint64]int)
+	for k, idx := range members {
+		prints[k] = winnow(normalize(staticcheck.Tokenize(docs[idx].Code, docs[idx].Language)))
+		for hash := range prints[k].first {
+			frequency[hash]++
+		}
+	}
+
+	common := make(map[uint64]bool)
+	if len(members) >= commonMinDocuments {
+		for hash, count := range frequency {
+			if float64(count) > float64(len(members))*commonRatio {
+				common[hash] = true
+			}
+		}
+	}
+
+	// 倒排索引：指纹 -> 包含它的文档，只统计至少共享一个指纹的文档对
+	sizes := make([]int, len(members))
+	index := make(map[uint64][]int)
+	for k, fp := range prints {
+		for hash := range fp.first {
+			if common[hash] {
+				continue
+			}
+			sizes[k]++
+			index[hash] = append(index[hash], k)
+		}
+	}
+	shared := make(map[[2]int]int)
+	for _, holders := range index {
+		for x := 0; x < len(holders); x++ {
+			for y := x + 1; y < len(holders); y++ {
+				shared[[2]int{holders[x], holders[y]}]++
+			}
+		}
+	}
+
+	var pairs []Pair
+	for key, count := range shared {
+		x, y := key[0], key[1]
+		similarity := 2 * float64(count) / float64(sizes[x]+sizes[y])
+		if similarity < threshold {
+			continue
+		}
+		pairs = append(pairs, Pair{
+			A:          members[x],
+			B:          members[y],
+			Similarity: similarity,
+			Matches:    matchRegions(prints[x], prints[y], common),
+		})
+	}
+	return pairs
+}
+
+// normalizedToken 归一化后的标记
+type normalizedToken struct {
+	text string
+	line int
+}
+
+// normalize 关键字与符号保留原文，其余标识符统一为 v，字面量统一为 0
+func normalize(tokens []staticcheck.Token) []normalizedToken {
+	result := make([]normalizedToken, 0, len(tokens))
+	for _, t := range tokens {
+		text := t.Text
+		switch {
+		case t.Literal:
+			text = "0"
+		case t.Ident && !keywords[text]:
+			text = "v"
+		}
+		result = append(result, normalizedToken{text: text, line: t.Line})
+	}
+	return result
+}
+
+// winnow 计算每个 k-gram 的哈希，在每个窗口中选取最小值（相同时取最右）作为指纹
+func winnow(tokens []normalizedToken) *fingerprints {
+	result := &fingerprints{first: make(map[uint64]int)}
+	if len(tokens) < kgramSize {
+		return result
+	}
+
+	hashes := make([]uint64, len(tokens)-kgramSize+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+kgramSize] {
+			h.Write([]byte(t.text))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+
+	add := func(pos int) {
+		fp := fingerprint{hash: hashes[pos], startLine: tokens[pos].line, endLine: tokens[pos+kgramSize-1].line}
+		if _, ok := result.first[fp.hash]; !ok {
+			result.first[fp.hash] = len(result.list)
+		}
+		result.list = append(result.list, fp)
+	}
+
+	if len(hashes) <= windowSize {
+		minPos := 0
+		for i := range hashes {
+			if hashes[i] <= hashes[minPos] {
+				minPos = i
+			}
+		}
+		add(minPos)
+		return result
+	}
+
+	last := -1
+	for start := 0; start+windowSize <= len(hashes); start++ {
+		minPos := start
+		for i := start; i < start+windowSize; i++ {
+			if hashes[i] <= hashes[minPos] {
+				minPos = i
+			}
+		}
+		if minPos != last {
+			add(minPos)
+			last = minPos
+		}
+	}
+	return result
+}
+
+// matchRegions 按 A 中的顺序列出共享指纹覆盖的行，并合并相邻或重叠的片段
+func matchRegions(a, b *fingerprints, common map[uint64]bool) []model.PlagiarismMatch {
+	var matches []model.PlagiarismMatch
+	for _, fp := range a.list {
+		if common[fp.hash] {
+			continue
+		}
+		k, ok := b.first[fp.hash]
+		if !ok {
+			continue
+		}
+		other := b.list[k]
+		m := model.PlagiarismMatch{AStart: fp.startLine, AEnd: fp.endLine, BStart: other.startLine, BEnd: other.endLine}
+		if n := len(matches); n > 0 {
+			last := &matches[n-1]
+			if m.AStart <= last.AEnd+1 && m.BStart <= last.BEnd+1 && m.BEnd >= last.BStart-1 {
+				last.AStart = min(last.AStart, m.AStart)
+				last.AEnd = max(last.AEnd, m.AEnd)
+				last.BStart = min(last.BStart, m.BStart)
+				last.BEnd = max(last.BEnd, m.BEnd)
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
@@ -0,0 +1,39 @@
+package judge
+
+import (
+	"strings"
+
+	"oj-system/internal/judge/staticcheck"
+	"oj-system/internal/model"
+)
+
+// staticCheckResult 按题目的静态检查规则检查提交：触发规则时返回未满足要求的结果；
+// 未触发且题目仅使用静态规则时返回满足要求的结果；其余情况返回 nil，继续由 AI 分析
+func staticCheckResult(submission *model.Submission, aiConfig *model.AIJudgeConfig) *model.AIJudgeResult {
+	violations := staticcheck.Check(submission.Code, submission.Language, aiConfig.StaticRules)
+	if len(violations) > 0 {
+		messages := make([]string, 0, len(violations))
+		for _, v := range violations {
+			messages = append(messages, v.Message)
+		}
+		reason := "触发静态检查规则：" + strings.Join(messages, "；")
+		return &model.AIJudgeResult{
+			Enabled:    true,
+			Passed:     false,
+			Reason:     reason,
+			Summary:    reason,
+			Source:     model.AIJudgeSourceStatic,
+			Violations: violations,
+		}
+	}
+	if aiConfig.StaticOnly {
+		return &model.AIJudgeResult{
+			Enabled: true,
+			Passed:  true,
+			Reason:  "未触发静态检查规则",
+			Summary: "未触发静态检查规则",
+			Source:  model.AIJudgeSourceStatic,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package staticcheck
+
+import (
+	"go/parser"
+	gotoken "go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// importRef 代码中引入的头文件/包/模块
+type importRef struct {
+	path string
+	line int
+}
+
+var (
+	cIncludeRe     = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*include[ \t]*[<"]([^>"\n]+)[>"]`)
+	javaImportRe   = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+(?:static[ \t]+)?([\w.*]+)[ \t]*;`)
+	pyImportRe     = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([^\n;]+)`)
+	pyFromImportRe = regexp.MustCompile(`(?m)^[ \t]*from[ \t]+([\w.]+)[ \t]+import\b`)
+)
+
+// collectImports 提取代码引入的头文件/包/模块；stripped 为去掉注释后的代码
+func collectImports(code, stripped, language string) []importRef {
+	switch language {
+	case "c", "cpp":
+		return regexpImports(stripped, cIncludeRe)
+	case "java":
+		return regexpImports(stripped, javaImportRe)
+	case "python":
+		var refs []importRef
+		for _, ref := range regexpImports(stripped, pyImportRe) {
+			// import a, b.c as d
+			for _, part := range strings.Split(ref.path, ",") {
+				if fields := strings.Fields(part); len(fields) > 0 {
+					refs = append(refs, importRef{path: fields[0], line: ref.line})
+				}
+			}
+		}
+		return append(refs, regexpImports(stripped, pyFromImportRe)...)
+	case "go":
+		return goImports(code)
+	}
+	return nil
+}
+
+func regexpImports(code string, re *regexp.Regexp) []importRef {
+	var refs []importRef
+	for _, m := range re.FindAllStringSubmatchIndex(code, -1) {
+		refs = append(refs, importRef{
+			path: strings.TrimSpace(code[m[2]:m[3]]),
+			line: strings.Count(code[:m[0]], "\n") + 1,
+		})
+	}
+	return refs
+}
+
+// goImports 使用 go/parser 解析 Go 代码的 import 声明
+func goImports(code string) []importRef {
+	fset := gotoken.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, parser.ImportsOnly)
+	if err != nil || file == nil {
+		return nil
+	}
+	var refs []importRef
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, importRef{path: path, line: fset.Position(spec.Pos()).Line})
+	}
+	return refs
+}
+
+// importMatches 引入路径是否命中规则：完全相同、为其子包/子模块，或 Java 通配导入包含了该类
+func importMatches(path, pattern string) bool {
+	if path == pattern {
+		return true
+	}
+	if strings.HasPrefix(path, pattern+".") || strings.HasPrefix(path, pattern+"/") {
+		return true
+	}
+	if strings.HasSuffix(path, ".*") && strings.HasPrefix(pattern, strings.TrimSuffix(path, "*")) {
+		return true
+	}
+	return false
+}
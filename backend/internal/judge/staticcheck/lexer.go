@@ -0,0 +1,186 @@
+package staticcheck
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// multiPuncts 需要整体识别的多字符符号（按长度优先匹配）
+var multiPuncts = []string{"::", "->", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||", "++", "--", ":=", "**", "//"}
+
+// scan 词法扫描：返回标记序列（不含注释），以及把注释替换为空白后的代码（保留换行，行号不变）。
+// python 使用 # 注释，其余语言使用 // 与 /* */
+func scan(code, language string) ([]token, string) {
+	hashComment := language == "python"
+	out := []byte(code)
+	blank := func(from, to int) {
+		for k := from; k < to; k++ {
+			if out[k] != '\n' {
+				out[k] = ' '
+			}
+		}
+	}
+
+	var tokens []token
+	line := 1
+	n := len(code)
+	for i := 0; i < n; {
+		c := code[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case hashComment && c == '#', !hashComment && c == '/' && i+1 < n && code[i+1] == '/':
+			end := lineEnd(code, i)
+			blank(i, end)
+			i = end
+		case !hashComment && c == '/' && i+1 < n && code[i+1] == '*':
+			end := n
+			if j := strings.Index(code[i+2:], "*/"); j >= 0 {
+				end = i + 2 + j + 2
+			}
+			line += strings.Count(code[i:end], "\n")
+			blank(i, end)
+			i = end
+		case c == '"' || c == '\'' || c == '`' && language == "go":
+			end := stringEnd(code, i, language)
+			tokens = append(tokens, token{kind: tokString, text: code[i:end], line: line})
+			line += strings.Count(code[i:end], "\n")
+			i = end
+		case isIdentStart(c):
+			j := i
+			for j < n && isIdentPart(code[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: code[i:j], line: line})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < n && (isIdentPart(code[j]) || code[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: code[i:j], line: line})
+			i = j
+		default:
+			text := code[i : i+1]
+			for _, p := range multiPuncts {
+				if strings.HasPrefix(code[i:], p) {
+					text = p
+					break
+				}
+			}
+			tokens = append(tokens, token{kind: tokPunct, text: text, line: line})
+			i += len(text)
+		}
+	}
+	return tokens, string(out)
+}
+
+func lineEnd(code string, from int) int {
+	if j := strings.IndexByte(code[from:], '\n'); j >= 0 {
+		return from + j
+	}
+	return len(code)
+}
+
+// stringEnd 返回从 start 开始的字符串/字符字面量的结束位置（不含）；未闭合时到行尾为止
+func stringEnd(code string, start int, language string) int {
+	quote := code[start]
+	n := len(code)
+	if language == "python" && start+2 < n && code[start+1] == quote && code[start+2] == quote {
+		delim := code[start : start+3]
+		if j := strings.Index(code[start+3:], delim); j >= 0 {
+			return start + 3 + j + 3
+		}
+		return n
+	}
+	for j := start + 1; j < n; j++ {
+		switch {
+		case code[j] == '\\' && quote != '`':
+			j++
+		case code[j] == quote:
+			return j + 1
+		case code[j] == '\n' && quote != '`':
+			return j
+		}
+	}
+	return n
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// matchTokens 在 tokens 中查找与 pattern 完全相同的连续标记序列，返回首个匹配的位置
+func matchTokens(tokens, pattern []token) int {
+	if len(pattern) == 0 {
+		return -1
+	}
+	for i := 0; i+len(pattern) <= len(tokens); i++ {
+		matched := true
+		for k, p := range pattern {
+			t := tokens[i+k]
+			if t.kind == tokString || t.text != p.text {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// codeTokens 去掉字符串字面量后的标记，用于解析规则中的标记模式
+func codeTokens(pattern, language string) []token {
+	tokens, _ := scan(pattern, language)
+	result := tokens[:0]
+	for _, t := range tokens {
+		if t.kind != tokString {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Token 对外提供的词法标记，代码相似度检测复用同一套扫描规则
+type Token struct {
+	Text    string
+	Line    int
+	Ident   bool // 标识符（含关键字）
+	Literal bool // 数字、字符或字符串字面量
+}
+
+// Tokenize 返回代码去掉注释后的标记序列
+func Tokenize(code, language string) []Token {
+	tokens, _ := scan(code, language)
+	result := make([]Token, len(tokens))
+	for i, t := range tokens {
+		result[i] = Token{
+			Text:    t.text,
+			Line:    t.line,
+			Ident:   t.kind == tokIdent,
+			Literal: t.kind == tokNumber || t.kind == tokString,
+		}
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package staticcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"strings"
+)
+
+// recursiveCall 函数直接调用自身的位置
+type recursiveCall struct {
+	function string
+	line     int
+}
+
+// findRecursion 查找直接递归：Go 使用 go/ast 精确分析，其余语言按词法结构识别函数定义与函数体
+func findRecursion(code, stripped, language string, tokens []token) *recursiveCall {
+	switch language {
+	case "go":
+		if call, ok := goRecursion(code); ok {
+			return call
+		}
+		// 语法错误无法解析时按花括号结构识别
+		return braceRecursion(tokens)
+	case "python":
+		return pythonRecursion(stripped, tokens)
+	default:
+		return braceRecursion(tokens)
+	}
+}
+
+// goRecursion 遍历每个函数声明的函数体，查找对自身的调用（方法为 接收者.方法名(...)）；
+// 代码无法解析时 ok 为 false
+func goRecursion(code string) (*recursiveCall, bool) {
+	fset := gotoken.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	if err != nil {
+		return nil, false
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		name := fn.Name.Name
+		recv := ""
+		if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+			recv = fn.Recv.List[0].Names[0].Name
+		}
+
+		var found *recursiveCall
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch f := call.Fun.(type) {
+			case *ast.Ident:
+				if fn.Recv == nil && f.Name == name {
+					found = &recursiveCall{function: name, line: fset.Position(call.Pos()).Line}
+				}
+			case *ast.SelectorExpr:
+				if x, ok := f.X.(*ast.Ident); ok && recv != "" && x.Name == recv && f.Sel.Name == name {
+					found = &recursiveCall{function: name, line: fset.Position(call.Pos()).Line}
+				}
+			}
+			return found == nil
+		})
+		if found != nil {
+			return found, true
+		}
+	}
+	return nil, true
+}
+
+// notFunctionNames 后接括号与花括号但不是函数定义的关键字
+var notFunctionNames = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"sizeof": true, "else": true, "do": true, "new": true, "synchronized": true, "try": true,
+	"func": true, "defer": true, "go": true,
+}
+
+// braceRecursion C/C++/Java 等：识别 名称(参数) [修饰] { 函数体 } 形式的函数定义，
+// 查找函数体中对同名函数的调用
+func braceRecursion(tokens []token) *recursiveCall {
+	for i := 0; i+1 < len(tokens); i++ {
+		name := tokens[i]
+		if name.kind != tokIdent || notFunctionNames[name.text] || tokens[i+1].text != "(" {
+			continue
+		}
+		if i > 0 && (tokens[i-1].text == "." || tokens[i-1].text == "new") {
+			continue
+		}
+		closeParen := matchClose(tokens, i+1, "(", ")")
+		if closeParen < 0 {
+			continue
+		}
+		open := functionBodyStart(tokens, closeParen+1)
+		if open < 0 {
+			continue
+		}
+		end := matchClose(tokens, open, "{", "}")
+		if end < 0 {
+			end = len(tokens)
+		}
+		for k := open + 1; k+1 < end; k++ {
+			t := tokens[k]
+			if t.kind != tokIdent || t.text != name.text || tokens[k+1].text != "(" {
+				continue
+			}
+			// obj.f(...) 调用的是其他对象的方法，this.f(...) / this->f(...) 仍是自身
+			if prev := tokens[k-1].text; (prev == "." || prev == "->") && tokens[k-2].text != "this" {
+				continue
+			}
+			return &recursiveCall{function: name.text, line: t.line}
+		}
+	}
+	return nil
+}
+
+// functionBodyStart 参数列表之后允许出现修饰符（const、noexcept、throws X、-> 返回类型等），
+// 随后为 { 时返回其位置，否则不是函数定义
+func functionBodyStart(tokens []token, from int) int {
+	for k := from; k < len(tokens) && k < from+16; k++ {
+		t := tokens[k]
+		switch {
+		case t.text == "{":
+			return k
+		case t.kind == tokIdent, t.text == "::", t.text == "->", t.text == "<", t.text == ">",
+			t.text == ",", t.text == "&", t.text == "*", t.text == ".":
+			continue
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// matchClose 返回与 tokens[open] 匹配的闭合符号位置
+func matchClose(tokens []token, open int, left, right string) int {
+	depth := 0
+	for k := open; k < len(tokens); k++ {
+		switch tokens[k].text {
+		case left:
+			depth++
+		case right:
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+	return -1
+}
+
+// pythonRecursion 按缩进确定 def 的函数体，查找对同名函数的调用（含 self.f(...)）
+func pythonRecursion(stripped string, tokens []token) *recursiveCall {
+	indents := lineIndents(stripped)
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].text != "def" || tokens[i+1].kind != tokIdent {
+			continue
+		}
+		name := tokens[i+1].text
+		defLine := tokens[i].line
+		defIndent := indents[defLine]
+		for k := i + 2; k+1 < len(tokens); k++ {
+			t := tokens[k]
+			if t.line > defLine && indents[t.line] <= defIndent {
+				break
+			}
+			if t.line == defLine || t.kind != tokIdent || t.text != name || tokens[k+1].text != "(" {
+				continue
+			}
+			if prev := tokens[k-1]; prev.text == "def" || prev.text == "." && tokens[k-2].text != "self" && tokens[k-2].text != "cls" {
+				continue
+			}
+			return &recursiveCall{function: name, line: t.line}
+		}
+	}
+	return nil
+}
+
+// lineIndents 每行（从 1 开始）的缩进宽度，制表符按 8 计
+func lineIndents(code string) map[int]int {
+	indents := make(map[int]int)
+	for i, line := range strings.Split(code, "\n") {
+		width := 0
+		for _, c := range line {
+			if c == ' ' {
+				width++
+			} else if c == '\t' {
+				width += 8
+			} else {
+				break
+			}
+		}
+		indents[i+1] = width
+	}
+	return indents
+}
+
+func (r *recursiveCall) String() string {
+	return fmt.Sprintf("函数 %s 调用了自身（递归）", r.function)
+}
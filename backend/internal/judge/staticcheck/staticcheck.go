@@ -0,0 +1,138 @@
+// Package staticcheck 静态规则检查：对"禁止 STL sort""禁止 #include <algorithm>""禁止递归"等
+// 机械性的要求，直接按代码文本与语法结构检查，无需调用 AI
+package staticcheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+)
+
+const (
+	maxRules      = 50
+	maxSnippetLen = 120
+)
+
+// Check 按规则检查代码，返回触发的规则（每条规则报告第一处）；不适用于该语言的规则跳过
+func Check(code, language string, rules []model.StaticRule) []model.RuleViolation {
+	if len(rules) == 0 {
+		return nil
+	}
+	tokens, stripped := scan(code, language)
+	lines := strings.Split(code, "\n")
+
+	var violations []model.RuleViolation
+	for i, rule := range rules {
+		if !appliesTo(rule, language) {
+			continue
+		}
+		line, detail := checkRule(rule, code, stripped, language, tokens)
+		if line == 0 {
+			continue
+		}
+		message := rule.Message
+		if message == "" {
+			message = detail
+		}
+		violations = append(violations, model.RuleViolation{
+			Rule:    i + 1,
+			Type:    rule.Type,
+			Pattern: rule.Pattern,
+			Message: message,
+			Line:    line,
+			Snippet: snippet(lines, line),
+		})
+	}
+	return violations
+}
+
+// checkRule 返回触发位置的行号（未触发为 0）与默认说明
+func checkRule(rule model.StaticRule, code, stripped, language string, tokens []token) (int, string) {
+	pattern := strings.TrimSpace(rule.Pattern)
+	switch rule.Type {
+	case model.StaticRuleRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return 0, ""
+		}
+		if loc := re.FindStringIndex(stripped); loc != nil {
+			return strings.Count(stripped[:loc[0]], "\n") + 1, fmt.Sprintf("代码匹配禁止的模式 %s", rule.Pattern)
+		}
+	case model.StaticRuleToken:
+		if k := matchTokens(tokens, codeTokens(pattern, language)); k >= 0 {
+			return tokens[k].line, fmt.Sprintf("使用了禁止的 %s", pattern)
+		}
+	case model.StaticRuleInclude:
+		for _, ref := range collectImports(code, stripped, language) {
+			if importMatches(ref.path, pattern) {
+				return ref.line, fmt.Sprintf("引入了禁止的 %s", ref.path)
+			}
+		}
+	case model.StaticRuleRecursion:
+		if call := findRecursion(code, stripped, language, tokens); call != nil {
+			return call.line, call.String()
+		}
+	}
+	return 0, ""
+}
+
+func appliesTo(rule model.StaticRule, language string) bool {
+	if len(rule.Languages) == 0 {
+		return true
+	}
+	for _, lang := range rule.Languages {
+		if strings.EqualFold(strings.TrimSpace(lang), language) {
+			return true
+		}
+	}
+	return false
+}
+
+func snippet(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	text := strings.TrimSpace(lines[line-1])
+	if len(text) > maxSnippetLen {
+		cut := maxSnippetLen
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
+
+// Validate 检查规则配置：类型有效、需要的模式已填写、正则可编译
+func Validate(rules []model.StaticRule) error {
+	if len(rules) > maxRules {
+		return fmt.Errorf("静态检查规则最多 %d 条", maxRules)
+	}
+	for i, rule := range rules {
+		pattern := strings.TrimSpace(rule.Pattern)
+		switch rule.Type {
+		case model.StaticRuleRegex:
+			if pattern == "" {
+				return fmt.Errorf("第 %d 条规则缺少正则表达式", i+1)
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("第 %d 条规则的正则表达式无效: %v", i+1, err)
+			}
+		case model.StaticRuleToken:
+			if len(codeTokens(pattern, "")) == 0 {
+				return fmt.Errorf("第 %d 条规则缺少标记", i+1)
+			}
+		case model.StaticRuleInclude:
+			if pattern == "" {
+				return fmt.Errorf("第 %d 条规则缺少头文件/包名", i+1)
+			}
+		case model.StaticRuleRecursion:
+		default:
+			return fmt.Errorf("第 %d 条规则的类型无效: %s", i+1, rule.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package judge
+
+import (
+	"errors"
+	"log"
+)
+
+const taskQueueSize = 100
+
+var errTaskQueueFull = errors.New("后台任务较多，请稍后重试")
+
+// backgroundTask 后台任务：题目校验、生成测试数据、AI 测试数据建议、代码查重、题面翻译与 hack。
+// 这些任务耗时较长且可由用户反复触发，统一排队由固定数量的 worker 执行，不为每个请求单独起协程
+type backgroundTask struct {
+	name  string               // 任务描述，用于日志
+	run   func()               // 执行任务，结果由任务自行保存
+	abort func(message string) // 未能入队时将任务记录标记为失败，避免一直停留在进行中
+}
+
+// taskQueue 后台任务队列，判题服务启动时创建
+var taskQueue chan backgroundTask
+
+// startTaskWorkers 启动后台任务 worker
+func startTaskWorkers(workers int) {
+	taskQueue = make(chan backgroundTask, taskQueueSize)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range taskQueue {
+				task.run()
+			}
+		}()
+	}
+	log.Printf("[Judger] 启动后台任务队列，workers=%d", workers)
+}
+
+// enqueueTask 将任务加入后台任务队列；队列已满时不等待，直接将任务记录标记为失败并返回错误
+func enqueueTask(task backgroundTask) error {
+	err := errTaskQueueFull
+	if taskQueue == nil {
+		err = errors.New("判题服务未启动")
+	} else {
+		select {
+		case taskQueue <- task:
+			return nil
+		default:
+		}
+	}
+	log.Printf("[Judger] 后台任务入队失败: %s, %v", task.name, err)
+	task.abort("加入后台任务队列失败: " + err.Error())
+	return err
+}
@@ -0,0 +1,57 @@
+package judge
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// EnqueueTestcaseGeneration 将测试数据生成任务加入后台任务队列
+func EnqueueTestcaseGeneration(generationID uint) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("generation_id=%d", generationID),
+		run:  func() { runTestcaseGeneration(generationID) },
+		abort: func(message string) {
+			repo := repository.NewProblemProgramRepository()
+			generation, err := repo.GetGeneration(generationID)
+			if err != nil {
+				return
+			}
+			now := time.Now()
+			generation.Status = model.GenerationStatusError
+			generation.Message = message
+			generation.FinishedAt = &now
+			if err := repo.SaveGeneration(generation); err != nil {
+				log.Printf("[Generator] 保存生成结果失败: %v", err)
+			}
+		},
+	})
+}
+
+// runTestcaseGeneration 执行测试数据生成任务：运行数据生成器与标准程序，全部成功后替换题目的普通测试点
+func runTestcaseGeneration(generationID uint) {
+	programService := service.NewProblemProgramService()
+	generation, err := programService.GetGeneration(generationID)
+	if err != nil {
+		log.Printf("[Generator] 生成任务不存在: generation_id=%d", generationID)
+		return
+	}
+
+	log.Printf("[Generator] 开始生成测试数据: generation_id=%d, problem_id=%d, count=%d", generation.ID, generation.ProblemID, len(generation.Args))
+	if err := programService.RunGeneration(generation); err != nil {
+		log.Printf("[Generator] 保存生成结果失败: %v", err)
+		return
+	}
+	log.Printf("[Generator] 生成结束: generation_id=%d, status=%s, %s", generation.ID, generation.Status, generation.Message)
+}
+
+// recoverInterruptedGenerations 服务重启后，将中断的生成任务标记为异常
+func recoverInterruptedGenerations() {
+	if err := service.NewProblemProgramService().RecoverInterrupted(); err != nil {
+		log.Printf("[Generator] 恢复中断的生成任务失败: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package judge
+
+import (
+	"fmt"
+	"log"
+
+	"oj-system/internal/judge/ai"
+	"oj-system/internal/repository"
+	"oj-system/internal/service"
+)
+
+// EnqueueTestcaseProposal 将 AI 测试数据建议任务加入后台任务队列
+func EnqueueTestcaseProposal(batchID uint) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("batch_id=%d", batchID),
+		run:  func() { runTestcaseProposal(batchID) },
+		abort: func(message string) {
+			proposalService := service.NewTestcaseProposalService()
+			batch, err := proposalService.GetBatch(batchID)
+			if err != nil {
+				return
+			}
+			if err := proposalService.Fail(batch, message); err != nil {
+				log.Printf("[Proposal] 保存建议任务失败: %v", err)
+			}
+		},
+	})
+}
+
+// runTestcaseProposal 执行 AI 测试数据建议：请模型建议边界输入与数据生成器，
+// 再用输入校验器检查、标准程序生成输出，等待出题人采纳
+func runTestcaseProposal(batchID uint) {
+	proposalService := service.NewTestcaseProposalService()
+	batch, err := proposalService.GetBatch(batchID)
+	if err != nil {
+		log.Printf("[Proposal] 建议任务不存在: batch_id=%d", batchID)
+		return
+	}
+	finishWithError := func(message string) {
+		if err := proposalService.Fail(batch, message); err != nil {
+			log.Printf("[Proposal] 保存建议任务失败: %v", err)
+		}
+	}
+
+	problem, err := repository.NewProblemRepository().GetByID(batch.ProblemID)
+	if err != nil {
+		finishWithError("题目不存在")
+		return
+	}
+
+	log.Printf("[Proposal] 开始建议测试数据: batch_id=%d, problem_id=%d, count=%d", batch.ID, problem.ID, batch.Count)
+	proposals, generator, modelName, err := ai.NewClient().ProposeTestcases(problem, batch.Count, batch.Focus)
+	batch.Model = modelName
+	if err != nil {
+		finishWithError(err.Error())
+		return
+	}
+	if err := proposalService.Complete(batch, proposals, generator); err != nil {
+		log.Printf("[Proposal] 保存建议结果失败: %v", err)
+		return
+	}
+	log.Printf("[Proposal] 建议完成: batch_id=%d, %s", batch.ID, batch.Message)
+}
+
+// recoverInterruptedProposals 服务重启后，将中断的建议任务标记为异常
+func recoverInterruptedProposals() {
+	if err := service.NewTestcaseProposalService().RecoverInterrupted(); err != nil {
+		log.Printf("[Proposal] 恢复中断的建议任务失败: %v", err)
+	}
+}
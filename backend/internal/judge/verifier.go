@@ -0,0 +1,144 @@
+package judge
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"oj-system/internal/judge/sandbox"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+// 预期 AC 的解法最大耗时超过时限的该比例时给出警告（时限可能过紧）
+const verifyTightTimeRatio = 0.5
+
+// EnqueueProblemVerification 将题目校验任务加入后台任务队列
+func EnqueueProblemVerification(verificationID uint) error {
+	return enqueueTask(backgroundTask{
+		name: fmt.Sprintf("verification_id=%d", verificationID),
+		run:  func() { runProblemVerification(verificationID) },
+		abort: func(message string) {
+			repo := repository.NewProblemVerificationRepository()
+			verification, err := repo.GetByID(verificationID)
+			if err != nil {
+				return
+			}
+			now := time.Now()
+			verification.Status = model.VerificationStatusError
+			verification.Message = message
+			verification.FinishedAt = &now
+			if err := repo.Save(verification); err != nil {
+				log.Printf("[Verifier] 保存校验结果失败: %v", err)
+			}
+		},
+	})
+}
+
+// runProblemVerification 执行题目校验：用当前测试数据逐个评测标注解法，并比对预期结果
+func runProblemVerification(verificationID uint) {
+	repo := repository.NewProblemVerificationRepository()
+	problemRepo := repository.NewProblemRepository()
+
+	verification, err := repo.GetByID(verificationID)
+	if err != nil {
+		log.Printf("[Verifier] 校验任务不存在: verification_id=%d", verificationID)
+		return
+	}
+
+	finish := func(status, message string, results model.SolutionVerifyResultList) {
+		now := time.Now()
+		verification.Status = status
+		verification.Passed = status == model.VerificationStatusPassed
+		verification.Message = message
+		verification.Results = results
+		verification.FinishedAt = &now
+		if err := repo.Save(verification); err != nil {
+			log.Printf("[Verifier] 保存校验结果失败: %v", err)
+		}
+	}
+
+	problem, err := problemRepo.GetByID(verification.ProblemID)
+	if err != nil {
+		finish(model.VerificationStatusError, "题目不存在", nil)
+		return
+	}
+	testcases, err := problemRepo.GetTestcases(problem.ID)
+	if err != nil || len(testcases) == 0 {
+		finish(model.VerificationStatusError, "题目暂无测试数据", nil)
+		return
+	}
+	solutions, err := repo.ListSolutions(problem.ID)
+	if err != nil || len(solutions) == 0 {
+		finish(model.VerificationStatusError, "题目暂无标注解法", nil)
+		return
+	}
+
+	log.Printf("[Verifier] 开始校验: verification_id=%d, problem_id=%d, solutions=%d", verification.ID, problem.ID, len(solutions))
+
+	sb := sandbox.NewSimpleSandbox()
+	results := make(model.SolutionVerifyResultList, 0, len(solutions))
+	mismatched := 0
+	for _, solution := range solutions {
+		result := verifySolution(sb, verification.ID, problem, testcases, solution)
+		if !result.Matched {
+			mismatched++
+		}
+		results = append(results, result)
+	}
+
+	if mismatched == 0 {
+		finish(model.VerificationStatusPassed, fmt.Sprintf("全部 %d 个解法均符合预期", len(results)), results)
+	} else {
+		finish(model.VerificationStatusFailed, fmt.Sprintf("%d/%d 个解法与预期结果不符", mismatched, len(results)), results)
+	}
+
+	log.Printf("[Verifier] 校验完成: verification_id=%d, status=%s", verification.ID, verification.Status)
+}
+
+// verifySolution 评测单个标注解法
+func verifySolution(sb sandbox.Sandbox, verificationID uint, problem *model.Problem, testcases []model.Testcase, solution model.ProblemSolution) model.SolutionVerifyResult {
+	workDir := sandbox.GetTaskWorkDir(fmt.Sprintf("verify-%d-%d", verificationID, solution.ID))
+	defer sandbox.CleanWorkDir(workDir)
+
+	testcaseResults, compileError := runProgramOnTestcases(sb, workDir, 0, solution.Language, solution.Code, problem, testcases)
+	actualStatus := calculateTraditionalStatus(testcaseResults)
+
+	result := model.SolutionVerifyResult{
+		SolutionID:      solution.ID,
+		Name:            solution.Name,
+		Language:        solution.Language,
+		ExpectedStatus:  solution.ExpectedStatus,
+		ActualStatus:    actualStatus,
+		Matched:         actualStatus == solution.ExpectedStatus,
+		CompileError:    compileError,
+		TestcaseResults: testcaseResults,
+	}
+	for _, r := range testcaseResults {
+		if r.Time > result.TimeUsed {
+			result.TimeUsed = r.Time
+		}
+		if r.Memory > result.MemoryUsed {
+			result.MemoryUsed = r.Memory
+		}
+	}
+
+	if solution.ExpectedStatus == model.StatusAccepted && actualStatus == model.StatusAccepted && problem.TimeLimit > 0 &&
+		float64(result.TimeUsed) > float64(problem.TimeLimit)*verifyTightTimeRatio {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("最大耗时 %dms 超过时限 %dms 的 %.0f%%，时限可能过紧", result.TimeUsed, problem.TimeLimit, verifyTightTimeRatio*100))
+	}
+	if solution.ExpectedStatus != model.StatusAccepted && actualStatus != solution.ExpectedStatus && actualStatus == model.StatusAccepted {
+		result.Warnings = append(result.Warnings, "预期不通过的解法通过了全部测试点，测试数据可能过弱")
+	}
+
+	return result
+}
+
+// recoverInterruptedVerifications 服务重启后，将中断的校验任务标记为异常
+func recoverInterruptedVerifications() {
+	repo := repository.NewProblemVerificationRepository()
+	if err := repo.MarkRunningAsError("服务重启，校验任务已中断"); err != nil {
+		log.Printf("[Verifier] 恢复中断的校验任务失败: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// AICache AI 分析结果缓存。
+// CacheKey 由代码、语言、题目 AI 判题配置、服务商、模型名称与提示词模板版本计算得到，任一变化都不会命中旧结果
+type AICache struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CacheKey  string         `json:"cache_key" gorm:"uniqueIndex;size:64;not null"`
+	ProblemID uint           `json:"problem_id" gorm:"index;not null"`
+	Provider  string         `json:"provider" gorm:"size:50"`
+	Model     string         `json:"model" gorm:"size:100"`
+	Result    *AIJudgeResult `json:"result" gorm:"type:text"`
+	HitCount  int            `json:"hit_count" gorm:"default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// AICallLog AI 调用审计记录：每次请求模型（含重试）记录一条，保存模型实际看到与返回的完整内容
+type AICallLog struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	SubmissionID     uint      `json:"submission_id" gorm:"index"`
+	ProblemID        uint      `json:"problem_id" gorm:"index"`
+	Provider         string    `json:"provider" gorm:"size:50"`
+	Model            string    `json:"model" gorm:"size:100"`
+	PromptTemplate   string    `json:"prompt_template" gorm:"size:150"`
+	Attempt          int       `json:"attempt"`                                 // 第几次调用（含重试）
+	Messages         string    `json:"messages,omitempty" gorm:"type:text"`     // 发送的完整消息（JSON 数组）
+	Response         string    `json:"response,omitempty" gorm:"type:text"`     // 模型输出的文本
+	RawResponse      string    `json:"raw_response,omitempty" gorm:"type:text"` // 原始响应体
+	Error            string    `json:"error,omitempty" gorm:"type:text"`
+	LatencyMS        int       `json:"latency_ms"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// 编译错误解释状态常量
+const (
+	CompileExplainStatusGenerating = "generating" // 排队/生成中
+	CompileExplainStatusDone       = "done"       // 已生成
+	CompileExplainStatusFailed     = "failed"     // 生成失败，不再重试
+)
+
+// CompileExplanation AI 对编译错误的通俗解释，状态见 CompileExplainStatus* 常量
+type CompileExplanation struct {
+	Content   string    `json:"content,omitempty"` // 解释内容
+	Line      int       `json:"line,omitempty"`    // 出错的代码行，0 为未能确定
+	Model     string    `json:"model,omitempty"`
+	Cached    bool      `json:"cached,omitempty"` // 是否复用了相同编译错误的解释
+	Error     string    `json:"error,omitempty"`  // 生成失败原因
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e *CompileExplanation) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *CompileExplanation) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, e)
+}
+
+// AICompileExplainCache 编译错误解释缓存。
+// CacheKey 由语言、编译错误文本、服务商与模型计算得到，相同的编译错误只调用一次模型
+type AICompileExplainCache struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CacheKey  string    `json:"cache_key" gorm:"uniqueIndex;size:64;not null"`
+	Language  string    `json:"language" gorm:"size:20"`
+	Provider  string    `json:"provider" gorm:"size:50"`
+	Model     string    `json:"model" gorm:"size:100"`
+	Content   string    `json:"content" gorm:"type:text"`
+	Line      int       `json:"line"`
+	HitCount  int       `json:"hit_count" gorm:"default:0"`
+	CreatedAt time.Time `json:"created_at"`
+}
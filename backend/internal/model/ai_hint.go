@@ -0,0 +1,47 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// AI 学习提示状态常量
+const (
+	AIHintStatusGenerating = "generating" // 排队/生成中
+	AIHintStatusDone       = "done"       // 已生成
+	AIHintStatusFailed     = "failed"     // 生成失败，不再重试
+)
+
+// AIHint 非 AC 提交的 AI 学习提示：只指出思路上的问题，不给出解法与代码
+type AIHint struct {
+	Category  string    `json:"category"`          // 失败类型：Wrong Answer / Time Limit Exceeded 等
+	Passed    int       `json:"passed"`            // 通过的测试点数
+	Total     int       `json:"total"`             // 测试点总数
+	Content   string    `json:"content,omitempty"` // 提示内容
+	Model     string    `json:"model,omitempty"`
+	Error     string    `json:"error,omitempty"` // 生成失败原因
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *AIHint) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return json.Marshal(h)
+}
+
+func (h *AIHint) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, h)
+}
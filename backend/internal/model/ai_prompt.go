@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// AIPromptTemplate AI 判题提示词模板（Go text/template），每次修改生成新版本
+type AIPromptTemplate struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description  string    `json:"description" gorm:"size:255"`
+	SystemPrompt string    `json:"system_prompt" gorm:"type:text"`
+	UserPrompt   string    `json:"user_prompt" gorm:"type:text;not null"`
+	Version      int       `json:"version" gorm:"default:1"` // 当前版本号
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AIPromptTemplateVersion 提示词模板的历史版本
+type AIPromptTemplateVersion struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TemplateID   uint      `json:"template_id" gorm:"uniqueIndex:idx_prompt_template_version;not null"`
+	Version      int       `json:"version" gorm:"uniqueIndex:idx_prompt_template_version;not null"`
+	SystemPrompt string    `json:"system_prompt" gorm:"type:text"`
+	UserPrompt   string    `json:"user_prompt" gorm:"type:text"`
+	Comment      string    `json:"comment" gorm:"size:255"` // 修改说明
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SaveAIPromptTemplateRequest 创建/修改提示词模板请求
+type SaveAIPromptTemplateRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	Description  string `json:"description" binding:"max=255"`
+	SystemPrompt string `json:"system_prompt"`
+	UserPrompt   string `json:"user_prompt" binding:"required"`
+	Comment      string `json:"comment" binding:"max=255"`
+}
+
+// RenderAIPromptRequest 提示词试渲染请求：按提交渲染提示词，不调用模型。
+// 未指定模板时使用该题实际生效的模板；填写 user_prompt 时渲染未保存的草稿
+type RenderAIPromptRequest struct {
+	SubmissionID uint   `json:"submission_id" binding:"required"`
+	TemplateID   uint   `json:"template_id"`
+	SystemPrompt string `json:"system_prompt"`
+	UserPrompt   string `json:"user_prompt"`
+}
+
+// AIPromptPreview 渲染后的提示词
+type AIPromptPreview struct {
+	TemplateID   uint   `json:"template_id"` // 0 表示内置模板
+	TemplateName string `json:"template_name"`
+	Version      int    `json:"version"`
+	SystemPrompt string `json:"system_prompt"`
+	UserPrompt   string `json:"user_prompt"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// AIUsageDaily AI 用量按天汇总：每天、每道题目、每场比赛一行，调用记录被清理后仍保留
+type AIUsageDaily struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	Date             string    `json:"date" gorm:"size:10;not null;uniqueIndex:idx_ai_usage_key"` // 2006-01-02
+	ProblemID        uint      `json:"problem_id" gorm:"not null;uniqueIndex:idx_ai_usage_key"`
+	ContestID        uint      `json:"contest_id" gorm:"not null;default:0;uniqueIndex:idx_ai_usage_key"` // 提交时所在的比赛，0 为非比赛提交
+	Calls            int64     `json:"calls"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// AIUsageTotals 用量合计
+type AIUsageTotals struct {
+	Calls            int64 `json:"calls"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// AIUsageDayItem 按天汇总
+type AIUsageDayItem struct {
+	Date string `json:"date"`
+	AIUsageTotals
+}
+
+// AIUsageProblemItem 按题目汇总
+type AIUsageProblemItem struct {
+	ProblemID    uint   `json:"problem_id"`
+	ProblemTitle string `json:"problem_title"`
+	AIUsageTotals
+}
+
+// AIUsageContestItem 按比赛汇总
+type AIUsageContestItem struct {
+	ContestID    uint   `json:"contest_id"`
+	ContestTitle string `json:"contest_title"`
+	AIUsageTotals
+}
+
+// AIBudgetStatus 本月预算使用情况
+type AIBudgetStatus struct {
+	Month    string `json:"month"`  // 2006-01
+	Budget   int64  `json:"budget"` // 0 为不限
+	Used     int64  `json:"used"`
+	Exceeded bool   `json:"exceeded"`
+	Policy   string `json:"policy"` // 预算用完后的处理策略
+}
+
+// AIUsageStats AI 用量统计
+type AIUsageStats struct {
+	From     string               `json:"from"`
+	To       string               `json:"to"`
+	Totals   AIUsageTotals        `json:"totals"`
+	Daily    []AIUsageDayItem     `json:"daily"`
+	Problems []AIUsageProblemItem `json:"problems"`
+	Contests []AIUsageContestItem `json:"contests"`
+	Budget   AIBudgetStatus       `json:"budget"`
+}
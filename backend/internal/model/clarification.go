@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// 答疑回复的可见范围
+const (
+	ClarificationVisibilityPrivate = "private" // 仅提问者可见
+	ClarificationVisibilityPublic  = "public"  // 广播给全体参赛者
+)
+
+// ContestClarification 比赛答疑：选手针对比赛题目的提问与管理员的回复
+type ContestClarification struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ContestID    uint       `json:"contest_id" gorm:"index;not null"`
+	ProblemID    uint       `json:"problem_id"` // 0 表示针对整场比赛
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	Question     string     `json:"question" gorm:"type:text;not null"`
+	Answer       string     `json:"answer" gorm:"type:text"`
+	Visibility   string     `json:"visibility" gorm:"size:20;default:private"` // private | public
+	AnsweredBy   uint       `json:"answered_by"`
+	AnsweredAt   *time.Time `json:"answered_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Username     string     `json:"username,omitempty" gorm:"-"`
+	AnswererName string     `json:"answerer_name,omitempty" gorm:"-"`
+	IsMine       bool       `json:"is_mine,omitempty" gorm:"-"`
+}
+
+// ContestAnnouncement 比赛公告：推送给全体参赛者
+type ContestAnnouncement struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ContestID uint      `json:"contest_id" gorm:"index;not null"`
+	Title     string    `json:"title" gorm:"size:200;not null"`
+	Content   string    `json:"content" gorm:"type:text"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClarificationCreateRequest 提问请求
+type ClarificationCreateRequest struct {
+	ProblemID uint   `json:"problem_id"` // 可选，为 0 时针对整场比赛
+	Question  string `json:"question" binding:"required"`
+}
+
+// ClarificationAnswerRequest 回复提问请求
+type ClarificationAnswerRequest struct {
+	Answer     string `json:"answer" binding:"required"`
+	Visibility string `json:"visibility"` // private | public，为空时为 private
+}
+
+// AnnouncementCreateRequest 发布比赛公告请求
+type AnnouncementCreateRequest struct {
+	Title   string `json:"title" binding:"required,max=200"`
+	Content string `json:"content"`
+}
@@ -8,50 +8,65 @@ import (
 
 // Contest 比赛模型
 type Contest struct {
-	ID              uint       `json:"id" gorm:"primaryKey"`
-	Title           string     `json:"title" gorm:"size:200;not null"`
-	Description     string     `json:"description" gorm:"type:text"`
-	Type            string     `json:"type" gorm:"size:10;not null"`             // oi | ioi
-	TimingMode      string     `json:"timing_mode" gorm:"size:20;default:fixed"` // fixed | window
-	DurationMinutes int        `json:"duration_minutes"`                         // 仅 timing_mode=window 时生效
-	SubmissionLimit int        `json:"submission_limit" gorm:"default:99"`       // 比赛提交总次数上限（固定 99）
-	StartAt         time.Time  `json:"start_at"`
-	EndAt           time.Time  `json:"end_at"`
-	ProblemIDs      UintList   `json:"problem_ids" gorm:"type:text"`
-	AllowedUsers    UintList   `json:"allowed_users" gorm:"type:text"`
-	AllowedGroups   StringList `json:"allowed_groups" gorm:"type:text"`
-	IsStatsSynced   bool       `json:"is_stats_synced" gorm:"default:false"`
-	CreatedBy       uint       `json:"created_by"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	Title                 string     `json:"title" gorm:"size:200;not null"`
+	Description           string     `json:"description" gorm:"type:text"`
+	Type                  string     `json:"type" gorm:"size:10;not null"`                         // oi | ioi | acm
+	TimingMode            string     `json:"timing_mode" gorm:"size:20;default:fixed"`             // fixed | window
+	DurationMinutes       int        `json:"duration_minutes"`                                     // 仅 timing_mode=window 时生效
+	SubmissionLimit       int        `json:"submission_limit" gorm:"default:99"`                   // 比赛提交总次数上限（固定 99）
+	HackEnabled           bool       `json:"hack_enabled" gorm:"default:false"`                    // 是否开启 hack（锁定后可 hack 他人）
+	FreezeMinutes         int        `json:"freeze_minutes" gorm:"default:0"`                      // 封榜时长：赛时最后 N 分钟的他人结果不在选手榜单上公布，0 不封榜
+	RevealedCells         StringList `json:"-" gorm:"type:text"`                                   // 封榜揭晓进度：已揭晓的 "用户ID:题目ID"
+	UnfrozenAt            *time.Time `json:"unfrozen_at"`                                          // 全部揭晓（解除封榜）的时间
+	LeaderboardVisibility string     `json:"leaderboard_visibility" gorm:"size:20;default:always"` // 选手榜单可见性：always | after_end | never | own_rank
+	AnonymizeLeaderboard  bool       `json:"anonymize_leaderboard" gorm:"default:false"`           // 选手榜单中隐藏他人的用户名与分组
+	AliasSalt             string     `json:"-" gorm:"size:64"`                                     // 匿名代号密钥：随机生成，选手代号由它与用户 ID 计算得出
+	StartAt               time.Time  `json:"start_at"`
+	EndAt                 time.Time  `json:"end_at"`
+	ProblemIDs            UintList   `json:"problem_ids" gorm:"type:text"`
+	AllowedUsers          UintList   `json:"allowed_users" gorm:"type:text"`
+	AllowedGroups         StringList `json:"allowed_groups" gorm:"type:text"`
+	IsStatsSynced         bool       `json:"is_stats_synced" gorm:"default:false"`
+	CreatedBy             uint       `json:"created_by"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
 }
 
 // ContestCreateRequest 创建比赛请求
 type ContestCreateRequest struct {
-	Title           string    `json:"title" binding:"required,max=200"`
-	Description     string    `json:"description"`
-	Type            string    `json:"type" binding:"required"`
-	TimingMode      string    `json:"timing_mode"`
-	DurationMinutes int       `json:"duration_minutes"`
-	StartAt         time.Time `json:"start_at" binding:"required"`
-	EndAt           time.Time `json:"end_at" binding:"required"`
-	ProblemIDs      []uint    `json:"problem_ids"`
-	AllowedUsers    []uint    `json:"allowed_users"`
-	AllowedGroups   []string  `json:"allowed_groups"`
+	Title                 string    `json:"title" binding:"required,max=200"`
+	Description           string    `json:"description"`
+	Type                  string    `json:"type" binding:"required"`
+	TimingMode            string    `json:"timing_mode"`
+	DurationMinutes       int       `json:"duration_minutes"`
+	HackEnabled           bool      `json:"hack_enabled"`
+	FreezeMinutes         int       `json:"freeze_minutes"`
+	LeaderboardVisibility string    `json:"leaderboard_visibility"` // 为空时为 always
+	AnonymizeLeaderboard  bool      `json:"anonymize_leaderboard"`
+	StartAt               time.Time `json:"start_at" binding:"required"`
+	EndAt                 time.Time `json:"end_at" binding:"required"`
+	ProblemIDs            []uint    `json:"problem_ids"`
+	AllowedUsers          []uint    `json:"allowed_users"`
+	AllowedGroups         []string  `json:"allowed_groups"`
 }
 
 // ContestUpdateRequest 更新比赛请求
 type ContestUpdateRequest struct {
-	Title           string    `json:"title" binding:"required,max=200"`
-	Description     string    `json:"description"`
-	Type            string    `json:"type" binding:"required"`
-	TimingMode      string    `json:"timing_mode"`
-	DurationMinutes int       `json:"duration_minutes"`
-	StartAt         time.Time `json:"start_at" binding:"required"`
-	EndAt           time.Time `json:"end_at" binding:"required"`
-	ProblemIDs      []uint    `json:"problem_ids"`
-	AllowedUsers    []uint    `json:"allowed_users"`
-	AllowedGroups   []string  `json:"allowed_groups"`
+	Title                 string    `json:"title" binding:"required,max=200"`
+	Description           string    `json:"description"`
+	Type                  string    `json:"type" binding:"required"`
+	TimingMode            string    `json:"timing_mode"`
+	DurationMinutes       int       `json:"duration_minutes"`
+	HackEnabled           bool      `json:"hack_enabled"`
+	FreezeMinutes         int       `json:"freeze_minutes"`
+	LeaderboardVisibility string    `json:"leaderboard_visibility"` // 为空时为 always
+	AnonymizeLeaderboard  bool      `json:"anonymize_leaderboard"`
+	StartAt               time.Time `json:"start_at" binding:"required"`
+	EndAt                 time.Time `json:"end_at" binding:"required"`
+	ProblemIDs            []uint    `json:"problem_ids"`
+	AllowedUsers          []uint    `json:"allowed_users"`
+	AllowedGroups         []string  `json:"allowed_groups"`
 }
 
 // ContestListItem 比赛列表项
@@ -62,6 +77,7 @@ type ContestListItem struct {
 	TimingMode      string    `json:"timing_mode"`
 	DurationMinutes int       `json:"duration_minutes"`
 	SubmissionLimit int       `json:"submission_limit"`
+	FreezeMinutes   int       `json:"freeze_minutes"`
 	StartAt         time.Time `json:"start_at"`
 	EndAt           time.Time `json:"end_at"`
 	ProblemCount    int       `json:"problem_count"`
@@ -80,6 +96,61 @@ type ContestLeaderboardEntry struct {
 	PostScores     []int      `json:"post_scores,omitempty"`
 	StartedAt      *time.Time `json:"started_at,omitempty"`
 	ElapsedSeconds int64      `json:"elapsed_seconds,omitempty"`
+	Frozen         []int      `json:"frozen,omitempty"`  // 封榜视图：各题尚未揭晓的提交数，顺序同 problem_ids
+	Rank           int        `json:"rank,omitempty"`    // 选手榜单中的名次
+	IsSelf         bool       `json:"is_self,omitempty"` // 选手榜单中查看者本人
+	// 以下仅 ACM 赛制填写
+	Solved     int              `json:"solved"`             // 赛时通过题数
+	Penalty    int              `json:"penalty"`            // 罚时（分钟）
+	PostSolved int              `json:"post_solved"`        // 计入赛后订正的通过题数
+	Problems   []ContestACMCell `json:"problems,omitempty"` // 各题的尝试次数与通过时间，顺序同 problem_ids
+}
+
+// ContestACMCell ACM 赛制排行榜中某一题的结果（只统计赛时提交）
+type ContestACMCell struct {
+	Accepted     bool `json:"accepted"`
+	Attempts     int  `json:"attempts"`                // 计入的提交次数：通过时含通过的那一次，编译错误与系统错误不计
+	Minutes      int  `json:"minutes"`                 // 首次通过时距开赛的分钟数，未通过为 0
+	Penalty      int  `json:"penalty"`                 // 本题罚时：通过分钟数 + 之前每次被拒 20 分钟，未通过为 0
+	FirstBlood   bool `json:"first_blood,omitempty"`   // 全场最早通过本题
+	Pending      int  `json:"pending,omitempty"`       // 尚未出结果的提交数
+	PostAccepted bool `json:"post_accepted,omitempty"` // 赛时未通过、赛后订正通过
+}
+
+// 选手榜单可见性
+const (
+	LeaderboardVisibilityAlways   = "always"    // 赛时与赛后均可查看
+	LeaderboardVisibilityAfterEnd = "after_end" // 比赛结束后可查看
+	LeaderboardVisibilityNever    = "never"     // 不向选手公开
+	LeaderboardVisibilityOwnRank  = "own_rank"  // 只能看到自己的名次
+)
+
+// ContestBoardView 按可见性设置处理后的选手榜单
+type ContestBoardView struct {
+	Visibility   string                    `json:"visibility"`
+	ScoresHidden bool                      `json:"scores_hidden"` // OI 赛制比赛结束前不公布分数与名次
+	Participants int                       `json:"participants"`  // 榜单上的总人数
+	MyRank       int                       `json:"my_rank"`       // 查看者的名次，不在榜单或不公布名次时为 0
+	Entries      []ContestLeaderboardEntry `json:"entries"`
+}
+
+// ContestFreezeState 比赛封榜状态
+type ContestFreezeState struct {
+	Enabled    bool       `json:"enabled"`             // 设置了封榜时长
+	Frozen     bool       `json:"frozen"`              // 榜单当前是否封榜（含揭晓过程中）
+	FreezeAt   *time.Time `json:"freeze_at,omitempty"` // 封榜时间；窗口期比赛按个人结束前 N 分钟，此处为空
+	UnfrozenAt *time.Time `json:"unfrozen_at,omitempty"`
+	Remaining  int        `json:"remaining"` // 尚未揭晓的格子数
+}
+
+// ContestRevealStep 揭晓一格（某用户某题）封榜期间的结果
+type ContestRevealStep struct {
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	ProblemID    uint   `json:"problem_id"`
+	ProblemIndex int    `json:"problem_index"`
+	RankBefore   int    `json:"rank_before"`
+	RankAfter    int    `json:"rank_after"`
 }
 
 type ContestSessionState struct {
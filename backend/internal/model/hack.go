@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Hack 状态
+const (
+	HackStatusPending = "pending" // 等待执行
+	HackStatusSuccess = "success" // hack 成功：目标提交未通过该输入
+	HackStatusFailed  = "failed"  // hack 失败：目标提交通过了该输入
+	HackStatusInvalid = "invalid" // 输入未通过校验器
+	HackStatusError   = "error"   // 标准程序异常等系统错误
+)
+
+// ContestLock 选手锁定比赛中某题的已通过代码；锁定后不能再提交该题，但可以 hack 他人
+type ContestLock struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ContestID    uint      `json:"contest_id" gorm:"not null;uniqueIndex:idx_contest_lock"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_contest_lock"`
+	ProblemID    uint      `json:"problem_id" gorm:"not null;uniqueIndex:idx_contest_lock"`
+	SubmissionID uint      `json:"submission_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Hack 一次 hack 尝试
+type Hack struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	ContestID          uint       `json:"contest_id" gorm:"index;not null"`
+	ProblemID          uint       `json:"problem_id" gorm:"not null"`
+	HackerID           uint       `json:"hacker_id" gorm:"index;not null"`
+	TargetSubmissionID uint       `json:"target_submission_id" gorm:"index;not null"`
+	TargetUserID       uint       `json:"target_user_id" gorm:"not null"`
+	Input              string     `json:"input,omitempty" gorm:"type:text"`
+	Status             string     `json:"status" gorm:"size:20;not null"`
+	TargetStatus       string     `json:"target_status" gorm:"size:30"` // 目标提交在该输入上的评测结果
+	Message            string     `json:"message" gorm:"type:text"`
+	TestcaseID         uint       `json:"testcase_id"` // hack 成功后加入的测试点
+	CreatedAt          time.Time  `json:"created_at"`
+	FinishedAt         *time.Time `json:"finished_at"`
+	HackerName         string     `json:"hacker_name" gorm:"-"`
+	TargetName         string     `json:"target_name" gorm:"-"`
+}
+
+// ContestLockRequest 锁定题目请求
+type ContestLockRequest struct {
+	ProblemID uint `json:"problem_id" binding:"required"`
+}
+
+// HackCreateRequest 发起 hack 请求
+type HackCreateRequest struct {
+	TargetSubmissionID uint   `json:"target_submission_id" binding:"required"`
+	Input              string `json:"input" binding:"required"`
+}
+
+// HackTarget 可 hack 的目标提交
+type HackTarget struct {
+	SubmissionID uint      `json:"submission_id"`
+	UserID       uint      `json:"user_id"`
+	Username     string    `json:"username"`
+	Language     string    `json:"language"`
+	Code         string    `json:"code"`
+	CreatedAt    time.Time `json:"created_at"`
+	Group        string    `json:"-" gorm:"column:user_group"`
+}
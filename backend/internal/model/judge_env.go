@@ -0,0 +1,47 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// JudgeEnv 评测环境指纹，随每次评测结果保存，便于升级工具链后区分新旧结果
+type JudgeEnv struct {
+	NodeID           string `json:"node_id,omitempty"` // 仅管理员查看提交时返回
+	Sandbox          string `json:"sandbox"`           // 沙箱后端（simple 等）
+	MemoryAccounting string `json:"memory_accounting"` // 内存统计方式：cgroup-v2 | proc-poll
+	Toolchain        string `json:"toolchain"`         // 提交语言对应的编译器/运行时版本
+}
+
+func (e *JudgeEnv) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *JudgeEnv) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, e)
+}
+
+// JudgeInfo 判题节点信息（启动时探测）
+type JudgeInfo struct {
+	NodeID           string            `json:"node_id,omitempty"` // 仅管理员接口返回
+	Sandbox          string            `json:"sandbox"`
+	MemoryAccounting string            `json:"memory_accounting"`
+	Workers          int               `json:"workers"`
+	Toolchains       map[string]string `json:"toolchains"` // 语言 -> 版本
+	ProbedAt         time.Time         `json:"probed_at"`
+}
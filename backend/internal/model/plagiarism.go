@@ -0,0 +1,152 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// 查重范围
+const (
+	PlagiarismScopeProblem = "problem" // 单个题目的全部提交
+	PlagiarismScopeContest = "contest" // 比赛期间比赛题目的提交
+)
+
+// 查重任务状态
+const (
+	PlagiarismStatusRunning = "running"
+	PlagiarismStatusDone    = "done"
+	PlagiarismStatusError   = "error"
+)
+
+// AI 复核结论
+const (
+	PlagiarismVerdictPlagiarism  = "plagiarism"  // 基本可以认定抄袭
+	PlagiarismVerdictSuspicious  = "suspicious"  // 可疑，需要人工确认
+	PlagiarismVerdictIndependent = "independent" // 相似来自题目本身（固定写法、简单题），倾向独立完成
+)
+
+// PlagiarismReport 代码查重报告：对范围内每个用户每题的最后一次提交两两比较相似度
+type PlagiarismReport struct {
+	ID              uint             `json:"id" gorm:"primaryKey"`
+	Scope           string           `json:"scope" gorm:"size:20;not null;index:idx_plagiarism_target"`
+	TargetID        uint             `json:"target_id" gorm:"not null;index:idx_plagiarism_target"` // 题目或比赛 ID
+	Title           string           `json:"title" gorm:"size:200"`                                 // 创建时的题目/比赛标题
+	Status          string           `json:"status" gorm:"size:20;not null"`
+	Message         string           `json:"message" gorm:"type:text"`
+	Threshold       float64          `json:"threshold"`             // 相似度达到该值的代码对才列入报告
+	AIReview        bool             `json:"ai_review"`             // 是否请 AI 复核相似度最高的代码对
+	AIReviewLimit   int              `json:"ai_review_limit"`       // AI 复核的代码对数
+	SubmissionCount int              `json:"submission_count"`      // 参与比较的提交数
+	PairCount       int              `json:"pair_count"`            // 列入报告的代码对数
+	Model           string           `json:"model" gorm:"size:100"` // AI 复核使用的模型
+	CreatedBy       uint             `json:"created_by"`
+	CreatedAt       time.Time        `json:"created_at"`
+	FinishedAt      *time.Time       `json:"finished_at"`
+	Pairs           []PlagiarismPair `json:"pairs,omitempty" gorm:"-"`
+}
+
+// PlagiarismPair 报告中的一对相似代码
+type PlagiarismPair struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	ReportID    uint                `json:"report_id" gorm:"index;not null"`
+	ProblemID   uint                `json:"problem_id" gorm:"not null"`
+	Language    string              `json:"language" gorm:"size:20"`
+	SubmissionA uint                `json:"submission_a" gorm:"not null"`
+	UserA       uint                `json:"user_a"`
+	UsernameA   string              `json:"username_a" gorm:"size:50"`
+	SubmissionB uint                `json:"submission_b" gorm:"not null"`
+	UserB       uint                `json:"user_b"`
+	UsernameB   string              `json:"username_b" gorm:"size:50"`
+	Similarity  float64             `json:"similarity"`                 // 0-1，按指纹计算的相似度
+	Matches     PlagiarismMatchList `json:"matches" gorm:"type:text"`   // 相同片段所在的行
+	AIReview    *PlagiarismAIReview `json:"ai_review" gorm:"type:text"` // AI 复核结果，未复核时为空
+}
+
+// PlagiarismMatch 两份代码中相同的片段（行号从 1 开始，包含首尾）
+type PlagiarismMatch struct {
+	AStart int `json:"a_start"`
+	AEnd   int `json:"a_end"`
+	BStart int `json:"b_start"`
+	BEnd   int `json:"b_end"`
+}
+
+type PlagiarismMatchList []PlagiarismMatch
+
+func (l PlagiarismMatchList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *PlagiarismMatchList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			*l = nil
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// PlagiarismAIReview AI 对一对相似代码的复核意见
+type PlagiarismAIReview struct {
+	Verdict    string    `json:"verdict"`    // plagiarism / suspicious / independent，失败时为空
+	Confidence float64   `json:"confidence"` // 0-1
+	Reason     string    `json:"reason"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (r *PlagiarismAIReview) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *PlagiarismAIReview) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// PlagiarismSource 参与查重的提交（每个用户每题的最后一次提交）
+type PlagiarismSource struct {
+	SubmissionID uint   `json:"submission_id"`
+	ProblemID    uint   `json:"problem_id"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Language     string `json:"language"`
+	Code         string `json:"code"`
+}
+
+// PlagiarismPairDetail 代码对详情，附两份代码用于并排对比
+type PlagiarismPairDetail struct {
+	PlagiarismPair
+	CodeA string `json:"code_a"`
+	CodeB string `json:"code_b"`
+}
+
+// PlagiarismCheckRequest 发起查重请求
+type PlagiarismCheckRequest struct {
+	Scope         string  `json:"scope" binding:"required,oneof=problem contest"`
+	TargetID      uint    `json:"target_id" binding:"required"`
+	Threshold     float64 `json:"threshold"`       // 0-1，默认 0.6
+	AIReview      bool    `json:"ai_review"`       // 是否请 AI 复核
+	AIReviewLimit int     `json:"ai_review_limit"` // 复核相似度最高的前 N 对，默认 5，最多 20
+}
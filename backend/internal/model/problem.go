@@ -20,6 +20,7 @@ type Problem struct {
 	Difficulty    string        `json:"difficulty" gorm:"size:20"`       // easy, medium, hard
 	Tags          StringList    `json:"tags" gorm:"type:text"`
 	AIJudgeConfig *AIJudgeConfig `json:"ai_judge_config" gorm:"type:text"`
+	AIHintEnabled bool          `json:"ai_hint_enabled" gorm:"default:false"` // 非 AC 提交生成 AI 学习提示（比赛进行中不生成）
 	FileIOEnabled bool          `json:"file_io_enabled" gorm:"default:false"`
 	FileInputName string        `json:"file_input_name" gorm:"size:100"`
 	FileOutputName string       `json:"file_output_name" gorm:"size:100"`
@@ -30,6 +31,9 @@ type Problem struct {
 	CreatedAt     time.Time     `json:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at"`
 	HasAccepted   bool          `json:"has_accepted" gorm:"-"`
+	Translations  ProblemTranslationMap `json:"translations,omitempty" gorm:"type:text"` // 其他语言的题面，键为语言代码（仅管理员可见）
+	Locale        string        `json:"locale,omitempty" gorm:"-"`                // 返回的题面语言
+	AvailableLocales []string   `json:"available_locales,omitempty" gorm:"-"`     // 可选的题面语言（原文与已审核的译文）
 }
 
 // Sample 样例
@@ -95,6 +99,36 @@ type AIJudgeConfig struct {
 	CustomPrompt       string   `json:"custom_prompt,omitempty"`
 	StrictMode         bool     `json:"strict_mode"`
 	MaxScoreIfNotMet   *int     `json:"max_score_if_not_met,omitempty"` // AI 未通过时最高得分，nil 时默认 50
+	PromptTemplateID   uint     `json:"prompt_template_id,omitempty"`   // 提示词模板，0 使用系统默认模板
+	Consensus          *AIConsensusConfig `json:"consensus,omitempty"`      // 多模型/多次采样投票，nil 时只调用一次
+	StaticRules        []StaticRule       `json:"static_rules,omitempty"`   // 静态检查规则，在调用 AI 之前检查
+	StaticOnly         bool               `json:"static_only,omitempty"`    // 仅使用静态规则判定，不调用 AI
+	ReviewThreshold    float64            `json:"review_threshold,omitempty"` // AI 判定置信度低于该值时转教师复核，0 不启用
+}
+
+// 静态检查规则类型
+const (
+	StaticRuleRegex     = "regex"     // 正则表达式，匹配去掉注释后的代码
+	StaticRuleToken     = "token"     // 词法标记序列，如 sort、std::sort、goto，不匹配注释与字符串中的内容
+	StaticRuleInclude   = "include"   // 头文件/包/模块，如 algorithm、java.util.Arrays、sort
+	StaticRuleRecursion = "recursion" // 禁止递归（函数直接调用自身）
+)
+
+// StaticRule 静态检查规则：机械性的禁止项无需 AI，直接按代码文本与语法结构检查
+type StaticRule struct {
+	Type      string   `json:"type"`
+	Pattern   string   `json:"pattern,omitempty"`   // recursion 规则无需填写
+	Languages []string `json:"languages,omitempty"` // 适用语言，为空时适用于所有语言
+	Message   string   `json:"message,omitempty"`   // 触发时的说明，为空时自动生成
+}
+
+// AIConsensusConfig 共识投票配置：多个模型或同一模型多次采样分别判断，
+// 只有足够多的投票（且置信度足够）认为未满足要求时才判定未满足
+type AIConsensusConfig struct {
+	Models        []string `json:"models,omitempty"`         // 参与投票的模型（同一服务商），为空时使用系统设置的模型
+	Samples       int      `json:"samples,omitempty"`        // 每个模型的采样次数，默认 1
+	FailRatio     float64  `json:"fail_ratio,omitempty"`     // 判定未满足所需的"未满足"票比例，0 表示超过半数
+	MinConfidence float64  `json:"min_confidence,omitempty"` // 置信度低于该值的"未满足"票不计入
 }
 
 // UnmarshalJSON 自定义反序列化，兼容旧数据中 required_language 为 string 的情况
@@ -168,6 +202,7 @@ type Testcase struct {
 	OutputFile string `json:"output_file" gorm:"size:255;not null"`
 	Score      int    `json:"score" gorm:"default:0"`
 	IsSample   bool   `json:"is_sample" gorm:"default:false"`
+	IsHack     bool   `json:"is_hack" gorm:"default:false"` // 比赛中 hack 成功后加入的测试点
 	OrderNum   int    `json:"order_num" gorm:"default:0"`
 }
 
@@ -184,6 +219,7 @@ type ProblemCreateRequest struct {
 	Difficulty    string         `json:"difficulty"`
 	Tags          []string       `json:"tags"`
 	AIJudgeConfig *AIJudgeConfig `json:"ai_judge_config"`
+	AIHintEnabled bool           `json:"ai_hint_enabled"`
 	FileIOEnabled bool           `json:"file_io_enabled"`
 	FileInputName string         `json:"file_input_name"`
 	FileOutputName string        `json:"file_output_name"`
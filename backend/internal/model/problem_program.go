@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// 题目配套程序角色
+const (
+	ProgramRoleGenerator = "generator" // 数据生成器：按参数生成 .in
+	ProgramRoleReference = "reference" // 标准程序：根据 .in 生成 .out
+	ProgramRoleValidator = "validator" // 输入校验器：从标准输入读取数据，合法时以 0 退出
+)
+
+// ProblemProgram 题目配套程序（数据生成器、标准程序、输入校验器），每个题目每种角色仅保留一份
+type ProblemProgram struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	ProblemID uint       `json:"problem_id" gorm:"not null;index;uniqueIndex:idx_problem_program_role"`
+	Role      string     `json:"role" gorm:"size:20;not null;uniqueIndex:idx_problem_program_role"`
+	Language  string     `json:"language" gorm:"size:20;not null"`
+	Code      string     `json:"code" gorm:"type:text;not null"`
+	Args      StringList `json:"args" gorm:"type:text"` // 仅 generator 使用：每一项为一次运行的命令行参数
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ProblemProgramSaveRequest 保存题目配套程序请求
+type ProblemProgramSaveRequest struct {
+	Language string   `json:"language" binding:"required"`
+	Code     string   `json:"code" binding:"required"`
+	Args     []string `json:"args"`
+}
+
+// 测试数据生成任务状态
+const (
+	GenerationStatusRunning = "running"
+	GenerationStatusSuccess = "success"
+	GenerationStatusError   = "error"
+)
+
+// TestcaseGeneration 测试数据生成任务：在后台运行生成器与标准程序，全部成功后替换题目的普通测试点
+type TestcaseGeneration struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ProblemID  uint       `json:"problem_id" gorm:"index;not null"`
+	Status     string     `json:"status" gorm:"size:20;not null"`
+	Args       StringList `json:"args" gorm:"type:text"`
+	Total      int        `json:"total"`       // 生成的测试点数量
+	TotalBytes int64      `json:"total_bytes"` // 生成的输入输出文件总大小
+	Message    string     `json:"message" gorm:"type:text"`
+	CreatedBy  uint       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// TestcaseGenerateRequest 服务端生成测试数据请求
+type TestcaseGenerateRequest struct {
+	Args []string `json:"args"` // 为空时使用生成器已保存的参数列表
+}
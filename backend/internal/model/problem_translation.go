@@ -0,0 +1,157 @@
+package model
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// ProblemSourceLocale 题目原文（Problem 上各字段）的语言
+const ProblemSourceLocale = "zh"
+
+// ProblemLocale 支持的题面语言
+type ProblemLocale struct {
+	Code string `json:"code"`
+	Name string `json:"name"` // 该语言的自称，同时用于翻译提示词
+}
+
+// ProblemLocales 支持翻译的题面语言（不含原文语言）
+var ProblemLocales = []ProblemLocale{
+	{Code: "en", Name: "English"},
+	{Code: "ja", Name: "日本語"},
+	{Code: "ko", Name: "한국어"},
+	{Code: "ru", Name: "Русский"},
+	{Code: "fr", Name: "Français"},
+	{Code: "es", Name: "Español"},
+}
+
+// FindProblemLocale 按语言代码查找支持的题面语言
+func FindProblemLocale(code string) (ProblemLocale, bool) {
+	for _, locale := range ProblemLocales {
+		if locale.Code == code {
+			return locale, true
+		}
+	}
+	return ProblemLocale{}, false
+}
+
+// 译文状态
+const (
+	TranslationStatusTranslating = "translating" // 机器翻译进行中
+	TranslationStatusDraft       = "draft"       // 草稿：机器翻译或未审核的人工修改，不对学生展示
+	TranslationStatusReviewed    = "reviewed"    // 已审核，按 Accept-Language 对学生展示
+	TranslationStatusError       = "error"       // 机器翻译失败
+)
+
+// ProblemTranslation 某一语言的题面译文
+type ProblemTranslation struct {
+	Status       string     `json:"status"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	InputFormat  string     `json:"input_format"`
+	OutputFormat string     `json:"output_format"`
+	Hint         string     `json:"hint"`
+	Error        string     `json:"error,omitempty"`       // 机器翻译失败原因
+	Model        string     `json:"model,omitempty"`       // 最近一次机器翻译使用的模型
+	SourceHash   string     `json:"source_hash,omitempty"` // 翻译所依据的原文摘要，原文修改后可据此提示重新翻译
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ReviewerID   uint       `json:"reviewer_id,omitempty"`
+	Reviewer     string     `json:"reviewer,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	// 已发布译文的待审核新版本（重新翻译或人工修改），审核通过前学生仍看到已发布的译文
+	Pending *ProblemTranslationDraft `json:"pending,omitempty"`
+}
+
+// ProblemTranslationDraft 已发布译文的待审核新版本，状态为 translating、draft 或 error
+type ProblemTranslationDraft struct {
+	Status       string    `json:"status"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	InputFormat  string    `json:"input_format"`
+	OutputFormat string    `json:"output_format"`
+	Hint         string    `json:"hint"`
+	Error        string    `json:"error,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	SourceHash   string    `json:"source_hash,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsTranslating 译文或其待审核版本是否正在机器翻译
+func (t *ProblemTranslation) IsTranslating() bool {
+	return t.Status == TranslationStatusTranslating ||
+		(t.Pending != nil && t.Pending.Status == TranslationStatusTranslating)
+}
+
+// IsPublished 译文已审核且依据的是当前原文；原文修改后需重新审核才对学生展示
+func (t *ProblemTranslation) IsPublished(sourceHash string) bool {
+	return t.Status == TranslationStatusReviewed && t.SourceHash == sourceHash
+}
+
+// ProblemTranslationMap 题面译文，键为语言代码（用于 GORM 序列化）
+type ProblemTranslationMap map[string]*ProblemTranslation
+
+func (m ProblemTranslationMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *ProblemTranslationMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			*m = nil
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// StatementHash 题面原文（标题、描述、输入输出格式、提示）的摘要
+func (p *Problem) StatementHash() string {
+	h := sha256.New()
+	for _, part := range []string{p.Title, p.Description, p.InputFormat, p.OutputFormat, p.Hint} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// ProblemTranslationItem 管理端展示的译文
+type ProblemTranslationItem struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+	*ProblemTranslation
+	Outdated bool `json:"outdated"` // 译文之后原文已修改
+}
+
+// ProblemTranslationOverview 题目的全部译文及支持的语言
+type ProblemTranslationOverview struct {
+	SourceLocale string                   `json:"source_locale"`
+	Locales      []ProblemLocale          `json:"locales"`
+	Translations []ProblemTranslationItem `json:"translations"`
+}
+
+// ProblemTranslateRequest 机器翻译请求
+type ProblemTranslateRequest struct {
+	Locales []string `json:"locales" binding:"required,min=1"`
+}
+
+// ProblemTranslationRequest 保存（人工修改）译文请求；reviewed 为 true 时标记为已审核
+type ProblemTranslationRequest struct {
+	Title        string `json:"title" binding:"required,max=200"`
+	Description  string `json:"description" binding:"required"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Hint         string `json:"hint"`
+	Reviewed     bool   `json:"reviewed"`
+}
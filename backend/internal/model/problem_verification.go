@@ -0,0 +1,88 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// 题目校验任务状态
+const (
+	VerificationStatusRunning = "running"
+	VerificationStatusPassed  = "passed"
+	VerificationStatusFailed  = "failed"
+	VerificationStatusError   = "error"
+)
+
+// ProblemSolution 题目校验用的标注解法（预期 AC / TLE / WA 等）
+type ProblemSolution struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ProblemID      uint      `json:"problem_id" gorm:"index;not null"`
+	Name           string    `json:"name" gorm:"size:100;not null"`
+	Language       string    `json:"language" gorm:"size:20;not null"`
+	Code           string    `json:"code" gorm:"type:text;not null"`
+	ExpectedStatus string    `json:"expected_status" gorm:"size:30;not null"`
+	CreatedBy      uint      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProblemSolutionRequest 创建/更新标注解法请求
+type ProblemSolutionRequest struct {
+	Name           string `json:"name" binding:"required,max=100"`
+	Language       string `json:"language" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	ExpectedStatus string `json:"expected_status" binding:"required"`
+}
+
+// ProblemVerification 题目校验任务：用当前测试数据评测全部标注解法
+type ProblemVerification struct {
+	ID         uint                     `json:"id" gorm:"primaryKey"`
+	ProblemID  uint                     `json:"problem_id" gorm:"index;not null"`
+	Status     string                   `json:"status" gorm:"size:20;not null"`
+	Passed     bool                     `json:"passed" gorm:"default:false"`
+	Message    string                   `json:"message" gorm:"type:text"`
+	Results    SolutionVerifyResultList `json:"results" gorm:"type:text"`
+	CreatedBy  uint                     `json:"created_by"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at"`
+}
+
+// SolutionVerifyResult 单个标注解法的校验结果
+type SolutionVerifyResult struct {
+	SolutionID      uint             `json:"solution_id"`
+	Name            string           `json:"name"`
+	Language        string           `json:"language"`
+	ExpectedStatus  string           `json:"expected_status"`
+	ActualStatus    string           `json:"actual_status"`
+	Matched         bool             `json:"matched"`
+	TimeUsed        int              `json:"time_used"`   // ms
+	MemoryUsed      int              `json:"memory_used"` // KB
+	CompileError    string           `json:"compile_error,omitempty"`
+	Warnings        []string         `json:"warnings,omitempty"`
+	TestcaseResults []TestcaseResult `json:"testcase_results"`
+}
+
+// SolutionVerifyResultList 校验结果列表（用于 GORM 序列化）
+type SolutionVerifyResultList []SolutionVerifyResult
+
+func (l SolutionVerifyResultList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *SolutionVerifyResultList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			*l = nil
+			return nil
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, l)
+}
@@ -20,25 +20,55 @@ const (
 	SettingAIAPIURL     = "ai_api_url"
 	SettingAIModel      = "ai_model"
 	SettingAITimeout    = "ai_timeout"
+	SettingAIMaxRetries = "ai_max_retries"
+	SettingAIFailurePolicy = "ai_failure_policy"
+	SettingAIPromptTemplateID = "ai_prompt_template_id"
+	SettingAILogRetentionDays = "ai_log_retention_days"
+	SettingAIMonthlyTokenBudget = "ai_monthly_token_budget"
+	SettingAIBudgetPolicy       = "ai_budget_policy"
+	SettingAICompileExplain     = "ai_compile_explain"
 	SettingJWTSecret    = "jwt_secret"
 )
 
+// AI 调用失败（重试后仍失败）时的处理策略
+const (
+	AIFailurePolicyPass    = "pass"    // 视为满足要求
+	AIFailurePolicyFail    = "fail"    // 视为未满足要求
+	AIFailurePolicyPending = "pending" // 标记为待分析，稍后自动重新分析
+)
+
 // AISettings AI 相关设置
 type AISettings struct {
-	Enabled  bool   `json:"enabled"`
-	Provider string `json:"provider"`
-	APIKey   string `json:"api_key"`
-	APIURL   string `json:"api_url"`
-	Model    string `json:"model"`
-	Timeout  int    `json:"timeout"`
+	Enabled       bool   `json:"enabled"`
+	Provider      string `json:"provider"`
+	APIKey        string `json:"api_key"`
+	APIURL        string `json:"api_url"`
+	Model         string `json:"model"`
+	Timeout       int    `json:"timeout"`
+	MaxRetries    int    `json:"max_retries"`
+	FailurePolicy string `json:"failure_policy"`
+	PromptTemplateID uint `json:"prompt_template_id"` // 默认提示词模板，0 使用内置模板
+	LogRetentionDays int  `json:"log_retention_days"` // AI 调用记录保留天数，0 为永久保留
+	MonthlyTokenBudget int64  `json:"monthly_token_budget"` // 每月 token 预算，0 为不限
+	BudgetPolicy       string `json:"budget_policy"`        // 预算用完后的处理策略，取值同 failure_policy
+	CompileExplain     bool   `json:"compile_explain"`      // 用 AI 解释编译错误（比赛赛时提交不解释）
+
+	Temperature float64 `json:"-"` // 单次调用的采样温度，0 使用默认值；由判题流程按需设置，不保存
 }
 
 // UpdateAISettingsRequest 更新 AI 设置请求
 type UpdateAISettingsRequest struct {
-	Enabled  bool   `json:"enabled"`
-	Provider string `json:"provider"`
-	APIKey   string `json:"api_key"`
-	APIURL   string `json:"api_url"`
-	Model    string `json:"model"`
-	Timeout  int    `json:"timeout"`
+	Enabled       bool   `json:"enabled"`
+	Provider      string `json:"provider"`
+	APIKey        string `json:"api_key"`
+	APIURL        string `json:"api_url"`
+	Model         string `json:"model"`
+	Timeout       int    `json:"timeout"`
+	MaxRetries    int    `json:"max_retries"`
+	FailurePolicy string `json:"failure_policy"`
+	PromptTemplateID uint `json:"prompt_template_id"`
+	LogRetentionDays int  `json:"log_retention_days"`
+	MonthlyTokenBudget int64  `json:"monthly_token_budget"`
+	BudgetPolicy       string `json:"budget_policy"`
+	CompileExplain     bool   `json:"compile_explain"`
 }
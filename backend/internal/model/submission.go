@@ -19,6 +19,15 @@ const (
 	StatusSystemError        = "System Error"
 )
 
+// AI 分析状态常量
+const (
+	AIStatusReviewing = "reviewing" // 测试点结果已发布，AI 分析排队/进行中
+	AIStatusDone      = "done"      // 已完成 AI 分析
+	AIStatusPending   = "pending"   // AI 调用失败，等待自动重新分析
+	// AIStatusManualReview AI 判定置信度低于题目阈值，等待教师复核
+	AIStatusManualReview = "manual_review"
+)
+
 // Submission 提交记录
 type Submission struct {
 	ID              uint              `json:"id" gorm:"primaryKey"`
@@ -34,6 +43,12 @@ type Submission struct {
 	AIJudgeResult   *AIJudgeResult    `json:"ai_judge_result" gorm:"type:text"`
 	CompileError    string            `json:"compile_error" gorm:"type:text"`
 	FinalMessage    string            `json:"final_message" gorm:"type:text"`
+	JudgeEnv        *JudgeEnv         `json:"judge_env" gorm:"type:text"` // 评测环境指纹
+	AIStatus        string            `json:"ai_status" gorm:"size:20;index"` // AI 分析状态：空（未启用）/reviewing/done/pending/manual_review
+	AIHintStatus    string            `json:"ai_hint_status" gorm:"size:20;index"` // AI 学习提示状态：空（不生成）/generating/done/failed
+	AIHint          *AIHint           `json:"ai_hint" gorm:"type:text"`
+	CompileExplainStatus string              `json:"compile_explain_status" gorm:"size:20;index"` // 编译错误解释状态：空（不解释）/generating/done/failed
+	CompileExplanation   *CompileExplanation `json:"compile_explanation" gorm:"type:text"`
 	CreatedAt       time.Time         `json:"created_at"`
 	ProblemTitle    string            `json:"problem_title" gorm:"-"`
 	Username        string            `json:"username" gorm:"-"`
@@ -50,6 +65,7 @@ type TestcaseResult struct {
 	Time     int    `json:"time"`   // ms
 	Memory   int    `json:"memory"` // KB
 	Message  string `json:"message,omitempty"`
+	Hack     bool   `json:"hack,omitempty"` // 比赛中 hack 成功后加入的测试点，不计入得分
 }
 
 // TestcaseResultList 测试点结果列表
@@ -85,6 +101,85 @@ type AIJudgeResult struct {
 	Reason            string            `json:"reason,omitempty"`
 	Summary           string            `json:"summary,omitempty"`
 	Details           *AIJudgeDetails   `json:"details,omitempty"`
+	Cached            bool              `json:"cached,omitempty"`         // 是否复用了缓存的分析结果
+	Error             string            `json:"error,omitempty"`          // AI 调用失败原因（重试后仍失败）
+	FailurePolicy     string            `json:"failure_policy,omitempty"` // 调用失败时采用的处理策略
+	Attempts          int               `json:"attempts,omitempty"`       // 本次分析的调用次数（含重试）
+	PromptTemplate    string            `json:"prompt_template,omitempty"` // 使用的提示词模板及版本
+	Votes             []AIJudgeVote     `json:"votes,omitempty"`           // 共识投票时每一票的结果
+	Consensus         string            `json:"consensus,omitempty"`       // 共识投票结论说明
+	Source            string            `json:"source,omitempty"`          // 结果来源：空为 AI 分析，static 为静态规则检查，skipped 为未进行分析
+	Violations        []RuleViolation   `json:"violations,omitempty"`      // 触发的静态检查规则
+	Confidence        float64           `json:"confidence,omitempty"`      // 模型对判定结论的置信度（0-1）
+	Review            *AIManualReview   `json:"review,omitempty"`          // 置信度不足时的教师复核记录
+}
+
+// 教师复核状态
+const (
+	AIReviewPending    = "pending"    // 等待复核
+	AIReviewConfirmed  = "confirmed"  // 教师确认了 AI 的判定
+	AIReviewOverridden = "overridden" // 教师改判
+)
+
+// AIManualReview 教师对低置信度 AI 判定的复核记录
+type AIManualReview struct {
+	Status     string     `json:"status"`
+	Threshold  float64    `json:"threshold"`             // 进入复核时题目配置的置信度阈值
+	AIPassed   bool       `json:"ai_passed"`             // AI 原本的判定
+	ReviewerID uint       `json:"reviewer_id,omitempty"`
+	Reviewer   string     `json:"reviewer,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// AIReviewRequest 教师复核请求：passed 为复核后认定的"是否满足题目要求"
+type AIReviewRequest struct {
+	Passed  *bool  `json:"passed" binding:"required"`
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// AIReviewQueueItem 人工复核队列中的提交
+type AIReviewQueueItem struct {
+	SubmissionListItem
+	AIJudgeResult *AIJudgeResult `json:"ai_judge_result"`
+}
+
+// 判题结果来源
+const (
+	AIJudgeSourceStatic  = "static"  // 结果由静态规则检查给出，未调用 AI
+	AIJudgeSourceSkipped = "skipped" // AI 判题未启用或未配置 API Key，未进行分析
+)
+
+// RuleViolation 触发的静态检查规则
+type RuleViolation struct {
+	Rule    int    `json:"rule"` // 规则序号（从 1 开始）
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Snippet string `json:"snippet,omitempty"` // 触发位置所在的代码行
+}
+
+// AIJudgeVote 共识投票中的一票
+type AIJudgeVote struct {
+	Model             string  `json:"model"`
+	Sample            int     `json:"sample"` // 同一模型的第几次采样
+	Passed            bool    `json:"passed"`
+	Confidence        float64 `json:"confidence"`
+	Counted           bool    `json:"counted"` // 是否计入"未满足"票（置信度不足的未满足票不计入）
+	AlgorithmDetected string  `json:"algorithm_detected,omitempty"`
+	Summary           string  `json:"summary,omitempty"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// Failed 是否为 AI 调用失败后按策略生成的结果
+func (a *AIJudgeResult) Failed() bool {
+	return a != nil && a.Error != ""
+}
+
+// AwaitingReview 是否正在等待教师复核（此时 AI 判定暂不影响状态与得分）
+func (a *AIJudgeResult) AwaitingReview() bool {
+	return a != nil && a.Review != nil && a.Review.Status == AIReviewPending
 }
 
 // AIJudgeDetails AI 判题详细信息
@@ -135,6 +230,7 @@ type SubmissionListItem struct {
 	TimeUsed   int       `json:"time_used"`
 	MemoryUsed int       `json:"memory_used"`
 	Score      int       `json:"score"`
+	AIStatus   string    `json:"ai_status"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -145,5 +241,6 @@ type ContestSubmission struct {
 	Group     string    `json:"group" gorm:"column:user_group"`
 	ProblemID uint      `json:"problem_id" gorm:"column:problem_id"`
 	Score     int       `json:"score" gorm:"column:score"`
+	Status    string    `json:"status" gorm:"column:status"`
 	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
 }
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// AI 测试数据建议任务状态
+const (
+	ProposalBatchRunning = "running"
+	ProposalBatchDone    = "done"
+	ProposalBatchError   = "error"
+)
+
+// AI 建议的测试数据状态
+const (
+	ProposalStatusValid    = "valid"    // 通过校验器（未配置校验器时不校验），已由标准程序生成输出
+	ProposalStatusInvalid  = "invalid"  // 未通过输入校验器
+	ProposalStatusError    = "error"    // 标准程序运行失败
+	ProposalStatusAccepted = "accepted" // 已加入题目测试点
+	ProposalStatusRejected = "rejected" // 已被出题人忽略
+)
+
+// TestcaseProposalBatch 一次 AI 测试数据建议：按题面与数据范围建议若干边界输入，并给出一份数据生成器
+type TestcaseProposalBatch struct {
+	ID                uint               `json:"id" gorm:"primaryKey"`
+	ProblemID         uint               `json:"problem_id" gorm:"index;not null"`
+	Status            string             `json:"status" gorm:"size:20;not null"`
+	Message           string             `json:"message" gorm:"type:text"`
+	Count             int                `json:"count"`                  // 要求建议的测试数据组数
+	Focus             string             `json:"focus" gorm:"type:text"` // 出题人补充的关注点
+	Model             string             `json:"model" gorm:"size:100"`  // 使用的模型
+	GeneratorLanguage string             `json:"generator_language" gorm:"size:20"`
+	GeneratorCode     string             `json:"generator_code" gorm:"type:text"`
+	GeneratorArgs     StringList         `json:"generator_args" gorm:"type:text"`
+	CreatedBy         uint               `json:"created_by"`
+	CreatedAt         time.Time          `json:"created_at"`
+	FinishedAt        *time.Time         `json:"finished_at"`
+	Proposals         []TestcaseProposal `json:"proposals" gorm:"-"`
+}
+
+// TestcaseProposal AI 建议的一组测试数据
+type TestcaseProposal struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	BatchID     uint      `json:"batch_id" gorm:"index;not null"`
+	ProblemID   uint      `json:"problem_id" gorm:"index;not null"`
+	Description string    `json:"description" gorm:"type:text"` // 这组数据针对的边界情况
+	Input       string    `json:"input" gorm:"type:text"`
+	Output      string    `json:"output" gorm:"type:text"` // 标准程序的输出
+	Status      string    `json:"status" gorm:"size:20;not null"`
+	Message     string    `json:"message" gorm:"type:text"` // 校验器或标准程序的错误信息
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TestcaseProposeRequest 请求 AI 建议测试数据
+type TestcaseProposeRequest struct {
+	Count int    `json:"count"` // 1-20，默认 10
+	Focus string `json:"focus"` // 补充说明，例如"重点考虑 n=1 与全部相同的情况"
+}
+
+// TestcaseProposalReviewRequest 采纳/忽略 AI 建议的测试数据
+type TestcaseProposalReviewRequest struct {
+	IDs   []uint `json:"ids" binding:"required"`
+	Score int    `json:"score"` // 采纳时每个测试点的分值，默认 10
+}
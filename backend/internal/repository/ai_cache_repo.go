@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AICacheRepository struct {
+	db *gorm.DB
+}
+
+func NewAICacheRepository() *AICacheRepository {
+	return &AICacheRepository{db: DB}
+}
+
+// GetByKey 按缓存键获取 AI 分析结果
+func (r *AICacheRepository) GetByKey(key string) (*model.AICache, error) {
+	var cache model.AICache
+	if err := r.db.Where("cache_key = ?", key).First(&cache).Error; err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// Save 保存 AI 分析结果（同一缓存键覆盖旧结果）
+func (r *AICacheRepository) Save(cache *model.AICache) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "model", "result", "hit_count", "created_at"}),
+	}).Create(cache).Error
+}
+
+// IncrementHit 记录一次缓存命中
+func (r *AICacheRepository) IncrementHit(id uint) error {
+	return r.db.Model(&model.AICache{}).Where("id = ?", id).
+		UpdateColumn("hit_count", gorm.Expr("hit_count + ?", 1)).Error
+}
+
+// DeleteByProblem 删除题目的全部缓存（题目 AI 判题配置变更或题目删除时调用）
+func (r *AICacheRepository) DeleteByProblem(problemID uint) error {
+	return r.db.Where("problem_id = ?", problemID).Delete(&model.AICache{}).Error
+}
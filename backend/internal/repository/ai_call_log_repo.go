@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AICallLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAICallLogRepository() *AICallLogRepository {
+	return &AICallLogRepository{db: DB}
+}
+
+// Create 记录一次 AI 调用
+func (r *AICallLogRepository) Create(log *model.AICallLog) error {
+	return r.db.Create(log).Error
+}
+
+// GetByID 获取调用记录（含完整的提示词与响应）
+func (r *AICallLogRepository) GetByID(id uint) (*model.AICallLog, error) {
+	var log model.AICallLog
+	if err := r.db.First(&log, id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// List 分页获取调用记录，列表不返回提示词与响应内容
+func (r *AICallLogRepository) List(page, size int, submissionID, problemID uint) ([]model.AICallLog, int64, error) {
+	var logs []model.AICallLog
+	var total int64
+
+	query := r.db.Model(&model.AICallLog{})
+	if submissionID > 0 {
+		query = query.Where("submission_id = ?", submissionID)
+	}
+	if problemID > 0 {
+		query = query.Where("problem_id = ?", problemID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Omit("messages", "response", "raw_response").
+		Offset(offset).Limit(size).Order("id DESC").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// DeleteBefore 删除指定时间之前的调用记录，返回删除条数
+func (r *AICallLogRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&model.AICallLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AICompileExplainRepository struct {
+	db *gorm.DB
+}
+
+func NewAICompileExplainRepository() *AICompileExplainRepository {
+	return &AICompileExplainRepository{db: DB}
+}
+
+// GetByKey 按缓存键获取编译错误解释
+func (r *AICompileExplainRepository) GetByKey(key string) (*model.AICompileExplainCache, error) {
+	var cache model.AICompileExplainCache
+	if err := r.db.Where("cache_key = ?", key).First(&cache).Error; err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// Save 保存编译错误解释（同一缓存键覆盖旧结果）
+func (r *AICompileExplainRepository) Save(cache *model.AICompileExplainCache) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "model", "content", "line", "hit_count", "created_at"}),
+	}).Create(cache).Error
+}
+
+// IncrementHit 记录一次缓存命中
+func (r *AICompileExplainRepository) IncrementHit(id uint) error {
+	return r.db.Model(&model.AICompileExplainCache{}).Where("id = ?", id).
+		UpdateColumn("hit_count", gorm.Expr("hit_count + ?", 1)).Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AIPromptRepository struct {
+	db *gorm.DB
+}
+
+func NewAIPromptRepository() *AIPromptRepository {
+	return &AIPromptRepository{db: DB}
+}
+
+// Create 创建模板并记录第 1 个版本
+func (r *AIPromptRepository) Create(tpl *model.AIPromptTemplate, comment string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tpl.Version = 1
+		if err := tx.Create(tpl).Error; err != nil {
+			return err
+		}
+		return tx.Create(versionOf(tpl, comment)).Error
+	})
+}
+
+// Update 保存模板修改，版本号加 1 并记录新版本
+func (r *AIPromptRepository) Update(tpl *model.AIPromptTemplate, comment string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tpl.Version++
+		if err := tx.Save(tpl).Error; err != nil {
+			return err
+		}
+		return tx.Create(versionOf(tpl, comment)).Error
+	})
+}
+
+// UpdateInfo 仅更新名称与说明，不产生新版本
+func (r *AIPromptRepository) UpdateInfo(tpl *model.AIPromptTemplate) error {
+	return r.db.Model(tpl).Updates(map[string]interface{}{
+		"name":        tpl.Name,
+		"description": tpl.Description,
+	}).Error
+}
+
+func versionOf(tpl *model.AIPromptTemplate, comment string) *model.AIPromptTemplateVersion {
+	return &model.AIPromptTemplateVersion{
+		TemplateID:   tpl.ID,
+		Version:      tpl.Version,
+		SystemPrompt: tpl.SystemPrompt,
+		UserPrompt:   tpl.UserPrompt,
+		Comment:      comment,
+		CreatedBy:    tpl.UpdatedBy,
+	}
+}
+
+// GetByID 获取模板
+func (r *AIPromptRepository) GetByID(id uint) (*model.AIPromptTemplate, error) {
+	var tpl model.AIPromptTemplate
+	if err := r.db.First(&tpl, id).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// ExistsByName 检查模板名称是否已被其他模板使用
+func (r *AIPromptRepository) ExistsByName(name string, excludeID uint) bool {
+	var count int64
+	r.db.Model(&model.AIPromptTemplate{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count)
+	return count > 0
+}
+
+// List 获取全部模板
+func (r *AIPromptRepository) List() ([]model.AIPromptTemplate, error) {
+	var templates []model.AIPromptTemplate
+	if err := r.db.Order("id ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete 删除模板及其全部历史版本
+func (r *AIPromptRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&model.AIPromptTemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.AIPromptTemplate{}, id).Error
+	})
+}
+
+// ListVersions 获取模板的历史版本（新版本在前）
+func (r *AIPromptRepository) ListVersions(templateID uint) ([]model.AIPromptTemplateVersion, error) {
+	var versions []model.AIPromptTemplateVersion
+	if err := r.db.Where("template_id = ?", templateID).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion 获取模板的指定版本
+func (r *AIPromptRepository) GetVersion(templateID uint, version int) (*model.AIPromptTemplateVersion, error) {
+	var v model.AIPromptTemplateVersion
+	if err := r.db.Where("template_id = ? AND version = ?", templateID, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
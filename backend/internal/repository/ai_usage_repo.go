@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AIUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewAIUsageRepository() *AIUsageRepository {
+	return &AIUsageRepository{db: DB}
+}
+
+// Add 累加一次调用的用量
+func (r *AIUsageRepository) Add(date string, problemID, contestID uint, promptTokens, completionTokens int64) error {
+	usage := &model.AIUsageDaily{
+		Date:             date,
+		ProblemID:        problemID,
+		ContestID:        contestID,
+		Calls:            1,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "date"}, {Name: "problem_id"}, {Name: "contest_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"calls":             gorm.Expr("calls + 1"),
+			"prompt_tokens":     gorm.Expr("prompt_tokens + ?", promptTokens),
+			"completion_tokens": gorm.Expr("completion_tokens + ?", completionTokens),
+			"total_tokens":      gorm.Expr("total_tokens + ?", promptTokens+completionTokens),
+			"updated_at":        gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	}).Create(usage).Error
+}
+
+const usageSumColumns = "COALESCE(SUM(calls), 0) AS calls, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, " +
+	"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, COALESCE(SUM(total_tokens), 0) AS total_tokens"
+
+// SumTokens 统计日期区间内（含两端）的 token 总量
+func (r *AIUsageRepository) SumTokens(from, to string) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.AIUsageDaily{}).
+		Where("date >= ? AND date <= ?", from, to).
+		Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+// Totals 日期区间内的用量合计
+func (r *AIUsageRepository) Totals(from, to string) (*model.AIUsageTotals, error) {
+	var totals model.AIUsageTotals
+	err := r.db.Model(&model.AIUsageDaily{}).
+		Where("date >= ? AND date <= ?", from, to).
+		Select(usageSumColumns).Scan(&totals).Error
+	return &totals, err
+}
+
+// ListDaily 日期区间内按天汇总
+func (r *AIUsageRepository) ListDaily(from, to string) ([]model.AIUsageDayItem, error) {
+	var items []model.AIUsageDayItem
+	err := r.db.Model(&model.AIUsageDaily{}).
+		Where("date >= ? AND date <= ?", from, to).
+		Select("date, " + usageSumColumns).
+		Group("date").Order("date").Scan(&items).Error
+	return items, err
+}
+
+// ListByProblem 日期区间内按题目汇总，按 token 用量降序
+func (r *AIUsageRepository) ListByProblem(from, to string) ([]model.AIUsageProblemItem, error) {
+	var items []model.AIUsageProblemItem
+	err := r.db.Table("ai_usage_dailies AS u").
+		Joins("LEFT JOIN problems p ON p.id = u.problem_id").
+		Where("u.date >= ? AND u.date <= ?", from, to).
+		Select("u.problem_id AS problem_id, COALESCE(p.title, '') AS problem_title, " +
+			"SUM(u.calls) AS calls, SUM(u.prompt_tokens) AS prompt_tokens, " +
+			"SUM(u.completion_tokens) AS completion_tokens, SUM(u.total_tokens) AS total_tokens").
+		Group("u.problem_id, p.title").Order("total_tokens DESC").Scan(&items).Error
+	return items, err
+}
+
+// ListByContest 日期区间内按比赛汇总（不含非比赛提交），按 token 用量降序
+func (r *AIUsageRepository) ListByContest(from, to string) ([]model.AIUsageContestItem, error) {
+	var items []model.AIUsageContestItem
+	err := r.db.Table("ai_usage_dailies AS u").
+		Joins("LEFT JOIN contests c ON c.id = u.contest_id").
+		Where("u.date >= ? AND u.date <= ? AND u.contest_id > 0", from, to).
+		Select("u.contest_id AS contest_id, COALESCE(c.title, '') AS contest_title, " +
+			"SUM(u.calls) AS calls, SUM(u.prompt_tokens) AS prompt_tokens, " +
+			"SUM(u.completion_tokens) AS completion_tokens, SUM(u.total_tokens) AS total_tokens").
+		Group("u.contest_id, c.title").Order("total_tokens DESC").Scan(&items).Error
+	return items, err
+}
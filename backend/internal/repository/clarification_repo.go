@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ClarificationRepository struct {
+	db *gorm.DB
+}
+
+func NewClarificationRepository() *ClarificationRepository {
+	return &ClarificationRepository{db: DB}
+}
+
+// Create 创建提问
+func (r *ClarificationRepository) Create(clarification *model.ContestClarification) error {
+	return r.db.Create(clarification).Error
+}
+
+// Save 保存提问（回复后调用）
+func (r *ClarificationRepository) Save(clarification *model.ContestClarification) error {
+	return r.db.Save(clarification).Error
+}
+
+// GetByID 获取比赛中的某条提问
+func (r *ClarificationRepository) GetByID(contestID, id uint) (*model.ContestClarification, error) {
+	var clarification model.ContestClarification
+	if err := r.db.Where("contest_id = ?", contestID).First(&clarification, id).Error; err != nil {
+		return nil, err
+	}
+	return &clarification, nil
+}
+
+// Delete 删除比赛中的某条提问
+func (r *ClarificationRepository) Delete(contestID, id uint) (bool, error) {
+	result := r.db.Where("contest_id = ?", contestID).Delete(&model.ContestClarification{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByContest 获取比赛的提问（附带提问人与回复人用户名，按时间倒序）。
+// userID 不为 0 时只返回该用户的提问与已公开的回复
+func (r *ClarificationRepository) ListByContest(contestID, userID uint) ([]model.ContestClarification, error) {
+	var clarifications []model.ContestClarification
+	query := r.db.Where("contest_id = ?", contestID)
+	if userID > 0 {
+		query = query.Where("user_id = ? OR visibility = ?", userID, model.ClarificationVisibilityPublic)
+	}
+	if err := query.Order("id DESC").Find(&clarifications).Error; err != nil {
+		return nil, err
+	}
+	if len(clarifications) == 0 {
+		return clarifications, nil
+	}
+
+	userIDs := make([]uint, 0, len(clarifications)*2)
+	for _, clarification := range clarifications {
+		userIDs = append(userIDs, clarification.UserID, clarification.AnsweredBy)
+	}
+	var users []model.User
+	if err := r.db.Select("id, username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Username
+	}
+	for i := range clarifications {
+		clarifications[i].Username = names[clarifications[i].UserID]
+		clarifications[i].AnswererName = names[clarifications[i].AnsweredBy]
+	}
+	return clarifications, nil
+}
+
+// CountUnanswered 统计用户在比赛中尚未回复的提问数
+func (r *ClarificationRepository) CountUnanswered(contestID, userID uint) int64 {
+	var count int64
+	r.db.Model(&model.ContestClarification{}).
+		Where("contest_id = ? AND user_id = ? AND answered_at IS NULL", contestID, userID).
+		Count(&count)
+	return count
+}
+
+// CreateAnnouncement 创建比赛公告
+func (r *ClarificationRepository) CreateAnnouncement(announcement *model.ContestAnnouncement) error {
+	return r.db.Create(announcement).Error
+}
+
+// DeleteAnnouncement 删除比赛中的某条公告
+func (r *ClarificationRepository) DeleteAnnouncement(contestID, id uint) (bool, error) {
+	result := r.db.Where("contest_id = ?", contestID).Delete(&model.ContestAnnouncement{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListAnnouncements 获取比赛公告（按时间倒序）
+func (r *ClarificationRepository) ListAnnouncements(contestID uint) ([]model.ContestAnnouncement, error) {
+	var announcements []model.ContestAnnouncement
+	if err := r.db.Where("contest_id = ?", contestID).Order("id DESC").Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// DeleteByContest 删除比赛的提问与公告
+func (r *ClarificationRepository) DeleteByContest(contestID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("contest_id = ?", contestID).Delete(&model.ContestClarification{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("contest_id = ?", contestID).Delete(&model.ContestAnnouncement{}).Error
+	})
+}
@@ -24,6 +24,28 @@ func (r *ContestRepository) Update(contest *model.Contest) error {
 	return r.db.Save(contest).Error
 }
 
+// UpdateFreezeProgress 保存封榜揭晓进度
+func (r *ContestRepository) UpdateFreezeProgress(id uint, revealed model.StringList, unfrozenAt *time.Time) error {
+	return r.db.Model(&model.Contest{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"revealed_cells": revealed,
+		"unfrozen_at":    unfrozenAt,
+	}).Error
+}
+
+// InitAliasSalt 为尚未设置匿名代号密钥的比赛写入密钥，返回实际生效的密钥（并发写入时以先写入的为准）
+func (r *ContestRepository) InitAliasSalt(id uint, salt string) (string, error) {
+	if err := r.db.Model(&model.Contest{}).
+		Where("id = ? AND (alias_salt = '' OR alias_salt IS NULL)", id).
+		Update("alias_salt", salt).Error; err != nil {
+		return "", err
+	}
+	var contest model.Contest
+	if err := r.db.Select("alias_salt").First(&contest, id).Error; err != nil {
+		return "", err
+	}
+	return contest.AliasSalt, nil
+}
+
 func (r *ContestRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Contest{}, id).Error
 }
@@ -58,6 +80,15 @@ func (r *ContestRepository) ListAll() ([]model.Contest, error) {
 	return contests, nil
 }
 
+// ListRunningAt 获取指定时刻正在进行的比赛
+func (r *ContestRepository) ListRunningAt(at time.Time) ([]model.Contest, error) {
+	var contests []model.Contest
+	if err := r.db.Where("start_at <= ? AND end_at >= ?", at, at).Order("id").Find(&contests).Error; err != nil {
+		return nil, err
+	}
+	return contests, nil
+}
+
 // GetPendingSyncContests 获取已结束但未同步统计的比赛
 func (r *ContestRepository) GetPendingSyncContests() ([]model.Contest, error) {
 	var contests []model.Contest
@@ -46,7 +46,25 @@ func autoMigrate() error {
 		&model.ContestParticipation{},
 		&model.Problem{},
 		&model.Testcase{},
+		&model.ProblemProgram{},
+		&model.TestcaseGeneration{},
+		&model.ProblemSolution{},
+		&model.ProblemVerification{},
+		&model.TestcaseProposalBatch{},
+		&model.TestcaseProposal{},
+		&model.PlagiarismReport{},
+		&model.PlagiarismPair{},
+		&model.ContestLock{},
+		&model.Hack{},
+		&model.ContestClarification{},
+		&model.ContestAnnouncement{},
 		&model.Submission{},
+		&model.AICache{},
+		&model.AICompileExplainCache{},
+		&model.AIPromptTemplate{},
+		&model.AIPromptTemplateVersion{},
+		&model.AICallLog{},
+		&model.AIUsageDaily{},
 		&model.Setting{},
 	)
 }
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type HackRepository struct {
+	db *gorm.DB
+}
+
+func NewHackRepository() *HackRepository {
+	return &HackRepository{db: DB}
+}
+
+// GetLock 获取选手对某题的锁定记录
+func (r *HackRepository) GetLock(contestID, userID, problemID uint) (*model.ContestLock, error) {
+	var lock model.ContestLock
+	if err := r.db.Where("contest_id = ? AND user_id = ? AND problem_id = ?", contestID, userID, problemID).First(&lock).Error; err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// CreateLock 创建锁定记录
+func (r *HackRepository) CreateLock(lock *model.ContestLock) error {
+	return r.db.Create(lock).Error
+}
+
+// ListLocksByUser 获取选手在比赛中的全部锁定记录
+func (r *HackRepository) ListLocksByUser(contestID, userID uint) ([]model.ContestLock, error) {
+	var locks []model.ContestLock
+	if err := r.db.Where("contest_id = ? AND user_id = ?", contestID, userID).Order("id ASC").Find(&locks).Error; err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+// IsProblemLocked 检查选手是否在指定比赛中锁定了该题
+func (r *HackRepository) IsProblemLocked(contestID, userID, problemID uint) bool {
+	var count int64
+	r.db.Model(&model.ContestLock{}).
+		Where("contest_id = ? AND user_id = ? AND problem_id = ?", contestID, userID, problemID).
+		Count(&count)
+	return count > 0
+}
+
+// Create 创建 hack 记录
+func (r *HackRepository) Create(hack *model.Hack) error {
+	return r.db.Create(hack).Error
+}
+
+// Save 保存 hack 记录
+func (r *HackRepository) Save(hack *model.Hack) error {
+	return r.db.Save(hack).Error
+}
+
+// GetByID 获取 hack 记录
+func (r *HackRepository) GetByID(id uint) (*model.Hack, error) {
+	var hack model.Hack
+	if err := r.db.First(&hack, id).Error; err != nil {
+		return nil, err
+	}
+	return &hack, nil
+}
+
+// ListByContest 获取比赛的 hack 记录（附带双方用户名，按时间倒序）
+func (r *HackRepository) ListByContest(contestID uint) ([]model.Hack, error) {
+	var hacks []model.Hack
+	if err := r.db.Where("contest_id = ?", contestID).Order("id DESC").Find(&hacks).Error; err != nil {
+		return nil, err
+	}
+	if len(hacks) == 0 {
+		return hacks, nil
+	}
+
+	userIDs := make([]uint, 0, len(hacks)*2)
+	for _, hack := range hacks {
+		userIDs = append(userIDs, hack.HackerID, hack.TargetUserID)
+	}
+	var users []model.User
+	if err := r.db.Select("id, username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Username
+	}
+	for i := range hacks {
+		hacks[i].HackerName = names[hacks[i].HackerID]
+		hacks[i].TargetName = names[hacks[i].TargetUserID]
+	}
+	return hacks, nil
+}
+
+// HasPendingByHacker 检查选手是否有尚未完成的 hack
+func (r *HackRepository) HasPendingByHacker(hackerID uint) bool {
+	var count int64
+	r.db.Model(&model.Hack{}).
+		Where("hacker_id = ? AND status = ?", hackerID, model.HackStatusPending).
+		Count(&count)
+	return count > 0
+}
+
+// MarkPendingAsError 将所有未完成的 hack 标记为异常（服务重启后调用）
+func (r *HackRepository) MarkPendingAsError(message string) error {
+	now := time.Now()
+	return r.db.Model(&model.Hack{}).
+		Where("status = ?", model.HackStatusPending).
+		Updates(map[string]interface{}{
+			"status":      model.HackStatusError,
+			"message":     message,
+			"finished_at": now,
+		}).Error
+}
+
+// DeleteByContest 删除比赛的锁定与 hack 记录
+func (r *HackRepository) DeleteByContest(contestID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("contest_id = ?", contestID).Delete(&model.ContestLock{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("contest_id = ?", contestID).Delete(&model.Hack{}).Error
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PlagiarismRepository struct {
+	db *gorm.DB
+}
+
+func NewPlagiarismRepository() *PlagiarismRepository {
+	return &PlagiarismRepository{db: DB}
+}
+
+// CreateReport 创建查重报告
+func (r *PlagiarismRepository) CreateReport(report *model.PlagiarismReport) error {
+	return r.db.Create(report).Error
+}
+
+// SaveReport 保存查重报告
+func (r *PlagiarismRepository) SaveReport(report *model.PlagiarismReport) error {
+	return r.db.Save(report).Error
+}
+
+// GetReport 获取查重报告
+func (r *PlagiarismRepository) GetReport(id uint) (*model.PlagiarismReport, error) {
+	var report model.PlagiarismReport
+	if err := r.db.First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReports 分页获取查重报告，scope 为空时不过滤
+func (r *PlagiarismRepository) ListReports(page, size int, scope string, targetID uint) ([]model.PlagiarismReport, int64, error) {
+	var reports []model.PlagiarismReport
+	var total int64
+
+	query := r.db.Model(&model.PlagiarismReport{})
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+		if targetID > 0 {
+			query = query.Where("target_id = ?", targetID)
+		}
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * size
+	if err := query.Order("id DESC").Offset(offset).Limit(size).Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
+
+// DeleteReport 删除查重报告及其代码对
+func (r *PlagiarismRepository) DeleteReport(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("report_id = ?", id).Delete(&model.PlagiarismPair{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.PlagiarismReport{}, id).Error
+	})
+}
+
+// HasRunning 检查同一题目/比赛是否有进行中的查重任务
+func (r *PlagiarismRepository) HasRunning(scope string, targetID uint) bool {
+	var count int64
+	r.db.Model(&model.PlagiarismReport{}).
+		Where("scope = ? AND target_id = ? AND status = ?", scope, targetID, model.PlagiarismStatusRunning).
+		Count(&count)
+	return count > 0
+}
+
+// MarkRunningAsError 将所有进行中的查重任务标记为异常（服务重启后调用）
+func (r *PlagiarismRepository) MarkRunningAsError(message string) error {
+	now := time.Now()
+	return r.db.Model(&model.PlagiarismReport{}).
+		Where("status = ?", model.PlagiarismStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      model.PlagiarismStatusError,
+			"message":     message,
+			"finished_at": now,
+		}).Error
+}
+
+// CreatePairs 批量保存相似代码对
+func (r *PlagiarismRepository) CreatePairs(pairs []model.PlagiarismPair) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	return r.db.Create(&pairs).Error
+}
+
+// ListPairs 获取报告的相似代码对（按相似度降序）
+func (r *PlagiarismRepository) ListPairs(reportID uint) ([]model.PlagiarismPair, error) {
+	var pairs []model.PlagiarismPair
+	if err := r.db.Where("report_id = ?", reportID).Order("similarity DESC, id ASC").Find(&pairs).Error; err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// GetPair 获取报告中的一对代码
+func (r *PlagiarismRepository) GetPair(reportID, pairID uint) (*model.PlagiarismPair, error) {
+	var pair model.PlagiarismPair
+	if err := r.db.Where("report_id = ? AND id = ?", reportID, pairID).First(&pair).Error; err != nil {
+		return nil, err
+	}
+	return &pair, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ProblemProgramRepository struct {
+	db *gorm.DB
+}
+
+func NewProblemProgramRepository() *ProblemProgramRepository {
+	return &ProblemProgramRepository{db: DB}
+}
+
+// GetByProblemAndRole 获取题目指定角色的配套程序
+func (r *ProblemProgramRepository) GetByProblemAndRole(problemID uint, role string) (*model.ProblemProgram, error) {
+	var program model.ProblemProgram
+	if err := r.db.Where("problem_id = ? AND role = ?", problemID, role).First(&program).Error; err != nil {
+		return nil, err
+	}
+	return &program, nil
+}
+
+// ListByProblem 获取题目的全部配套程序
+func (r *ProblemProgramRepository) ListByProblem(problemID uint) ([]model.ProblemProgram, error) {
+	var programs []model.ProblemProgram
+	if err := r.db.Where("problem_id = ?", problemID).Order("role ASC").Find(&programs).Error; err != nil {
+		return nil, err
+	}
+	return programs, nil
+}
+
+// Save 创建或更新配套程序（按题目 + 角色唯一）
+func (r *ProblemProgramRepository) Save(program *model.ProblemProgram) error {
+	existing, err := r.GetByProblemAndRole(program.ProblemID, program.Role)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	if existing != nil {
+		program.ID = existing.ID
+		program.CreatedAt = existing.CreatedAt
+	}
+	return r.db.Save(program).Error
+}
+
+// DeleteByProblemAndRole 删除题目指定角色的配套程序
+func (r *ProblemProgramRepository) DeleteByProblemAndRole(problemID uint, role string) (bool, error) {
+	result := r.db.Where("problem_id = ? AND role = ?", problemID, role).Delete(&model.ProblemProgram{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateGeneration 创建测试数据生成任务
+func (r *ProblemProgramRepository) CreateGeneration(generation *model.TestcaseGeneration) error {
+	return r.db.Create(generation).Error
+}
+
+// SaveGeneration 保存测试数据生成任务
+func (r *ProblemProgramRepository) SaveGeneration(generation *model.TestcaseGeneration) error {
+	return r.db.Save(generation).Error
+}
+
+// GetGeneration 获取测试数据生成任务
+func (r *ProblemProgramRepository) GetGeneration(id uint) (*model.TestcaseGeneration, error) {
+	var generation model.TestcaseGeneration
+	if err := r.db.First(&generation, id).Error; err != nil {
+		return nil, err
+	}
+	return &generation, nil
+}
+
+// ListGenerations 获取题目最近的测试数据生成任务
+func (r *ProblemProgramRepository) ListGenerations(problemID uint, limit int) ([]model.TestcaseGeneration, error) {
+	var generations []model.TestcaseGeneration
+	if err := r.db.Where("problem_id = ?", problemID).Order("id DESC").Limit(limit).Find(&generations).Error; err != nil {
+		return nil, err
+	}
+	return generations, nil
+}
+
+// HasRunningGeneration 检查题目是否有进行中的测试数据生成任务
+func (r *ProblemProgramRepository) HasRunningGeneration(problemID uint) bool {
+	var count int64
+	r.db.Model(&model.TestcaseGeneration{}).
+		Where("problem_id = ? AND status = ?", problemID, model.GenerationStatusRunning).
+		Count(&count)
+	return count > 0
+}
+
+// MarkRunningGenerationsAsError 将所有进行中的测试数据生成任务标记为异常（服务重启后调用）
+func (r *ProblemProgramRepository) MarkRunningGenerationsAsError(message string) error {
+	now := time.Now()
+	return r.db.Model(&model.TestcaseGeneration{}).
+		Where("status = ?", model.GenerationStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      model.GenerationStatusError,
+			"message":     message,
+			"finished_at": now,
+		}).Error
+}
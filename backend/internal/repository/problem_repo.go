@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+
 	"oj-system/internal/model"
 
 	"gorm.io/gorm"
@@ -40,9 +42,52 @@ func (r *ProblemRepository) GetByIDs(ids []uint) ([]model.Problem, error) {
 	return problems, nil
 }
 
-// Update 更新题目
+// Update 更新题目；译文可能正由后台翻译任务修改，只通过 UpdateTranslations 写入，这里不写回
 func (r *ProblemRepository) Update(problem *model.Problem) error {
-	return r.db.Save(problem).Error
+	return r.db.Omit("translations").Save(problem).Error
+}
+
+// UpdateTranslations 在事务中读取并修改题目的译文，只写回 translations 列（不影响题目的其他字段与更新时间）
+func (r *ProblemRepository) UpdateTranslations(problemID uint, fn func(model.ProblemTranslationMap) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var problem model.Problem
+		if err := tx.Select("id", "translations").First(&problem, problemID).Error; err != nil {
+			return err
+		}
+		translations := problem.Translations
+		if translations == nil {
+			translations = model.ProblemTranslationMap{}
+		}
+		if err := fn(translations); err != nil {
+			return err
+		}
+		return tx.Model(&model.Problem{}).Where("id = ?", problemID).
+			UpdateColumn("translations", translations).Error
+	})
+}
+
+// ListIDsWithTranslationStatus 获取存在指定状态译文的题目 ID
+func (r *ProblemRepository) ListIDsWithTranslationStatus(status string) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Problem{}).
+		Where("translations LIKE ?", `%"status":"`+status+`"%`).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListIDsByPromptTemplate 获取 AI 判题配置指定了该提示词模板的题目 ID
+func (r *ProblemRepository) ListIDsByPromptTemplate(templateID uint) ([]uint, error) {
+	field := fmt.Sprintf(`"prompt_template_id":%d`, templateID)
+	var ids []uint
+	if err := r.db.Model(&model.Problem{}).
+		Where("ai_judge_config LIKE ? OR ai_judge_config LIKE ?", "%"+field+",%", "%"+field+"}%").
+		Order("id").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 // Delete 删除题目
@@ -52,6 +97,21 @@ func (r *ProblemRepository) Delete(id uint) error {
 		if err := tx.Where("problem_id = ?", id).Delete(&model.Testcase{}).Error; err != nil {
 			return err
 		}
+		// 删除关联的配套程序
+		if err := tx.Where("problem_id = ?", id).Delete(&model.ProblemProgram{}).Error; err != nil {
+			return err
+		}
+		// 删除关联的标注解法与校验记录
+		if err := tx.Where("problem_id = ?", id).Delete(&model.ProblemSolution{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("problem_id = ?", id).Delete(&model.ProblemVerification{}).Error; err != nil {
+			return err
+		}
+		// 删除 AI 分析缓存
+		if err := tx.Where("problem_id = ?", id).Delete(&model.AICache{}).Error; err != nil {
+			return err
+		}
 		// 删除题目
 		return tx.Delete(&model.Problem{}, id).Error
 	})
@@ -130,6 +190,40 @@ func (r *ProblemRepository) DeleteTestcases(problemID uint) error {
 	return r.db.Where("problem_id = ?", problemID).Delete(&model.Testcase{}).Error
 }
 
+// DeleteRegularTestcases 删除题目的普通测试用例（保留 hack 测试点）
+func (r *ProblemRepository) DeleteRegularTestcases(problemID uint) error {
+	return r.db.Where("problem_id = ? AND is_hack = ?", problemID, false).Delete(&model.Testcase{}).Error
+}
+
+// ReplaceRegularTestcases 在事务中用新的测试用例替换题目的普通测试用例，hack 测试点保留并排在最后
+func (r *ProblemRepository) ReplaceRegularTestcases(problemID uint, testcases []model.Testcase) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("problem_id = ? AND is_hack = ?", problemID, false).Delete(&model.Testcase{}).Error; err != nil {
+			return err
+		}
+		if len(testcases) > 0 {
+			if err := tx.Create(&testcases).Error; err != nil {
+				return err
+			}
+		}
+		var hacks []model.Testcase
+		if err := tx.Where("problem_id = ? AND is_hack = ?", problemID, true).Order("order_num ASC").Find(&hacks).Error; err != nil {
+			return err
+		}
+		for i, tc := range hacks {
+			if err := tx.Model(&model.Testcase{}).Where("id = ?", tc.ID).Update("order_num", len(testcases)+i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTestcaseOrder 更新测试用例序号
+func (r *ProblemRepository) UpdateTestcaseOrder(id uint, orderNum int) error {
+	return r.db.Model(&model.Testcase{}).Where("id = ?", id).Update("order_num", orderNum).Error
+}
+
 // IncrementSubmitCount 增加题目提交数
 func (r *ProblemRepository) IncrementSubmitCount(problemID uint) error {
 	return r.db.Model(&model.Problem{}).Where("id = ?", problemID).
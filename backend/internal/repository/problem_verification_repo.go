@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ProblemVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewProblemVerificationRepository() *ProblemVerificationRepository {
+	return &ProblemVerificationRepository{db: DB}
+}
+
+// ListSolutions 获取题目的标注解法
+func (r *ProblemVerificationRepository) ListSolutions(problemID uint) ([]model.ProblemSolution, error) {
+	var solutions []model.ProblemSolution
+	if err := r.db.Where("problem_id = ?", problemID).Order("id ASC").Find(&solutions).Error; err != nil {
+		return nil, err
+	}
+	return solutions, nil
+}
+
+// GetSolution 获取题目下的指定标注解法
+func (r *ProblemVerificationRepository) GetSolution(problemID, solutionID uint) (*model.ProblemSolution, error) {
+	var solution model.ProblemSolution
+	if err := r.db.Where("id = ? AND problem_id = ?", solutionID, problemID).First(&solution).Error; err != nil {
+		return nil, err
+	}
+	return &solution, nil
+}
+
+// SaveSolution 创建或更新标注解法
+func (r *ProblemVerificationRepository) SaveSolution(solution *model.ProblemSolution) error {
+	return r.db.Save(solution).Error
+}
+
+// DeleteSolution 删除标注解法
+func (r *ProblemVerificationRepository) DeleteSolution(problemID, solutionID uint) (bool, error) {
+	result := r.db.Where("id = ? AND problem_id = ?", solutionID, problemID).Delete(&model.ProblemSolution{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Create 创建校验任务
+func (r *ProblemVerificationRepository) Create(verification *model.ProblemVerification) error {
+	return r.db.Create(verification).Error
+}
+
+// Save 保存校验任务
+func (r *ProblemVerificationRepository) Save(verification *model.ProblemVerification) error {
+	return r.db.Save(verification).Error
+}
+
+// GetByID 获取校验任务
+func (r *ProblemVerificationRepository) GetByID(id uint) (*model.ProblemVerification, error) {
+	var verification model.ProblemVerification
+	if err := r.db.First(&verification, id).Error; err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ListByProblem 获取题目最近的校验任务
+func (r *ProblemVerificationRepository) ListByProblem(problemID uint, limit int) ([]model.ProblemVerification, error) {
+	var verifications []model.ProblemVerification
+	if err := r.db.Where("problem_id = ?", problemID).Order("id DESC").Limit(limit).Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// HasRunning 检查题目是否有进行中的校验任务
+func (r *ProblemVerificationRepository) HasRunning(problemID uint) bool {
+	var count int64
+	r.db.Model(&model.ProblemVerification{}).
+		Where("problem_id = ? AND status = ?", problemID, model.VerificationStatusRunning).
+		Count(&count)
+	return count > 0
+}
+
+// MarkRunningAsError 将所有进行中的校验任务标记为异常（服务重启后调用）
+func (r *ProblemVerificationRepository) MarkRunningAsError(message string) error {
+	now := time.Now()
+	return r.db.Model(&model.ProblemVerification{}).
+		Where("status = ?", model.VerificationStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      model.VerificationStatusError,
+			"message":     message,
+			"finished_at": now,
+		}).Error
+}
@@ -31,7 +31,9 @@ func (r *SubmissionRepository) GetByID(id uint) (*model.Submission, error) {
 			"submissions.id, submissions.problem_id, submissions.user_id, submissions.language, submissions.code, "+
 				"submissions.status, submissions.time_used, submissions.memory_used, submissions.score, "+
 				"submissions.testcase_results, submissions.ai_judge_result, submissions.compile_error, "+
-				"submissions.final_message, submissions.created_at, problems.title as problem_title, users.username as username",
+				"submissions.final_message, submissions.judge_env, submissions.ai_status, "+
+				"submissions.ai_hint_status, submissions.ai_hint, submissions.compile_explain_status, submissions.compile_explanation, "+
+				"submissions.created_at, problems.title as problem_title, users.username as username",
 		).
 		Joins("LEFT JOIN problems ON submissions.problem_id = problems.id").
 		Joins("LEFT JOIN users ON submissions.user_id = users.id").
@@ -42,7 +44,9 @@ func (r *SubmissionRepository) GetByID(id uint) (*model.Submission, error) {
 		&submission.Language, &submission.Code, &submission.Status,
 		&submission.TimeUsed, &submission.MemoryUsed, &submission.Score,
 		&submission.TestcaseResults, &submission.AIJudgeResult,
-		&submission.CompileError, &submission.FinalMessage, &submission.CreatedAt,
+		&submission.CompileError, &submission.FinalMessage, &submission.JudgeEnv, &submission.AIStatus,
+		&submission.AIHintStatus, &submission.AIHint, &submission.CompileExplainStatus, &submission.CompileExplanation,
+		&submission.CreatedAt,
 		&problemTitle, &username,
 	); err != nil {
 		return nil, err
@@ -58,16 +62,7 @@ func (r *SubmissionRepository) GetByID(id uint) (*model.Submission, error) {
 func (r *SubmissionRepository) Update(submission *model.Submission) error {
 	result := r.db.Model(&model.Submission{}).
 		Where("id = ?", submission.ID).
-		Updates(map[string]interface{}{
-			"status":           submission.Status,
-			"time_used":        submission.TimeUsed,
-			"memory_used":      submission.MemoryUsed,
-			"score":            submission.Score,
-			"testcase_results": submission.TestcaseResults,
-			"ai_judge_result":  submission.AIJudgeResult,
-			"compile_error":    submission.CompileError,
-			"final_message":    submission.FinalMessage,
-		})
+		Updates(submissionResultColumns(submission))
 	if result.Error != nil {
 		return result.Error
 	}
@@ -77,6 +72,33 @@ func (r *SubmissionRepository) Update(submission *model.Submission) error {
 	return nil
 }
 
+// UpdateIfAIStatus 仅当提交仍处于指定的 AI 分析状态（且未被重测）时更新，返回是否更新成功
+func (r *SubmissionRepository) UpdateIfAIStatus(submission *model.Submission, aiStatus string) (bool, error) {
+	result := r.db.Model(&model.Submission{}).
+		Where("id = ? AND ai_status = ? AND status NOT IN ?", submission.ID, aiStatus,
+			[]string{model.StatusPending, model.StatusJudging}).
+		Updates(submissionResultColumns(submission))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func submissionResultColumns(submission *model.Submission) map[string]interface{} {
+	return map[string]interface{}{
+		"status":           submission.Status,
+		"time_used":        submission.TimeUsed,
+		"memory_used":      submission.MemoryUsed,
+		"score":            submission.Score,
+		"testcase_results": submission.TestcaseResults,
+		"ai_judge_result":  submission.AIJudgeResult,
+		"compile_error":    submission.CompileError,
+		"final_message":    submission.FinalMessage,
+		"judge_env":        submission.JudgeEnv,
+		"ai_status":        submission.AIStatus,
+	}
+}
+
 // DeleteByID 删除提交记录
 func (r *SubmissionRepository) DeleteByID(id uint) error {
 	result := r.db.Delete(&model.Submission{}, id)
@@ -111,7 +133,7 @@ func (r *SubmissionRepository) List(page, size int, problemID, userID uint, stat
 	offset := (page - 1) * size
 	rows, err := query.Select(
 		"submissions.id, submissions.problem_id, submissions.user_id, submissions.language, submissions.status, " +
-			"submissions.time_used, submissions.memory_used, submissions.score, submissions.created_at, " +
+			"submissions.time_used, submissions.memory_used, submissions.score, submissions.ai_status, submissions.created_at, " +
 			"problems.title as problem_title, users.username as username",
 	).
 		Joins("LEFT JOIN problems ON submissions.problem_id = problems.id").
@@ -127,7 +149,7 @@ func (r *SubmissionRepository) List(page, size int, problemID, userID uint, stat
 		if err := rows.Scan(
 			&item.ID, &item.ProblemID, &item.UserID,
 			&item.Language, &item.Status,
-			&item.TimeUsed, &item.MemoryUsed, &item.Score,
+			&item.TimeUsed, &item.MemoryUsed, &item.Score, &item.AIStatus,
 			&item.CreatedAt, &item.ProblemTitle, &item.Username,
 		); err != nil {
 			continue
@@ -138,6 +160,49 @@ func (r *SubmissionRepository) List(page, size int, problemID, userID uint, stat
 	return items, total, nil
 }
 
+// ListByAIStatusWithResult 分页获取处于指定 AI 分析状态的提交及其 AI 判题结果，按提交时间先后排列
+func (r *SubmissionRepository) ListByAIStatusWithResult(page, size int, aiStatus string, problemID uint) ([]model.AIReviewQueueItem, int64, error) {
+	var total int64
+	items := make([]model.AIReviewQueueItem, 0)
+
+	query := r.db.Model(&model.Submission{}).Where("submissions.ai_status = ?", aiStatus)
+	if problemID > 0 {
+		query = query.Where("submissions.problem_id = ?", problemID)
+	}
+	query.Count(&total)
+
+	offset := (page - 1) * size
+	rows, err := query.Select(
+		"submissions.id, submissions.problem_id, submissions.user_id, submissions.language, submissions.status, " +
+			"submissions.time_used, submissions.memory_used, submissions.score, submissions.ai_status, submissions.created_at, " +
+			"submissions.ai_judge_result, problems.title as problem_title, users.username as username",
+	).
+		Joins("LEFT JOIN problems ON submissions.problem_id = problems.id").
+		Joins("LEFT JOIN users ON submissions.user_id = users.id").
+		Offset(offset).Limit(size).Order("submissions.id ASC").Rows()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.AIReviewQueueItem
+		var result model.AIJudgeResult
+		if err := rows.Scan(
+			&item.ID, &item.ProblemID, &item.UserID,
+			&item.Language, &item.Status,
+			&item.TimeUsed, &item.MemoryUsed, &item.Score, &item.AIStatus,
+			&item.CreatedAt, &result, &item.ProblemTitle, &item.Username,
+		); err != nil {
+			continue
+		}
+		item.AIJudgeResult = &result
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
 func (r *SubmissionRepository) ListRejudgeCandidatesByProblem(problemID uint) ([]model.Submission, error) {
 	var submissions []model.Submission
 	err := r.db.Where("problem_id = ?", problemID).
@@ -157,14 +222,20 @@ func (r *SubmissionRepository) ResetForRejudge(ids []uint) error {
 	return r.db.Model(&model.Submission{}).
 		Where("id IN ?", ids).
 		Updates(map[string]interface{}{
-			"status":           model.StatusPending,
-			"time_used":        0,
-			"memory_used":      0,
-			"score":            0,
-			"testcase_results": model.TestcaseResultList{},
-			"ai_judge_result":  nil,
-			"compile_error":    "",
-			"final_message":    "",
+			"status":                 model.StatusPending,
+			"time_used":              0,
+			"memory_used":            0,
+			"score":                  0,
+			"testcase_results":       model.TestcaseResultList{},
+			"ai_judge_result":        nil,
+			"compile_error":          "",
+			"final_message":          "",
+			"judge_env":              nil,
+			"ai_status":              "",
+			"ai_hint_status":         "",
+			"ai_hint":                nil,
+			"compile_explain_status": "",
+			"compile_explanation":    nil,
 		}).Error
 }
 
@@ -178,6 +249,69 @@ func (r *SubmissionRepository) GetPendingSubmissions(limit int) ([]model.Submiss
 	return submissions, nil
 }
 
+// ListIDsByAIStatus 获取处于指定 AI 分析状态的提交 ID
+func (r *SubmissionRepository) ListIDsByAIStatus(aiStatus string, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Submission{}).
+		Where("ai_status = ? AND status NOT IN ?", aiStatus, []string{model.StatusPending, model.StatusJudging}).
+		Order("id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListIDsByAIHintStatus 获取处于指定 AI 学习提示状态的提交 ID
+func (r *SubmissionRepository) ListIDsByAIHintStatus(hintStatus string, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Submission{}).
+		Where("ai_hint_status = ? AND status NOT IN ?", hintStatus, []string{model.StatusPending, model.StatusJudging}).
+		Order("id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateAIHintIfStatus 仅当提交仍处于指定的提示状态（且未被重测）时保存 AI 学习提示，返回是否更新成功
+func (r *SubmissionRepository) UpdateAIHintIfStatus(id uint, fromStatus, hintStatus string, hint *model.AIHint) (bool, error) {
+	result := r.db.Model(&model.Submission{}).
+		Where("id = ? AND ai_hint_status = ? AND status NOT IN ?", id, fromStatus,
+			[]string{model.StatusPending, model.StatusJudging}).
+		Updates(map[string]interface{}{
+			"ai_hint_status": hintStatus,
+			"ai_hint":        hint,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListIDsByCompileExplainStatus 获取处于指定编译错误解释状态的提交 ID
+func (r *SubmissionRepository) ListIDsByCompileExplainStatus(explainStatus string, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Submission{}).
+		Where("compile_explain_status = ? AND status NOT IN ?", explainStatus, []string{model.StatusPending, model.StatusJudging}).
+		Order("id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateCompileExplanationIfStatus 仅当提交仍处于指定的解释状态（且未被重测）时保存编译错误解释，返回是否更新成功
+func (r *SubmissionRepository) UpdateCompileExplanationIfStatus(id uint, fromStatus, explainStatus string, explanation *model.CompileExplanation) (bool, error) {
+	result := r.db.Model(&model.Submission{}).
+		Where("id = ? AND compile_explain_status = ? AND status NOT IN ?", id, fromStatus,
+			[]string{model.StatusPending, model.StatusJudging}).
+		Updates(map[string]interface{}{
+			"compile_explain_status": explainStatus,
+			"compile_explanation":    explanation,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // UpdateStatus 更新提交状态
 func (r *SubmissionRepository) UpdateStatus(id uint, status string) error {
 	return r.db.Model(&model.Submission{}).Where("id = ?", id).
@@ -193,6 +327,17 @@ func (r *SubmissionRepository) HasAccepted(userID, problemID uint) bool {
 	return count > 0
 }
 
+// HasSettledAccepted 检查用户是否已有其他 AI 结果已落定的 AC 提交（用于判断首次通过）
+func (r *SubmissionRepository) HasSettledAccepted(userID, problemID, excludeID uint) bool {
+	var count int64
+	r.db.Model(&model.Submission{}).
+		Where("user_id = ? AND problem_id = ? AND status = ? AND id <> ? AND (ai_status IS NULL OR ai_status NOT IN ?)",
+			userID, problemID, model.StatusAccepted, excludeID,
+			[]string{model.AIStatusReviewing, model.AIStatusPending, model.AIStatusManualReview}).
+		Count(&count)
+	return count > 0
+}
+
 // GetUserSubmissionCount 获取用户在某题目的提交数
 func (r *SubmissionRepository) GetUserSubmissionCount(userID, problemID uint) int64 {
 	var count int64
@@ -244,7 +389,7 @@ func (r *SubmissionRepository) ListForContestSince(problemIDs []uint, startAt ti
 
 	var submissions []model.ContestSubmission
 	err := r.db.Table("submissions").
-		Select("submissions.user_id, submissions.problem_id, submissions.score, submissions.created_at, users.username, users.`group` as user_group").
+		Select("submissions.user_id, submissions.problem_id, submissions.score, submissions.status, submissions.created_at, users.username, users.`group` as user_group").
 		Joins("LEFT JOIN users ON submissions.user_id = users.id").
 		Where("submissions.problem_id IN ?", problemIDs).
 		Where("submissions.created_at >= ?", startAt).
@@ -257,6 +402,68 @@ func (r *SubmissionRepository) ListForContestSince(problemIDs []uint, startAt ti
 	return submissions, nil
 }
 
+// GetLatestAcceptedInRange 获取用户在时间范围内对某题的最后一次 AC 提交
+func (r *SubmissionRepository) GetLatestAcceptedInRange(userID, problemID uint, startAt, endAt time.Time) (*model.Submission, error) {
+	startAt = startAt.In(time.Local)
+	endAt = endAt.In(time.Local)
+	var submission model.Submission
+	err := r.db.Where("user_id = ? AND problem_id = ? AND status = ?", userID, problemID, model.StatusAccepted).
+		Where("created_at >= ? AND created_at <= ?", startAt, endAt).
+		Order("id DESC").
+		First(&submission).Error
+	if err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// ListAcceptedForProblemInRange 获取时间范围内某题的全部 AC 提交（含代码，按提交倒序）
+func (r *SubmissionRepository) ListAcceptedForProblemInRange(problemID uint, startAt, endAt time.Time) ([]model.HackTarget, error) {
+	startAt = startAt.In(time.Local)
+	endAt = endAt.In(time.Local)
+
+	var targets []model.HackTarget
+	err := r.db.Table("submissions").
+		Select("submissions.id as submission_id, submissions.user_id, submissions.language, submissions.code, submissions.created_at, users.username, users.`group` as user_group").
+		Joins("LEFT JOIN users ON submissions.user_id = users.id").
+		Where("submissions.problem_id = ? AND submissions.status = ?", problemID, model.StatusAccepted).
+		Where("submissions.created_at >= ? AND submissions.created_at <= ?", startAt, endAt).
+		Order("submissions.id DESC").
+		Scan(&targets).Error
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// ListLatestForPlagiarism 获取每个用户在各题的最后一次有效提交（含代码，不含编译错误与未完成的提交），
+// startAt/endAt 为空时不限时间
+func (r *SubmissionRepository) ListLatestForPlagiarism(problemIDs []uint, startAt, endAt *time.Time) ([]model.PlagiarismSource, error) {
+	if len(problemIDs) == 0 {
+		return []model.PlagiarismSource{}, nil
+	}
+	latest := r.db.Table("submissions").
+		Select("MAX(id)").
+		Where("problem_id IN ?", problemIDs).
+		Where("status NOT IN ?", []string{model.StatusPending, model.StatusJudging, model.StatusCompileError, model.StatusSystemError})
+	if startAt != nil && endAt != nil {
+		latest = latest.Where("created_at >= ? AND created_at <= ?", startAt.In(time.Local), endAt.In(time.Local))
+	}
+	latest = latest.Group("user_id, problem_id")
+
+	var sources []model.PlagiarismSource
+	err := r.db.Table("submissions").
+		Select("submissions.id as submission_id, submissions.problem_id, submissions.user_id, users.username, submissions.language, submissions.code").
+		Joins("LEFT JOIN users ON submissions.user_id = users.id").
+		Where("submissions.id IN (?)", latest).
+		Order("submissions.id ASC").
+		Scan(&sources).Error
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
 // GetAcceptedProblemIDs 获取用户已通过的题目 ID 列表
 func (r *SubmissionRepository) GetAcceptedProblemIDs(userID uint, problemIDs []uint) ([]uint, error) {
 	if userID == 0 || len(problemIDs) == 0 {
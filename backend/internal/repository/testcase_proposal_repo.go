@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"oj-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type TestcaseProposalRepository struct {
+	db *gorm.DB
+}
+
+func NewTestcaseProposalRepository() *TestcaseProposalRepository {
+	return &TestcaseProposalRepository{db: DB}
+}
+
+// CreateBatch 创建建议任务
+func (r *TestcaseProposalRepository) CreateBatch(batch *model.TestcaseProposalBatch) error {
+	return r.db.Create(batch).Error
+}
+
+// SaveBatch 保存建议任务
+func (r *TestcaseProposalRepository) SaveBatch(batch *model.TestcaseProposalBatch) error {
+	return r.db.Save(batch).Error
+}
+
+// GetBatch 获取建议任务
+func (r *TestcaseProposalRepository) GetBatch(id uint) (*model.TestcaseProposalBatch, error) {
+	var batch model.TestcaseProposalBatch
+	if err := r.db.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 获取题目最近的建议任务
+func (r *TestcaseProposalRepository) ListBatches(problemID uint, limit int) ([]model.TestcaseProposalBatch, error) {
+	var batches []model.TestcaseProposalBatch
+	if err := r.db.Where("problem_id = ?", problemID).Order("id DESC").Limit(limit).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// HasRunning 检查题目是否有进行中的建议任务
+func (r *TestcaseProposalRepository) HasRunning(problemID uint) bool {
+	var count int64
+	r.db.Model(&model.TestcaseProposalBatch{}).
+		Where("problem_id = ? AND status = ?", problemID, model.ProposalBatchRunning).
+		Count(&count)
+	return count > 0
+}
+
+// MarkRunningAsError 将所有进行中的建议任务标记为异常（服务重启后调用）
+func (r *TestcaseProposalRepository) MarkRunningAsError(message string) error {
+	now := time.Now()
+	return r.db.Model(&model.TestcaseProposalBatch{}).
+		Where("status = ?", model.ProposalBatchRunning).
+		Updates(map[string]interface{}{
+			"status":      model.ProposalBatchError,
+			"message":     message,
+			"finished_at": now,
+		}).Error
+}
+
+// CreateProposals 批量保存建议的测试数据
+func (r *TestcaseProposalRepository) CreateProposals(proposals []model.TestcaseProposal) error {
+	if len(proposals) == 0 {
+		return nil
+	}
+	return r.db.Create(&proposals).Error
+}
+
+// ListProposalsByBatches 获取多个建议任务的测试数据
+func (r *TestcaseProposalRepository) ListProposalsByBatches(batchIDs []uint) ([]model.TestcaseProposal, error) {
+	var proposals []model.TestcaseProposal
+	if len(batchIDs) == 0 {
+		return proposals, nil
+	}
+	if err := r.db.Where("batch_id IN ?", batchIDs).Order("id ASC").Find(&proposals).Error; err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// ListProposals 按 ID 获取题目的建议测试数据
+func (r *TestcaseProposalRepository) ListProposals(problemID uint, ids []uint) ([]model.TestcaseProposal, error) {
+	var proposals []model.TestcaseProposal
+	if err := r.db.Where("problem_id = ? AND id IN ?", problemID, ids).Order("id ASC").Find(&proposals).Error; err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// UpdateProposalStatusFrom 仅当建议测试数据仍处于 from 状态时改为 to，返回是否修改成功（并发采纳或忽略时只有一个请求成功）
+func (r *TestcaseProposalRepository) UpdateProposalStatusFrom(id uint, from, to string) (bool, error) {
+	result := r.db.Model(&model.TestcaseProposal{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
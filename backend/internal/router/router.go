@@ -30,9 +30,16 @@ func SetupRouter(mode string) *gin.Engine {
 	userHandler := handler.NewUserHandler()
 	problemHandler := handler.NewProblemHandler()
 	submissionHandler := handler.NewSubmissionHandler()
+	aiPromptHandler := handler.NewAIPromptHandler()
+	aiCallLogHandler := handler.NewAICallLogHandler()
+	aiUsageHandler := handler.NewAIUsageHandler()
+	testcaseProposalHandler := handler.NewTestcaseProposalHandler()
+	problemTranslationHandler := handler.NewProblemTranslationHandler()
+	plagiarismHandler := handler.NewPlagiarismHandler()
 	settingHandler := handler.NewSettingHandler()
 	contestHandler := handler.NewContestHandler()
 	statsHandler := handler.NewStatisticsHandler()
+	judgeHandler := handler.NewJudgeHandler()
 
 	// API v1
 	v1 := r.Group("/api/v1")
@@ -60,6 +67,26 @@ func SetupRouter(mode string) *gin.Engine {
 			problem.POST("/:id/image", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.UploadProblemImage)
 			problem.POST("/:id/testcase", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.UploadTestcase)
 			problem.POST("/:id/testcase/zip", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.UploadTestcaseZip)
+			problem.POST("/:id/testcase/generate", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.GenerateTestcases)
+			problem.GET("/:id/testcase/generations", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.ListGenerations)
+			problem.POST("/:id/testcase/ai-propose", middleware.AuthMiddleware(), middleware.AdminMiddleware(), testcaseProposalHandler.Propose)
+			problem.GET("/:id/testcase/ai-proposals", middleware.AuthMiddleware(), middleware.AdminMiddleware(), testcaseProposalHandler.List)
+			problem.POST("/:id/testcase/ai-proposals/accept", middleware.AuthMiddleware(), middleware.AdminMiddleware(), testcaseProposalHandler.Accept)
+			problem.POST("/:id/testcase/ai-proposals/reject", middleware.AuthMiddleware(), middleware.AdminMiddleware(), testcaseProposalHandler.Reject)
+			problem.GET("/:id/translations", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemTranslationHandler.List)
+			problem.POST("/:id/translations/ai", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemTranslationHandler.Translate)
+			problem.PUT("/:id/translations/:locale", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemTranslationHandler.Save)
+			problem.DELETE("/:id/translations/:locale", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemTranslationHandler.Delete)
+			problem.DELETE("/:id/translations/:locale/pending", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemTranslationHandler.DiscardPending)
+			problem.GET("/:id/programs", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.ListPrograms)
+			problem.PUT("/:id/programs/:role", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.SaveProgram)
+			problem.DELETE("/:id/programs/:role", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.DeleteProgram)
+			problem.GET("/:id/solutions", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.ListSolutions)
+			problem.POST("/:id/solutions", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.CreateSolution)
+			problem.PUT("/:id/solutions/:solution_id", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.UpdateSolution)
+			problem.DELETE("/:id/solutions/:solution_id", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.DeleteSolution)
+			problem.POST("/:id/verify", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.VerifyProblem)
+			problem.GET("/:id/verifications", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.ListVerifications)
 			problem.POST("/:id/rejudge", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.RejudgeProblem)
 			problem.GET("/:id/testcases", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.GetTestcases)
 			problem.DELETE("/:id/testcases", middleware.AuthMiddleware(), middleware.AdminMiddleware(), problemHandler.DeleteTestcases)
@@ -79,6 +106,8 @@ func SetupRouter(mode string) *gin.Engine {
 		v1.GET("/rank", userHandler.GetRankList)
 		// 统计
 		v1.GET("/statistics", statsHandler.GetPublic)
+		// 判题节点信息
+		v1.GET("/judge/info", judgeHandler.Info)
 
 		// 比赛模块
 		contest := v1.Group("/contest")
@@ -87,6 +116,14 @@ func SetupRouter(mode string) *gin.Engine {
 			contest.GET("/list", contestHandler.List)
 			contest.GET("/:id", contestHandler.GetByID)
 			contest.POST("/:id/start", contestHandler.StartContest)
+			contest.GET("/:id/leaderboard", contestHandler.GetUserLeaderboard)
+			contest.POST("/:id/lock", contestHandler.LockProblem)
+			contest.GET("/:id/locks", contestHandler.ListLocks)
+			contest.GET("/:id/hack/targets", contestHandler.ListHackTargets)
+			contest.POST("/:id/hacks", contestHandler.CreateHack)
+			contest.GET("/:id/hacks", contestHandler.ListHacks)
+			contest.GET("/:id/clarifications", contestHandler.ListClarifications)
+			contest.POST("/:id/clarifications", contestHandler.AskClarification)
 		}
 
 		// 管理模块
@@ -109,13 +146,47 @@ func SetupRouter(mode string) *gin.Engine {
 				adminEditor.POST("/contests/:id/users/:user_id/force-finish", contestHandler.ForceFinishUserContest)
 				adminEditor.GET("/contests/:id/leaderboard", contestHandler.GetLeaderboard)
 				adminEditor.GET("/contests/:id/export", contestHandler.ExportLeaderboard)
+				adminEditor.GET("/contests/:id/resolver", contestHandler.GetResolver)
+				adminEditor.POST("/contests/:id/resolver/next", contestHandler.RevealNext)
+				adminEditor.POST("/contests/:id/unfreeze", contestHandler.Unfreeze)
+				adminEditor.POST("/contests/:id/clarifications/:clarification_id/answer", contestHandler.AnswerClarification)
+				adminEditor.DELETE("/contests/:id/clarifications/:clarification_id", contestHandler.DeleteClarification)
+				adminEditor.POST("/contests/:id/announcements", contestHandler.CreateAnnouncement)
+				adminEditor.DELETE("/contests/:id/announcements/:announcement_id", contestHandler.DeleteAnnouncement)
 				adminEditor.POST("/submissions/:id/abort", submissionHandler.AbortSubmission)
 				adminEditor.DELETE("/submissions/:id", submissionHandler.DeleteSubmission)
+				adminEditor.POST("/submissions/:id/ai-review", submissionHandler.ReviewAIResult)
+				adminEditor.GET("/ai-reviews", submissionHandler.ListAIReviews)
+				adminEditor.GET("/judge/info", judgeHandler.AdminInfo)
 
 				// 系统设置
 				adminEditor.GET("/settings/ai", settingHandler.GetAISettings)
 				adminEditor.PUT("/settings/ai", settingHandler.UpdateAISettings)
 				adminEditor.POST("/settings/ai/test", settingHandler.TestAIConnection)
+				adminEditor.GET("/settings/ai/prompts", aiPromptHandler.List)
+				adminEditor.GET("/settings/ai/prompts/builtin", aiPromptHandler.Builtin)
+				adminEditor.POST("/settings/ai/prompts", aiPromptHandler.Create)
+				adminEditor.POST("/settings/ai/prompts/render", aiPromptHandler.Render)
+				adminEditor.GET("/settings/ai/prompts/:id", aiPromptHandler.GetByID)
+				adminEditor.PUT("/settings/ai/prompts/:id", aiPromptHandler.Update)
+				adminEditor.DELETE("/settings/ai/prompts/:id", aiPromptHandler.Delete)
+				adminEditor.GET("/settings/ai/prompts/:id/versions", aiPromptHandler.ListVersions)
+				adminEditor.POST("/settings/ai/prompts/:id/versions/:version/restore", aiPromptHandler.RestoreVersion)
+
+				// AI 调用审计记录
+				adminEditor.GET("/ai-logs", aiCallLogHandler.List)
+				adminEditor.GET("/ai-logs/:id", aiCallLogHandler.GetByID)
+				adminEditor.POST("/ai-logs/purge", aiCallLogHandler.Purge)
+
+				// AI 用量统计
+				adminEditor.GET("/ai-usage", aiUsageHandler.GetStats)
+
+				// 代码查重
+				adminEditor.POST("/plagiarism", plagiarismHandler.Create)
+				adminEditor.GET("/plagiarism", plagiarismHandler.List)
+				adminEditor.GET("/plagiarism/:id", plagiarismHandler.GetByID)
+				adminEditor.GET("/plagiarism/:id/pairs/:pair_id", plagiarismHandler.GetPair)
+				adminEditor.DELETE("/plagiarism/:id", plagiarismHandler.Delete)
 			}
 
 			// 仅超级管理员可访问：管理员权限变更
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+type AICallLogService struct {
+	repo *repository.AICallLogRepository
+}
+
+func NewAICallLogService() *AICallLogService {
+	return &AICallLogService{
+		repo: repository.NewAICallLogRepository(),
+	}
+}
+
+// List 分页获取 AI 调用记录
+func (s *AICallLogService) List(page, size int, submissionID, problemID uint) (*model.PageData, error) {
+	logs, total, err := s.repo.List(page, size, submissionID, problemID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageData{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  logs,
+	}, nil
+}
+
+// GetByID 获取 AI 调用记录详情
+func (s *AICallLogService) GetByID(id uint) (*model.AICallLog, error) {
+	entry, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("调用记录不存在")
+	}
+	return entry, nil
+}
+
+// PurgeExpired 按保留天数删除过期的调用记录，返回删除条数
+func (s *AICallLogService) PurgeExpired() (int64, error) {
+	days := GetSettingService().GetAISettings().LogRetentionDays
+	if days <= 0 {
+		return 0, nil
+	}
+	deleted, err := s.repo.DeleteBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Printf("[AI] 已清理 %d 天前的调用记录 %d 条", days, deleted)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+type AIPromptService struct {
+	repo        *repository.AIPromptRepository
+	problemRepo *repository.ProblemRepository
+}
+
+func NewAIPromptService() *AIPromptService {
+	return &AIPromptService{
+		repo:        repository.NewAIPromptRepository(),
+		problemRepo: repository.NewProblemRepository(),
+	}
+}
+
+// List 获取全部提示词模板
+func (s *AIPromptService) List() ([]model.AIPromptTemplate, error) {
+	return s.repo.List()
+}
+
+// GetByID 获取提示词模板
+func (s *AIPromptService) GetByID(id uint) (*model.AIPromptTemplate, error) {
+	tpl, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("提示词模板不存在")
+	}
+	return tpl, nil
+}
+
+// Create 创建提示词模板（模板语法由调用方预先校验）
+func (s *AIPromptService) Create(req *model.SaveAIPromptTemplateRequest, userID uint) (*model.AIPromptTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if s.repo.ExistsByName(name, 0) {
+		return nil, errors.New("模板名称已存在")
+	}
+	tpl := &model.AIPromptTemplate{
+		Name:         name,
+		Description:  req.Description,
+		SystemPrompt: req.SystemPrompt,
+		UserPrompt:   req.UserPrompt,
+		UpdatedBy:    userID,
+	}
+	if err := s.repo.Create(tpl, req.Comment); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Update 修改提示词模板，生成新版本；内容未变化时只更新名称与说明
+func (s *AIPromptService) Update(id uint, req *model.SaveAIPromptTemplateRequest, userID uint) (*model.AIPromptTemplate, error) {
+	tpl, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if s.repo.ExistsByName(name, id) {
+		return nil, errors.New("模板名称已存在")
+	}
+
+	contentChanged := tpl.SystemPrompt != req.SystemPrompt || tpl.UserPrompt != req.UserPrompt
+	tpl.Name = name
+	tpl.Description = req.Description
+	if !contentChanged {
+		if err := s.repo.UpdateInfo(tpl); err != nil {
+			return nil, err
+		}
+		return tpl, nil
+	}
+
+	tpl.SystemPrompt = req.SystemPrompt
+	tpl.UserPrompt = req.UserPrompt
+	tpl.UpdatedBy = userID
+	if err := s.repo.Update(tpl, req.Comment); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Delete 删除提示词模板；系统默认模板与仍被题目引用的模板不能删除
+func (s *AIPromptService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if GetSettingService().GetAISettings().PromptTemplateID == id {
+		return errors.New("该模板为系统默认模板，请先在 AI 设置中更换默认模板")
+	}
+	problemIDs, err := s.problemRepo.ListIDsByPromptTemplate(id)
+	if err != nil {
+		return errors.New("检查模板引用失败")
+	}
+	if len(problemIDs) > 0 {
+		return fmt.Errorf("有 %d 道题目正在使用该模板（%s），请先修改这些题目的 AI 判题配置", len(problemIDs), formatProblemIDs(problemIDs, 5))
+	}
+	return s.repo.Delete(id)
+}
+
+// formatProblemIDs 列出前 limit 个题目 ID，如 "#1、#2 等"
+func formatProblemIDs(ids []uint, limit int) string {
+	parts := make([]string, 0, limit)
+	for i, id := range ids {
+		if i == limit {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("#%d", id))
+	}
+	text := strings.Join(parts, "、")
+	if len(ids) > limit {
+		text += " 等"
+	}
+	return text
+}
+
+// ListVersions 获取模板的历史版本
+func (s *AIPromptService) ListVersions(id uint) ([]model.AIPromptTemplateVersion, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(id)
+}
+
+// Restore 将模板内容恢复为指定历史版本（作为新版本保存，历史不被覆盖）
+func (s *AIPromptService) Restore(id uint, version int, userID uint) (*model.AIPromptTemplate, error) {
+	tpl, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.repo.GetVersion(id, version)
+	if err != nil {
+		return nil, errors.New("版本不存在")
+	}
+	if old.Version == tpl.Version {
+		return nil, errors.New("已是当前版本")
+	}
+
+	tpl.SystemPrompt = old.SystemPrompt
+	tpl.UserPrompt = old.UserPrompt
+	tpl.UpdatedBy = userID
+	if err := s.repo.Update(tpl, fmt.Sprintf("恢复自版本 %d", version)); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const aiUsageDateLayout = "2006-01-02"
+
+type AIUsageService struct {
+	repo        *repository.AIUsageRepository
+	contestRepo *repository.ContestRepository
+}
+
+func NewAIUsageService() *AIUsageService {
+	return &AIUsageService{
+		repo:        repository.NewAIUsageRepository(),
+		contestRepo: repository.NewContestRepository(),
+	}
+}
+
+// Record 记录一次模型调用的用量；提交时正在进行且包含该题的比赛计入比赛用量
+func (s *AIUsageService) Record(problemID uint, submittedAt time.Time, promptTokens, completionTokens int) {
+	contestID := s.contestAt(problemID, submittedAt)
+	date := time.Now().Format(aiUsageDateLayout)
+	if err := s.repo.Add(date, problemID, contestID, int64(promptTokens), int64(completionTokens)); err != nil {
+		log.Printf("[AI] 记录用量失败: %v", err)
+	}
+}
+
+func (s *AIUsageService) contestAt(problemID uint, at time.Time) uint {
+	contests, err := s.contestRepo.ListRunningAt(at)
+	if err != nil {
+		return 0
+	}
+	for _, contest := range contests {
+		if containsUint(contest.ProblemIDs, problemID) {
+			return contest.ID
+		}
+	}
+	return 0
+}
+
+// monthRange 返回 t 所在自然月的首日与末日
+func monthRange(t time.Time) (string, string) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return first.Format(aiUsageDateLayout), first.AddDate(0, 1, -1).Format(aiUsageDateLayout)
+}
+
+// GetBudgetStatus 本月 token 预算使用情况
+func (s *AIUsageService) GetBudgetStatus(settings *model.AISettings) (*model.AIBudgetStatus, error) {
+	now := time.Now()
+	from, to := monthRange(now)
+	used, err := s.repo.SumTokens(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AIBudgetStatus{
+		Month:    now.Format("2006-01"),
+		Budget:   settings.MonthlyTokenBudget,
+		Used:     used,
+		Exceeded: settings.MonthlyTokenBudget > 0 && used >= settings.MonthlyTokenBudget,
+		Policy:   settings.BudgetPolicy,
+	}, nil
+}
+
+// GetStats 统计日期区间内的用量（按天、题目、比赛），未指定时为本月
+func (s *AIUsageService) GetStats(from, to string) (*model.AIUsageStats, error) {
+	if from == "" || to == "" {
+		monthFrom, monthTo := monthRange(time.Now())
+		if from == "" {
+			from = monthFrom
+		}
+		if to == "" {
+			to = monthTo
+		}
+	}
+	fromDate, err := time.Parse(aiUsageDateLayout, from)
+	if err != nil {
+		return nil, errors.New("开始日期格式应为 YYYY-MM-DD")
+	}
+	toDate, err := time.Parse(aiUsageDateLayout, to)
+	if err != nil {
+		return nil, errors.New("结束日期格式应为 YYYY-MM-DD")
+	}
+	if toDate.Before(fromDate) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+
+	stats := &model.AIUsageStats{From: from, To: to}
+	totals, err := s.repo.Totals(from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats.Totals = *totals
+	if stats.Daily, err = s.repo.ListDaily(from, to); err != nil {
+		return nil, err
+	}
+	if stats.Problems, err = s.repo.ListByProblem(from, to); err != nil {
+		return nil, err
+	}
+	if stats.Contests, err = s.repo.ListByContest(from, to); err != nil {
+		return nil, err
+	}
+	budget, err := s.GetBudgetStatus(GetSettingService().GetAISettings())
+	if err != nil {
+		return nil, err
+	}
+	stats.Budget = *budget
+	return stats, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const (
+	maxClarificationQuestionLength = 1000
+	maxClarificationAnswerLength   = 2000
+	maxPendingClarifications       = 5 // 每位选手同时等待回复的提问数上限
+)
+
+type ClarificationService struct {
+	repo              *repository.ClarificationRepository
+	contestRepo       *repository.ContestRepository
+	userRepo          *repository.UserRepository
+	participationRepo *repository.ContestParticipationRepository
+}
+
+func NewClarificationService() *ClarificationService {
+	return &ClarificationService{
+		repo:              repository.NewClarificationRepository(),
+		contestRepo:       repository.NewContestRepository(),
+		userRepo:          repository.NewUserRepository(),
+		participationRepo: repository.NewContestParticipationRepository(),
+	}
+}
+
+// Ask 选手在自己的赛时区间内提问（按时间窗口计时的比赛为个人窗口），problemID 为 0 时针对整场比赛
+func (s *ClarificationService) Ask(contestID, userID uint, req *model.ClarificationCreateRequest) (*model.ContestClarification, error) {
+	contest, err := s.loadAccessibleContest(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	liveStart, liveEnd, active := getUserContestLiveWindow(s.participationRepo, contest, userID)
+	if !active {
+		return nil, errors.New("请先开始比赛")
+	}
+	if now.Before(liveStart) {
+		return nil, errors.New("比赛尚未开始")
+	}
+	if !now.Before(liveEnd) {
+		return nil, errors.New("比赛已结束，无法提问")
+	}
+	if req.ProblemID > 0 && !containsUint([]uint(contest.ProblemIDs), req.ProblemID) {
+		return nil, errors.New("题目不在比赛中")
+	}
+
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, errors.New("问题不能为空")
+	}
+	if utf8.RuneCountInString(question) > maxClarificationQuestionLength {
+		return nil, fmt.Errorf("问题不能超过 %d 字", maxClarificationQuestionLength)
+	}
+	if s.repo.CountUnanswered(contestID, userID) >= maxPendingClarifications {
+		return nil, fmt.Errorf("最多同时有 %d 个等待回复的提问", maxPendingClarifications)
+	}
+
+	clarification := &model.ContestClarification{
+		ContestID:  contestID,
+		ProblemID:  req.ProblemID,
+		UserID:     userID,
+		Question:   question,
+		Visibility: model.ClarificationVisibilityPrivate,
+	}
+	if err := s.repo.Create(clarification); err != nil {
+		return nil, errors.New("提问失败")
+	}
+	clarification.IsMine = true
+	return clarification, nil
+}
+
+// List 获取比赛的公告与提问
+func (s *ClarificationService) List(contestID, userID uint, isAdmin bool) ([]model.ContestAnnouncement, []model.ContestClarification, error) {
+	var contest *model.Contest
+	var err error
+	if isAdmin {
+		if contest, err = s.contestRepo.GetByID(contestID); err != nil {
+			return nil, nil, errors.New("比赛不存在")
+		}
+	} else if contest, err = s.loadAccessibleContest(contestID, userID); err != nil {
+		return nil, nil, err
+	}
+	return s.ListForContest(contest, userID, isAdmin)
+}
+
+// ListForContest 获取比赛的公告与提问（调用方已校验比赛访问权限）：
+// 管理员看到全部提问；选手只看到自己的提问与公开的回复，他人的提问不显示提问人
+func (s *ClarificationService) ListForContest(contest *model.Contest, userID uint, isAdmin bool) ([]model.ContestAnnouncement, []model.ContestClarification, error) {
+	announcements, err := s.repo.ListAnnouncements(contest.ID)
+	if err != nil {
+		return nil, nil, errors.New("获取比赛公告失败")
+	}
+
+	viewerID := userID
+	if isAdmin {
+		viewerID = 0
+	}
+	clarifications, err := s.repo.ListByContest(contest.ID, viewerID)
+	if err != nil {
+		return nil, nil, errors.New("获取比赛答疑失败")
+	}
+	for i := range clarifications {
+		clarifications[i].IsMine = clarifications[i].UserID == userID
+		if !isAdmin && !clarifications[i].IsMine {
+			clarifications[i].UserID = 0
+			clarifications[i].Username = ""
+		}
+	}
+	return announcements, clarifications, nil
+}
+
+// Answer 回复提问：private 仅提问者可见，public 广播给全体参赛者；重复回复会覆盖之前的回复
+func (s *ClarificationService) Answer(contestID, id, adminID uint, req *model.ClarificationAnswerRequest) (*model.ContestClarification, error) {
+	clarification, err := s.repo.GetByID(contestID, id)
+	if err != nil {
+		return nil, errors.New("提问不存在")
+	}
+
+	answer := strings.TrimSpace(req.Answer)
+	if answer == "" {
+		return nil, errors.New("回复不能为空")
+	}
+	if utf8.RuneCountInString(answer) > maxClarificationAnswerLength {
+		return nil, fmt.Errorf("回复不能超过 %d 字", maxClarificationAnswerLength)
+	}
+	visibility := strings.ToLower(strings.TrimSpace(req.Visibility))
+	switch visibility {
+	case "":
+		visibility = model.ClarificationVisibilityPrivate
+	case model.ClarificationVisibilityPrivate, model.ClarificationVisibilityPublic:
+	default:
+		return nil, errors.New("无效的回复可见范围")
+	}
+
+	now := time.Now()
+	clarification.Answer = answer
+	clarification.Visibility = visibility
+	clarification.AnsweredBy = adminID
+	clarification.AnsweredAt = &now
+	if err := s.repo.Save(clarification); err != nil {
+		return nil, errors.New("回复失败")
+	}
+	return clarification, nil
+}
+
+// Delete 删除提问
+func (s *ClarificationService) Delete(contestID, id uint) error {
+	deleted, err := s.repo.Delete(contestID, id)
+	if err != nil {
+		return errors.New("删除提问失败")
+	}
+	if !deleted {
+		return errors.New("提问不存在")
+	}
+	return nil
+}
+
+// CreateAnnouncement 发布比赛公告
+func (s *ClarificationService) CreateAnnouncement(contestID, adminID uint, req *model.AnnouncementCreateRequest) (*model.ContestAnnouncement, error) {
+	if _, err := s.contestRepo.GetByID(contestID); err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("公告标题不能为空")
+	}
+
+	announcement := &model.ContestAnnouncement{
+		ContestID: contestID,
+		Title:     title,
+		Content:   strings.TrimSpace(req.Content),
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateAnnouncement(announcement); err != nil {
+		return nil, errors.New("发布公告失败")
+	}
+	return announcement, nil
+}
+
+// DeleteAnnouncement 删除比赛公告
+func (s *ClarificationService) DeleteAnnouncement(contestID, id uint) error {
+	deleted, err := s.repo.DeleteAnnouncement(contestID, id)
+	if err != nil {
+		return errors.New("删除公告失败")
+	}
+	if !deleted {
+		return errors.New("公告不存在")
+	}
+	return nil
+}
+
+// loadAccessibleContest 校验比赛存在且用户在参赛范围内
+func (s *ClarificationService) loadAccessibleContest(contestID, userID uint) (*model.Contest, error) {
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !canAccessContest(contest, userID, user.Group) {
+		return nil, errors.New("无权限访问该比赛")
+	}
+	return contest, nil
+}
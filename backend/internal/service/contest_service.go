@@ -1,21 +1,31 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"oj-system/internal/model"
 	"oj-system/internal/repository"
 )
 
+// contestRevealMu 串行执行封榜揭晓（读取进度 → 计算下一格 → 写回），避免连续点击时重复揭晓同一格或丢失进度
+var contestRevealMu sync.Mutex
+
 type ContestService struct {
 	contestRepo       *repository.ContestRepository
 	problemRepo       *repository.ProblemRepository
 	userRepo          *repository.UserRepository
 	submissionRepo    *repository.SubmissionRepository
 	participationRepo *repository.ContestParticipationRepository
+	hackRepo          *repository.HackRepository
+	clarificationRepo *repository.ClarificationRepository
 }
 
 func NewContestService() *ContestService {
@@ -25,6 +35,8 @@ func NewContestService() *ContestService {
 		userRepo:          repository.NewUserRepository(),
 		submissionRepo:    repository.NewSubmissionRepository(),
 		participationRepo: repository.NewContestParticipationRepository(),
+		hackRepo:          repository.NewHackRepository(),
+		clarificationRepo: repository.NewClarificationRepository(),
 	}
 }
 
@@ -38,6 +50,13 @@ func (s *ContestService) Create(req *model.ContestCreateRequest, createdBy uint)
 	if timingMode != contestTimingWindow {
 		durationMinutes = 0
 	}
+	if err := validateFreezeMinutes(req.FreezeMinutes, req.StartAt, req.EndAt, timingMode, durationMinutes); err != nil {
+		return nil, err
+	}
+	visibility, err := normalizeLeaderboardVisibility(req.LeaderboardVisibility)
+	if err != nil {
+		return nil, err
+	}
 
 	problemIDs := uniqueUintList(req.ProblemIDs)
 	if err := s.validateProblemIDs(problemIDs); err != nil {
@@ -50,19 +69,29 @@ func (s *ContestService) Create(req *model.ContestCreateRequest, createdBy uint)
 		return nil, errors.New("请至少选择一个参赛用户或分组")
 	}
 
+	aliasSalt, err := randomHex(32)
+	if err != nil {
+		return nil, errors.New("创建比赛失败")
+	}
+
 	contest := &model.Contest{
-		Title:           strings.TrimSpace(req.Title),
-		Description:     strings.TrimSpace(req.Description),
-		Type:            strings.ToLower(strings.TrimSpace(req.Type)),
-		TimingMode:      timingMode,
-		DurationMinutes: durationMinutes,
-		SubmissionLimit: submissionLimit,
-		StartAt:         req.StartAt,
-		EndAt:           req.EndAt,
-		ProblemIDs:      model.UintList(problemIDs),
-		AllowedUsers:    model.UintList(allowedUsers),
-		AllowedGroups:   model.StringList(allowedGroups),
-		CreatedBy:       createdBy,
+		Title:                 strings.TrimSpace(req.Title),
+		Description:           strings.TrimSpace(req.Description),
+		Type:                  strings.ToLower(strings.TrimSpace(req.Type)),
+		TimingMode:            timingMode,
+		DurationMinutes:       durationMinutes,
+		SubmissionLimit:       submissionLimit,
+		HackEnabled:           req.HackEnabled,
+		FreezeMinutes:         req.FreezeMinutes,
+		LeaderboardVisibility: visibility,
+		AnonymizeLeaderboard:  req.AnonymizeLeaderboard,
+		AliasSalt:             aliasSalt,
+		StartAt:               req.StartAt,
+		EndAt:                 req.EndAt,
+		ProblemIDs:            model.UintList(problemIDs),
+		AllowedUsers:          model.UintList(allowedUsers),
+		AllowedGroups:         model.StringList(allowedGroups),
+		CreatedBy:             createdBy,
 	}
 
 	if err := s.contestRepo.Create(contest); err != nil {
@@ -82,6 +111,13 @@ func (s *ContestService) Update(id uint, req *model.ContestUpdateRequest) (*mode
 	if timingMode != contestTimingWindow {
 		durationMinutes = 0
 	}
+	if err := validateFreezeMinutes(req.FreezeMinutes, req.StartAt, req.EndAt, timingMode, durationMinutes); err != nil {
+		return nil, err
+	}
+	visibility, err := normalizeLeaderboardVisibility(req.LeaderboardVisibility)
+	if err != nil {
+		return nil, err
+	}
 
 	contest, err := s.contestRepo.GetByID(id)
 	if err != nil {
@@ -105,6 +141,15 @@ func (s *ContestService) Update(id uint, req *model.ContestUpdateRequest) (*mode
 	contest.TimingMode = timingMode
 	contest.DurationMinutes = durationMinutes
 	contest.SubmissionLimit = submissionLimit
+	contest.HackEnabled = req.HackEnabled
+	if contest.FreezeMinutes != req.FreezeMinutes {
+		// 封榜时长变化后重新封榜，揭晓进度作废
+		contest.FreezeMinutes = req.FreezeMinutes
+		contest.RevealedCells = nil
+		contest.UnfrozenAt = nil
+	}
+	contest.LeaderboardVisibility = visibility
+	contest.AnonymizeLeaderboard = req.AnonymizeLeaderboard
 	contest.StartAt = req.StartAt
 	contest.EndAt = req.EndAt
 	contest.ProblemIDs = model.UintList(problemIDs)
@@ -122,6 +167,12 @@ func (s *ContestService) Delete(id uint) error {
 	if err := s.contestRepo.Delete(id); err != nil {
 		return errors.New("删除比赛失败")
 	}
+	if err := s.hackRepo.DeleteByContest(id); err != nil {
+		return errors.New("删除比赛 hack 记录失败")
+	}
+	if err := s.clarificationRepo.DeleteByContest(id); err != nil {
+		return errors.New("删除比赛答疑记录失败")
+	}
 	return nil
 }
 
@@ -353,6 +404,29 @@ func (s *ContestService) CountUserLiveSubmissions(contest *model.Contest, userID
 	return count, nil
 }
 
+// IsContestSubmission 判断提交是否属于进行中比赛的赛时提交（用于比赛期间关闭 AI 学习提示等辅助功能）。
+// 按赛时阶段判定：比赛进行中只有个人窗口已结束的补题提交不算；查询失败时按赛时处理
+func (s *ContestService) IsContestSubmission(problemID, userID uint, submittedAt time.Time) bool {
+	contests, err := s.contestRepo.ListRunningAt(submittedAt)
+	if err != nil {
+		return true
+	}
+	for i := range contests {
+		contest := &contests[i]
+		if !containsUint([]uint(contest.ProblemIDs), problemID) {
+			continue
+		}
+		participation := model.ContestParticipation{}
+		if p, err := s.participationRepo.GetByContestAndUser(contest.ID, userID); err == nil && p != nil {
+			participation = *p
+		}
+		if classifySubmissionPhase(contest, participation, submittedAt) != leaderboardPhasePost {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ContestService) ListForUser(page, size int, userID uint, isAdmin bool) ([]model.ContestListItem, int64, error) {
 	if isAdmin {
 		contests, total, err := s.contestRepo.List(page, size)
@@ -399,6 +473,7 @@ func (s *ContestService) GetProblemsByIDs(ids []uint) ([]model.Problem, error) {
 	return s.problemRepo.GetByIDs(ids)
 }
 
+// GetLeaderboard 获取比赛排行榜（实时数据，不受封榜影响）
 func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*model.Contest, []uint, []model.ContestLeaderboardEntry, string, error) {
 	contest, err := s.contestRepo.GetByID(contestID)
 	if err != nil {
@@ -407,19 +482,34 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 	contest.SubmissionLimit = normalizeContestSubmissionLimit(contest.SubmissionLimit)
 	boardMode = normalizeLeaderboardMode(boardMode)
 
+	entries, err := s.buildLeaderboard(contest, boardMode, nil)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	return contest, []uint(contest.ProblemIDs), entries, boardMode, nil
+}
+
+// leaderboardFreeze 封榜视图：封榜期间的赛时提交（查看者本人与已揭晓的格子除外）不计入结果，只计为待揭晓
+type leaderboardFreeze struct {
+	viewerID uint
+	revealed map[string]bool
+}
+
+func (s *ContestService) buildLeaderboard(contest *model.Contest, boardMode string, freeze *leaderboardFreeze) ([]model.ContestLeaderboardEntry, error) {
 	problemIDs := []uint(contest.ProblemIDs)
 	submissions, err := s.submissionRepo.ListForContestSince(problemIDs, contest.StartAt)
 	if err != nil {
-		return nil, nil, nil, "", errors.New("获取提交记录失败")
+		return nil, errors.New("获取提交记录失败")
 	}
 	now := time.Now()
 	isWindowMode := normalizeContestTimingMode(contest.TimingMode) == contestTimingWindow
+	isACM := strings.ToLower(contest.Type) == contestTypeACM
 
 	participationMap := map[uint]model.ContestParticipation{}
 	participationUserIDs := make([]uint, 0)
-	participations, err := s.participationRepo.ListByContest(contestID)
+	participations, err := s.participationRepo.ListByContest(contest.ID)
 	if err != nil {
-		return nil, nil, nil, "", errors.New("获取比赛会话失败")
+		return nil, errors.New("获取比赛会话失败")
 	}
 	for _, participation := range participations {
 		participationMap[participation.UserID] = participation
@@ -432,6 +522,8 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 		group         string
 		liveScores    map[uint]int
 		postScores    map[uint]int
+		acmRuns       map[uint][]acmRun
+		frozen        map[uint]int
 		participation *model.ContestParticipation
 	}
 
@@ -439,7 +531,7 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 	if len(participationUserIDs) > 0 {
 		users, err := s.userRepo.GetByIDs(uniqueUintList(participationUserIDs))
 		if err != nil {
-			return nil, nil, nil, "", errors.New("获取参赛用户失败")
+			return nil, errors.New("获取参赛用户失败")
 		}
 		for _, user := range users {
 			participation := participationMap[user.ID]
@@ -450,6 +542,8 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 				group:         user.Group,
 				liveScores:    make(map[uint]int),
 				postScores:    make(map[uint]int),
+				acmRuns:       make(map[uint][]acmRun),
+				frozen:        make(map[uint]int),
 				participation: &participationCopy,
 			}
 		}
@@ -476,6 +570,8 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 				group:         sub.Group,
 				liveScores:    make(map[uint]int),
 				postScores:    make(map[uint]int),
+				acmRuns:       make(map[uint][]acmRun),
+				frozen:        make(map[uint]int),
 				participation: participation,
 			}
 			userMap[sub.UserID] = entry
@@ -489,14 +585,39 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 		}
 
 		phase := classifySubmissionPhase(contest, participationMap[sub.UserID], sub.CreatedAt)
+		if freeze != nil && sub.UserID != freeze.viewerID {
+			// 封榜期间他人的赛后提交同样不公布
+			if phase == leaderboardPhasePost {
+				continue
+			}
+			if phase == leaderboardPhaseLive &&
+				!sub.CreatedAt.Before(contestFreezeStart(contest, participationMap[sub.UserID])) &&
+				!freeze.revealed[revealCellKey(sub.UserID, sub.ProblemID)] {
+				entry.frozen[sub.ProblemID]++
+				continue
+			}
+		}
 		if phase == leaderboardPhaseLive {
 			entry.liveScores[sub.ProblemID] = sub.Score
 		} else if phase == leaderboardPhasePost {
 			entry.postScores[sub.ProblemID] = sub.Score
 		}
+		if isACM && phase != leaderboardPhaseIgnore {
+			// 罚时从开赛（窗口期为个人开始）算起
+			penaltyStart := contest.StartAt
+			if isWindowMode && entry.participation != nil {
+				penaltyStart = entry.participation.StartAt
+			}
+			entry.acmRuns[sub.ProblemID] = append(entry.acmRuns[sub.ProblemID], acmRun{
+				status:  sub.Status,
+				elapsed: sub.CreatedAt.Sub(penaltyStart),
+				live:    phase == leaderboardPhaseLive,
+			})
+		}
 	}
 
 	entries := make([]model.ContestLeaderboardEntry, 0, len(userMap))
+	acmFirstAC := make(map[uint][]time.Duration, len(userMap))
 	for _, entry := range userMap {
 		liveScores := make([]int, 0, len(problemIDs))
 		postScores := make([]int, 0, len(problemIDs))
@@ -542,7 +663,7 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 			}
 		}
 
-		entries = append(entries, model.ContestLeaderboardEntry{
+		leaderboardEntry := model.ContestLeaderboardEntry{
 			UserID:         entry.userID,
 			Username:       entry.username,
 			Group:          entry.group,
@@ -554,7 +675,27 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 			PostScores:     postScores,
 			StartedAt:      startedAt,
 			ElapsedSeconds: elapsedSeconds,
-		})
+		}
+		if isACM {
+			acmFirstAC[entry.userID] = fillACMCells(&leaderboardEntry, problemIDs, entry.acmRuns)
+		}
+		if freeze != nil {
+			leaderboardEntry.Frozen = make([]int, len(problemIDs))
+			for i, pid := range problemIDs {
+				// ACM 封榜前已通过的题，之后的提交不影响结果，无需揭晓
+				if isACM && leaderboardEntry.Problems[i].Accepted {
+					continue
+				}
+				leaderboardEntry.Frozen[i] = entry.frozen[pid]
+			}
+		}
+		entries = append(entries, leaderboardEntry)
+	}
+
+	if isACM {
+		markACMFirstBlood(entries, acmFirstAC, len(problemIDs))
+		sortACMEntries(entries, boardMode)
+		return entries, nil
 	}
 
 	sort.Slice(entries, func(i, j int) bool {
@@ -573,7 +714,7 @@ func (s *ContestService) GetLeaderboard(contestID uint, boardMode string) (*mode
 		return entries[i].Total > entries[j].Total
 	})
 
-	return contest, problemIDs, entries, boardMode, nil
+	return entries, nil
 }
 
 // RefreshStats 刷新比赛相关的统计数据
@@ -645,6 +786,13 @@ func (s *ContestService) validateProblemIDs(ids []uint) error {
 }
 
 const (
+	contestTypeOI  = "oi"
+	contestTypeIOI = "ioi"
+	contestTypeACM = "acm"
+
+	// acmRejectPenaltyMinutes ACM 赛制中通过前每次被拒的罚时
+	acmRejectPenaltyMinutes = 20
+
 	contestTimingFixed        = "fixed"
 	contestTimingWindow       = "window"
 	contestSubmissionLimitMax = 99
@@ -663,7 +811,7 @@ func validateContestRequest(title, contestType string, startAt, endAt time.Time,
 		return errors.New("标题不能为空")
 	}
 	contestType = strings.ToLower(strings.TrimSpace(contestType))
-	if contestType != "oi" && contestType != "ioi" {
+	if contestType != contestTypeOI && contestType != contestTypeIOI && contestType != contestTypeACM {
 		return errors.New("无效的赛制类型")
 	}
 	if timingMode != contestTimingFixed && timingMode != contestTimingWindow {
@@ -678,6 +826,20 @@ func validateContestRequest(title, contestType string, startAt, endAt time.Time,
 	return nil
 }
 
+func validateFreezeMinutes(freezeMinutes int, startAt, endAt time.Time, timingMode string, durationMinutes int) error {
+	if freezeMinutes < 0 {
+		return errors.New("封榜时长不能为负数")
+	}
+	length := int(endAt.Sub(startAt) / time.Minute)
+	if timingMode == contestTimingWindow {
+		length = durationMinutes
+	}
+	if freezeMinutes >= length && freezeMinutes > 0 {
+		return errors.New("封榜时长必须小于比赛时长")
+	}
+	return nil
+}
+
 func normalizeContestTimingMode(mode string) string {
 	mode = strings.ToLower(strings.TrimSpace(mode))
 	if mode == contestTimingWindow {
@@ -686,6 +848,19 @@ func normalizeContestTimingMode(mode string) string {
 	return contestTimingFixed
 }
 
+func normalizeLeaderboardVisibility(visibility string) (string, error) {
+	visibility = strings.ToLower(strings.TrimSpace(visibility))
+	switch visibility {
+	case "":
+		return model.LeaderboardVisibilityAlways, nil
+	case model.LeaderboardVisibilityAlways, model.LeaderboardVisibilityAfterEnd,
+		model.LeaderboardVisibilityNever, model.LeaderboardVisibilityOwnRank:
+		return visibility, nil
+	default:
+		return "", errors.New("无效的榜单可见性")
+	}
+}
+
 func normalizeContestSubmissionLimit(limit int) int {
 	return contestSubmissionLimitMax
 }
@@ -729,6 +904,7 @@ func buildContestListItems(contests []model.Contest) []model.ContestListItem {
 			TimingMode:      normalizeContestTimingMode(contest.TimingMode),
 			DurationMinutes: contest.DurationMinutes,
 			SubmissionLimit: normalizeContestSubmissionLimit(contest.SubmissionLimit),
+			FreezeMinutes:   contest.FreezeMinutes,
 			StartAt:         contest.StartAt,
 			EndAt:           contest.EndAt,
 			ProblemCount:    len(contest.ProblemIDs),
@@ -831,3 +1007,382 @@ func containsString(list []string, value string) bool {
 	}
 	return false
 }
+
+// acmRun ACM 排行榜统计用的一次提交
+type acmRun struct {
+	status  string
+	elapsed time.Duration // 距开赛（窗口期为个人开始）的时长
+	live    bool
+}
+
+// fillACMCells 按提交记录填写 ACM 各题结果、通过题数与罚时，返回各题首次通过的用时（未通过为 -1），用于判定一血
+func fillACMCells(entry *model.ContestLeaderboardEntry, problemIDs []uint, runs map[uint][]acmRun) []time.Duration {
+	entry.Problems = make([]model.ContestACMCell, len(problemIDs))
+	firstAC := make([]time.Duration, len(problemIDs))
+	for i, pid := range problemIDs {
+		firstAC[i] = -1
+		cell := &entry.Problems[i]
+		for _, run := range runs[pid] {
+			if !run.live {
+				if !cell.Accepted && run.status == model.StatusAccepted {
+					cell.PostAccepted = true
+				}
+				continue
+			}
+			if cell.Accepted {
+				continue
+			}
+			switch run.status {
+			case model.StatusPending, model.StatusJudging:
+				cell.Pending++
+			case model.StatusCompileError, model.StatusSystemError:
+				// 编译错误与系统错误不计入尝试次数
+			case model.StatusAccepted:
+				cell.Attempts++
+				cell.Accepted = true
+				cell.Minutes = int(run.elapsed / time.Minute)
+				cell.Penalty = cell.Minutes + (cell.Attempts-1)*acmRejectPenaltyMinutes
+				firstAC[i] = run.elapsed
+			default:
+				cell.Attempts++
+			}
+		}
+		if cell.Accepted {
+			// 通过之后的提交不影响结果
+			cell.Pending = 0
+			entry.Solved++
+			entry.Penalty += cell.Penalty
+		}
+		if cell.Accepted || cell.PostAccepted {
+			entry.PostSolved++
+		}
+	}
+	return firstAC
+}
+
+// markACMFirstBlood 标记每题用时最短的通过（同时通过的均标记）
+func markACMFirstBlood(entries []model.ContestLeaderboardEntry, firstAC map[uint][]time.Duration, problemCount int) {
+	for i := 0; i < problemCount; i++ {
+		best := time.Duration(-1)
+		for _, times := range firstAC {
+			if times[i] >= 0 && (best < 0 || times[i] < best) {
+				best = times[i]
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		for j := range entries {
+			if firstAC[entries[j].UserID][i] == best {
+				entries[j].Problems[i].FirstBlood = true
+			}
+		}
+	}
+}
+
+// sortACMEntries ACM 排名：通过题数多者在前，相同时罚时少者在前；赛后榜以计入订正的通过题数为先
+func sortACMEntries(entries []model.ContestLeaderboardEntry, boardMode string) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if boardMode == leaderboardModePost && a.PostSolved != b.PostSolved {
+			return a.PostSolved > b.PostSolved
+		}
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		if a.Penalty != b.Penalty {
+			return a.Penalty < b.Penalty
+		}
+		if boardMode == leaderboardModeCombined && a.PostSolved != b.PostSolved {
+			return a.PostSolved > b.PostSolved
+		}
+		return a.UserID < b.UserID
+	})
+}
+
+// GetFrozenLeaderboard 获取选手看到的赛时榜单：封榜期间他人的结果不公布（查看者本人的结果照常计入），
+// viewerID 为 0 时所有人的结果均封榜（颁奖揭晓用）
+func (s *ContestService) GetFrozenLeaderboard(contestID, viewerID uint) (*model.Contest, []model.ContestLeaderboardEntry, *model.ContestFreezeState, error) {
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, nil, nil, errors.New("比赛不存在")
+	}
+	contest.SubmissionLimit = normalizeContestSubmissionLimit(contest.SubmissionLimit)
+
+	var freeze *leaderboardFreeze
+	if contestFreezeActive(contest) {
+		freeze = newLeaderboardFreeze(contest, viewerID)
+	}
+	entries, err := s.buildLeaderboard(contest, leaderboardModeLive, freeze)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return contest, entries, buildContestFreezeState(contest, entries), nil
+}
+
+// GetFreezeState 获取比赛的封榜状态（待揭晓格子数按全部封榜的榜单统计）
+func (s *ContestService) GetFreezeState(contest *model.Contest) (*model.ContestFreezeState, error) {
+	if !contestFreezeActive(contest) {
+		return buildContestFreezeState(contest, nil), nil
+	}
+	entries, err := s.buildLeaderboard(contest, leaderboardModeLive, newLeaderboardFreeze(contest, 0))
+	if err != nil {
+		return nil, err
+	}
+	return buildContestFreezeState(contest, entries), nil
+}
+
+// RevealNext 按颁奖揭晓顺序揭晓下一格：从当前排名最靠后、仍有待揭晓结果的用户开始，揭晓其最靠前的一题。
+// 全部揭晓后解除封榜；返回揭晓的格子（已无待揭晓时为 nil）与揭晓后的榜单
+func (s *ContestService) RevealNext(contestID uint) (*model.ContestRevealStep, *model.Contest, []model.ContestLeaderboardEntry, *model.ContestFreezeState, error) {
+	contestRevealMu.Lock()
+	defer contestRevealMu.Unlock()
+
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, nil, nil, nil, errors.New("比赛不存在")
+	}
+	if err := checkContestRevealable(contest); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	problemIDs := []uint(contest.ProblemIDs)
+	before, err := s.buildLeaderboard(contest, leaderboardModeLive, newLeaderboardFreeze(contest, 0))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var step *model.ContestRevealStep
+	for rank := len(before) - 1; rank >= 0 && step == nil; rank-- {
+		for i, count := range before[rank].Frozen {
+			if count > 0 {
+				step = &model.ContestRevealStep{
+					UserID:       before[rank].UserID,
+					Username:     before[rank].Username,
+					ProblemID:    problemIDs[i],
+					ProblemIndex: i,
+					RankBefore:   rank + 1,
+				}
+				break
+			}
+		}
+	}
+
+	if step != nil {
+		contest.RevealedCells = append(contest.RevealedCells, revealCellKey(step.UserID, step.ProblemID))
+	}
+	after, err := s.buildLeaderboard(contest, leaderboardModeLive, newLeaderboardFreeze(contest, 0))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if countFrozenCells(after) == 0 {
+		now := time.Now()
+		contest.UnfrozenAt = &now
+	}
+	if err := s.contestRepo.UpdateFreezeProgress(contest.ID, contest.RevealedCells, contest.UnfrozenAt); err != nil {
+		return nil, nil, nil, nil, errors.New("保存揭晓进度失败")
+	}
+	if step != nil {
+		for rank, entry := range after {
+			if entry.UserID == step.UserID {
+				step.RankAfter = rank + 1
+				break
+			}
+		}
+	}
+	return step, contest, after, buildContestFreezeState(contest, after), nil
+}
+
+// Unfreeze 一次揭晓全部结果，解除封榜
+func (s *ContestService) Unfreeze(contestID uint) (*model.Contest, error) {
+	contestRevealMu.Lock()
+	defer contestRevealMu.Unlock()
+
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	if err := checkContestRevealable(contest); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	contest.UnfrozenAt = &now
+	if err := s.contestRepo.UpdateFreezeProgress(contest.ID, contest.RevealedCells, contest.UnfrozenAt); err != nil {
+		return nil, errors.New("解除封榜失败")
+	}
+	return contest, nil
+}
+
+func checkContestRevealable(contest *model.Contest) error {
+	if contest.FreezeMinutes <= 0 {
+		return errors.New("该比赛未设置封榜")
+	}
+	if contest.UnfrozenAt != nil {
+		return errors.New("封榜已解除")
+	}
+	if time.Now().Before(contest.EndAt) {
+		return errors.New("比赛结束后才能揭晓封榜结果")
+	}
+	return nil
+}
+
+func contestFreezeActive(contest *model.Contest) bool {
+	return contest.FreezeMinutes > 0 && contest.UnfrozenAt == nil
+}
+
+func newLeaderboardFreeze(contest *model.Contest, viewerID uint) *leaderboardFreeze {
+	revealed := make(map[string]bool, len(contest.RevealedCells))
+	for _, key := range contest.RevealedCells {
+		revealed[key] = true
+	}
+	return &leaderboardFreeze{viewerID: viewerID, revealed: revealed}
+}
+
+// contestFreezeStart 用户的封榜时间：固定起止为比赛结束前 N 分钟，窗口期为个人应结束时间前 N 分钟
+func contestFreezeStart(contest *model.Contest, participation model.ContestParticipation) time.Time {
+	end := contest.EndAt
+	if normalizeContestTimingMode(contest.TimingMode) == contestTimingWindow && participation.ID != 0 {
+		// 按个人时长计算，强制交卷不会提前封榜
+		personalEnd := participation.StartAt.Add(time.Duration(contest.DurationMinutes) * time.Minute)
+		if personalEnd.Before(end) {
+			end = personalEnd
+		}
+	}
+	return end.Add(-time.Duration(contest.FreezeMinutes) * time.Minute)
+}
+
+func revealCellKey(userID, problemID uint) string {
+	return fmt.Sprintf("%d:%d", userID, problemID)
+}
+
+func countFrozenCells(entries []model.ContestLeaderboardEntry) int {
+	count := 0
+	for _, entry := range entries {
+		for _, frozen := range entry.Frozen {
+			if frozen > 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func buildContestFreezeState(contest *model.Contest, entries []model.ContestLeaderboardEntry) *model.ContestFreezeState {
+	state := &model.ContestFreezeState{
+		Enabled:    contest.FreezeMinutes > 0,
+		Frozen:     contestFreezeActive(contest),
+		UnfrozenAt: contest.UnfrozenAt,
+		Remaining:  countFrozenCells(entries),
+	}
+	if state.Enabled && normalizeContestTimingMode(contest.TimingMode) == contestTimingFixed {
+		freezeAt := contest.EndAt.Add(-time.Duration(contest.FreezeMinutes) * time.Minute)
+		state.FreezeAt = &freezeAt
+		if time.Now().Before(freezeAt) {
+			state.Frozen = false
+		}
+	}
+	return state
+}
+
+// ApplyLeaderboardVisibility 按比赛的榜单可见性设置处理选手看到的榜单：
+// never 不公开、after_end 赛后公开、own_rank 只返回查看者本人；OI 赛制结束前隐藏分数与名次，
+// 开启匿名时他人的用户名替换为由比赛密钥计算的代号（见 contestAlias）
+func (s *ContestService) ApplyLeaderboardVisibility(contest *model.Contest, entries []model.ContestLeaderboardEntry, viewerID uint, now time.Time) (*model.ContestBoardView, error) {
+	visibility, err := normalizeLeaderboardVisibility(contest.LeaderboardVisibility)
+	if err != nil {
+		visibility = model.LeaderboardVisibilityAlways
+	}
+	ended := !now.Before(contest.EndAt)
+	switch {
+	case visibility == model.LeaderboardVisibilityNever:
+		return nil, errors.New("该比赛不公开排行榜")
+	case visibility == model.LeaderboardVisibilityAfterEnd && !ended:
+		return nil, errors.New("比赛结束后公布排行榜")
+	}
+
+	view := &model.ContestBoardView{
+		Visibility:   visibility,
+		ScoresHidden: strings.ToLower(contest.Type) == contestTypeOI && !ended,
+		Participants: len(entries),
+	}
+
+	aliasSalt := ""
+	if contest.AnonymizeLeaderboard {
+		if aliasSalt, err = ensureContestAliasSalt(s.contestRepo, contest); err != nil {
+			return nil, errors.New("获取排行榜失败")
+		}
+	}
+
+	if view.ScoresHidden {
+		// 不公布分数时按用户 ID 排列，避免从顺序推断名次；匿名时按代号排列，避免从顺序推断用户 ID
+		sorted := make([]model.ContestLeaderboardEntry, len(entries))
+		copy(sorted, entries)
+		if aliasSalt != "" {
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return contestAlias(aliasSalt, sorted[i].UserID) < contestAlias(aliasSalt, sorted[j].UserID)
+			})
+		} else {
+			sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].UserID < sorted[j].UserID })
+		}
+		entries = sorted
+	}
+
+	result := make([]model.ContestLeaderboardEntry, 0, len(entries))
+	for i, entry := range entries {
+		entry.IsSelf = entry.UserID == viewerID
+		if view.ScoresHidden {
+			hideLeaderboardScores(&entry)
+		} else {
+			entry.Rank = i + 1
+		}
+		if entry.IsSelf {
+			view.MyRank = entry.Rank
+		}
+		if visibility == model.LeaderboardVisibilityOwnRank && !entry.IsSelf {
+			continue
+		}
+		if aliasSalt != "" && !entry.IsSelf {
+			entry.Username = contestAlias(aliasSalt, entry.UserID)
+			entry.UserID = 0
+			entry.Group = ""
+		}
+		result = append(result, entry)
+	}
+	view.Entries = result
+	return view, nil
+}
+
+// ensureContestAliasSalt 返回比赛的匿名代号密钥，早于该字段创建的比赛在首次使用时生成
+func ensureContestAliasSalt(contestRepo *repository.ContestRepository, contest *model.Contest) (string, error) {
+	if contest.AliasSalt != "" {
+		return contest.AliasSalt, nil
+	}
+	salt, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	if salt, err = contestRepo.InitAliasSalt(contest.ID, salt); err != nil {
+		return "", err
+	}
+	contest.AliasSalt = salt
+	return salt, nil
+}
+
+// contestAlias 匿名榜单中的选手代号：以比赛密钥对用户 ID 做 HMAC，代号在同一比赛的榜单与 hack 页面中一致，
+// 但与用户 ID 的大小顺序无关，不同比赛之间也无法对应，选手无法据此还原出他人身份
+func contestAlias(salt string, userID uint) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	fmt.Fprintf(mac, "%d", userID)
+	return "选手 " + strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:6])
+}
+
+func hideLeaderboardScores(entry *model.ContestLeaderboardEntry) {
+	entry.Total = 0
+	entry.LiveTotal = 0
+	entry.PostTotal = 0
+	entry.Scores = nil
+	entry.LiveScores = nil
+	entry.PostScores = nil
+	entry.Frozen = nil
+	entry.Rank = 0
+}
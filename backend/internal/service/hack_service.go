@@ -0,0 +1,291 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const maxHackInputSize = 1 << 20 // 1MB
+
+// hackCreateMu 串行执行“检查进行中的 hack + 创建”，避免同一选手并发请求绕过每人一个进行中 hack 的限制
+var hackCreateMu sync.Mutex
+
+type HackService struct {
+	repo              *repository.HackRepository
+	contestRepo       *repository.ContestRepository
+	userRepo          *repository.UserRepository
+	submissionRepo    *repository.SubmissionRepository
+	participationRepo *repository.ContestParticipationRepository
+	programRepo       *repository.ProblemProgramRepository
+}
+
+func NewHackService() *HackService {
+	return &HackService{
+		repo:              repository.NewHackRepository(),
+		contestRepo:       repository.NewContestRepository(),
+		userRepo:          repository.NewUserRepository(),
+		submissionRepo:    repository.NewSubmissionRepository(),
+		participationRepo: repository.NewContestParticipationRepository(),
+		programRepo:       repository.NewProblemProgramRepository(),
+	}
+}
+
+// Lock 锁定自己在比赛中已通过的题目：锁定后不能再提交该题，但可以查看并 hack 他人的代码
+func (s *HackService) Lock(contestID, userID, problemID uint) (*model.ContestLock, error) {
+	now := time.Now()
+	contest, participation, err := s.loadLiveHackContest(contestID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !containsUint([]uint(contest.ProblemIDs), problemID) {
+		return nil, errors.New("题目不在比赛中")
+	}
+	if s.repo.IsProblemLocked(contestID, userID, problemID) {
+		return nil, errors.New("该题已锁定")
+	}
+
+	submission, err := s.submissionRepo.GetLatestAcceptedInRange(userID, problemID, contest.StartAt, now)
+	if err != nil || classifySubmissionPhase(contest, participation, submission.CreatedAt) != leaderboardPhaseLive {
+		return nil, errors.New("请先在比赛中通过该题再锁定")
+	}
+
+	lock := &model.ContestLock{
+		ContestID:    contestID,
+		UserID:       userID,
+		ProblemID:    problemID,
+		SubmissionID: submission.ID,
+	}
+	if err := s.repo.CreateLock(lock); err != nil {
+		return nil, errors.New("锁定失败")
+	}
+	return lock, nil
+}
+
+// ListLocks 获取选手在比赛中的锁定记录
+func (s *HackService) ListLocks(contestID, userID uint) ([]model.ContestLock, error) {
+	if _, err := s.contestRepo.GetByID(contestID); err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	return s.repo.ListLocksByUser(contestID, userID)
+}
+
+// ListTargets 获取某题可被 hack 的提交（每位其他选手赛时最后一次 AC），需先锁定该题
+func (s *HackService) ListTargets(contestID, userID, problemID uint) ([]model.HackTarget, error) {
+	now := time.Now()
+	contest, _, err := s.loadLiveHackContest(contestID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !containsUint([]uint(contest.ProblemIDs), problemID) {
+		return nil, errors.New("题目不在比赛中")
+	}
+	if !s.repo.IsProblemLocked(contestID, userID, problemID) {
+		return nil, errors.New("请先锁定该题")
+	}
+
+	candidates, err := s.submissionRepo.ListAcceptedForProblemInRange(problemID, contest.StartAt, now)
+	if err != nil {
+		return nil, errors.New("获取提交记录失败")
+	}
+	participations, err := s.participationMap(contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasSalt := ""
+	if contest.AnonymizeLeaderboard {
+		if aliasSalt, err = ensureContestAliasSalt(s.contestRepo, contest); err != nil {
+			return nil, errors.New("获取提交记录失败")
+		}
+	}
+
+	seen := make(map[uint]struct{})
+	targets := make([]model.HackTarget, 0)
+	for _, candidate := range candidates {
+		if candidate.UserID == userID {
+			continue
+		}
+		if _, ok := seen[candidate.UserID]; ok {
+			continue
+		}
+		if !canAccessContest(contest, candidate.UserID, candidate.Group) {
+			continue
+		}
+		if classifySubmissionPhase(contest, participations[candidate.UserID], candidate.CreatedAt) != leaderboardPhaseLive {
+			continue
+		}
+		seen[candidate.UserID] = struct{}{}
+		if aliasSalt != "" {
+			// 匿名榜单的比赛中以与榜单一致的代号代替目标选手
+			candidate.Username = contestAlias(aliasSalt, candidate.UserID)
+			candidate.UserID = 0
+		}
+		targets = append(targets, candidate)
+	}
+	return targets, nil
+}
+
+// Create 发起 hack（实际评测由判题模块异步执行）
+func (s *HackService) Create(contestID, hackerID uint, req *model.HackCreateRequest) (*model.Hack, error) {
+	now := time.Now()
+	contest, _, err := s.loadLiveHackContest(contestID, hackerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	input := req.Input
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("hack 输入不能为空")
+	}
+	if len(input) > maxHackInputSize {
+		return nil, fmt.Errorf("hack 输入不能超过 %dKB", maxHackInputSize/1024)
+	}
+	if !strings.HasSuffix(input, "\n") {
+		input += "\n"
+	}
+
+	target, err := s.submissionRepo.GetByID(req.TargetSubmissionID)
+	if err != nil {
+		return nil, errors.New("目标提交不存在")
+	}
+	if !containsUint([]uint(contest.ProblemIDs), target.ProblemID) {
+		return nil, errors.New("目标提交不属于该比赛")
+	}
+	if target.UserID == hackerID {
+		return nil, errors.New("不能 hack 自己的提交")
+	}
+	if target.Status != model.StatusAccepted {
+		return nil, errors.New("只能 hack 已通过的提交")
+	}
+	targetUser, err := s.userRepo.GetByID(target.UserID)
+	if err != nil || !canAccessContest(contest, target.UserID, targetUser.Group) {
+		return nil, errors.New("目标提交不属于该比赛")
+	}
+	targetParticipation := model.ContestParticipation{}
+	if p, err := s.participationRepo.GetByContestAndUser(contestID, target.UserID); err == nil && p != nil {
+		targetParticipation = *p
+	}
+	if classifySubmissionPhase(contest, targetParticipation, target.CreatedAt) != leaderboardPhaseLive {
+		return nil, errors.New("目标提交不属于该比赛")
+	}
+
+	if !s.repo.IsProblemLocked(contestID, hackerID, target.ProblemID) {
+		return nil, errors.New("请先锁定该题")
+	}
+	if _, err := s.programRepo.GetByProblemAndRole(target.ProblemID, model.ProgramRoleValidator); err != nil {
+		return nil, errors.New("该题未配置输入校验器，暂不支持 hack")
+	}
+	if _, err := s.programRepo.GetByProblemAndRole(target.ProblemID, model.ProgramRoleReference); err != nil {
+		return nil, errors.New("该题未配置标准程序，暂不支持 hack")
+	}
+
+	hackCreateMu.Lock()
+	defer hackCreateMu.Unlock()
+	if s.repo.HasPendingByHacker(hackerID) {
+		return nil, errors.New("上一次 hack 尚未完成，请稍后再试")
+	}
+
+	hack := &model.Hack{
+		ContestID:          contestID,
+		ProblemID:          target.ProblemID,
+		HackerID:           hackerID,
+		TargetSubmissionID: target.ID,
+		TargetUserID:       target.UserID,
+		Input:              input,
+		Status:             model.HackStatusPending,
+	}
+	if err := s.repo.Create(hack); err != nil {
+		return nil, errors.New("创建 hack 失败")
+	}
+	return hack, nil
+}
+
+// List 获取比赛的 hack 记录；比赛结束前，非管理员只能看到与自己相关的 hack 输入
+func (s *HackService) List(contestID, userID uint, isAdmin bool) ([]model.Hack, error) {
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	if !isAdmin {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		if !canAccessContest(contest, userID, user.Group) {
+			return nil, errors.New("无权限访问该比赛")
+		}
+	}
+
+	hacks, err := s.repo.ListByContest(contestID)
+	if err != nil {
+		return nil, errors.New("获取 hack 记录失败")
+	}
+	if !isAdmin && time.Now().Before(contest.EndAt) {
+		for i := range hacks {
+			if hacks[i].HackerID != userID && hacks[i].TargetUserID != userID {
+				hacks[i].Input = ""
+			}
+		}
+	}
+	if !isAdmin && contest.AnonymizeLeaderboard {
+		aliasSalt, err := ensureContestAliasSalt(s.contestRepo, contest)
+		if err != nil {
+			return nil, errors.New("获取 hack 记录失败")
+		}
+		for i := range hacks {
+			if hacks[i].HackerID != userID {
+				hacks[i].HackerName = contestAlias(aliasSalt, hacks[i].HackerID)
+				hacks[i].HackerID = 0
+			}
+			if hacks[i].TargetUserID != userID {
+				hacks[i].TargetName = contestAlias(aliasSalt, hacks[i].TargetUserID)
+				hacks[i].TargetUserID = 0
+			}
+		}
+	}
+	return hacks, nil
+}
+
+// loadLiveHackContest 校验比赛已开启 hack、用户可参赛且当前处于赛时阶段
+func (s *HackService) loadLiveHackContest(contestID, userID uint, now time.Time) (*model.Contest, model.ContestParticipation, error) {
+	participation := model.ContestParticipation{}
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, participation, errors.New("比赛不存在")
+	}
+	if !contest.HackEnabled {
+		return nil, participation, errors.New("该比赛未开启 hack")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, participation, errors.New("用户不存在")
+	}
+	if !canAccessContest(contest, userID, user.Group) {
+		return nil, participation, errors.New("无权限访问该比赛")
+	}
+	if p, err := s.participationRepo.GetByContestAndUser(contestID, userID); err == nil && p != nil {
+		participation = *p
+	}
+	if classifySubmissionPhase(contest, participation, now) != leaderboardPhaseLive {
+		return nil, participation, errors.New("仅可在比赛进行中锁定或 hack")
+	}
+	return contest, participation, nil
+}
+
+func (s *HackService) participationMap(contestID uint) (map[uint]model.ContestParticipation, error) {
+	participations, err := s.participationRepo.ListByContest(contestID)
+	if err != nil {
+		return nil, errors.New("获取比赛会话失败")
+	}
+	result := make(map[uint]model.ContestParticipation, len(participations))
+	for _, participation := range participations {
+		result[participation.UserID] = participation
+	}
+	return result, nil
+}
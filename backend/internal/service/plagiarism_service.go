@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const (
+	defaultPlagiarismThreshold   = 0.6
+	minPlagiarismThreshold       = 0.1
+	defaultPlagiarismReviewLimit = 5
+	maxPlagiarismReviewLimit     = 20
+	// maxPlagiarismPairs 报告最多保存的代码对数（按相似度从高到低）
+	maxPlagiarismPairs = 200
+)
+
+// plagiarismCreateMu 串行执行“检查进行中的查重任务 + 创建任务”，避免并发请求对同一范围重复查重
+var plagiarismCreateMu sync.Mutex
+
+type PlagiarismService struct {
+	repo           *repository.PlagiarismRepository
+	problemRepo    *repository.ProblemRepository
+	contestRepo    *repository.ContestRepository
+	submissionRepo *repository.SubmissionRepository
+}
+
+func NewPlagiarismService() *PlagiarismService {
+	return &PlagiarismService{
+		repo:           repository.NewPlagiarismRepository(),
+		problemRepo:    repository.NewProblemRepository(),
+		contestRepo:    repository.NewContestRepository(),
+		submissionRepo: repository.NewSubmissionRepository(),
+	}
+}
+
+// Start 创建查重任务（指纹比较与 AI 复核由判题模块异步执行）
+func (s *PlagiarismService) Start(req *model.PlagiarismCheckRequest, createdBy uint) (*model.PlagiarismReport, error) {
+	title := ""
+	switch req.Scope {
+	case model.PlagiarismScopeProblem:
+		problem, err := s.problemRepo.GetByID(req.TargetID)
+		if err != nil {
+			return nil, errors.New("题目不存在")
+		}
+		title = problem.Title
+	case model.PlagiarismScopeContest:
+		contest, err := s.contestRepo.GetByID(req.TargetID)
+		if err != nil {
+			return nil, errors.New("比赛不存在")
+		}
+		if len(contest.ProblemIDs) == 0 {
+			return nil, errors.New("比赛没有题目")
+		}
+		title = contest.Title
+	default:
+		return nil, errors.New("查重范围无效")
+	}
+
+	threshold := req.Threshold
+	if threshold == 0 {
+		threshold = defaultPlagiarismThreshold
+	}
+	if threshold < minPlagiarismThreshold || threshold > 1 {
+		return nil, fmt.Errorf("相似度阈值需在 %.1f 到 1 之间", minPlagiarismThreshold)
+	}
+
+	reviewLimit := 0
+	if req.AIReview {
+		if !GetSettingService().GetAISettings().Enabled {
+			return nil, errors.New("AI 功能未启用，无法进行 AI 复核")
+		}
+		reviewLimit = req.AIReviewLimit
+		if reviewLimit <= 0 {
+			reviewLimit = defaultPlagiarismReviewLimit
+		}
+		if reviewLimit > maxPlagiarismReviewLimit {
+			return nil, fmt.Errorf("AI 复核最多 %d 对代码", maxPlagiarismReviewLimit)
+		}
+	}
+
+	plagiarismCreateMu.Lock()
+	defer plagiarismCreateMu.Unlock()
+	if s.repo.HasRunning(req.Scope, req.TargetID) {
+		return nil, errors.New("已有进行中的查重任务")
+	}
+
+	report := &model.PlagiarismReport{
+		Scope:         req.Scope,
+		TargetID:      req.TargetID,
+		Title:         title,
+		Status:        model.PlagiarismStatusRunning,
+		Threshold:     threshold,
+		AIReview:      req.AIReview,
+		AIReviewLimit: reviewLimit,
+		CreatedBy:     createdBy,
+	}
+	if err := s.repo.CreateReport(report); err != nil {
+		return nil, errors.New("创建查重任务失败")
+	}
+	return report, nil
+}
+
+// Get 获取查重报告（不含代码对）
+func (s *PlagiarismService) Get(id uint) (*model.PlagiarismReport, error) {
+	report, err := s.repo.GetReport(id)
+	if err != nil {
+		return nil, errors.New("查重报告不存在")
+	}
+	return report, nil
+}
+
+// GetWithPairs 获取查重报告及全部相似代码对
+func (s *PlagiarismService) GetWithPairs(id uint) (*model.PlagiarismReport, error) {
+	report, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := s.repo.ListPairs(id)
+	if err != nil {
+		return nil, err
+	}
+	report.Pairs = pairs
+	if report.Pairs == nil {
+		report.Pairs = []model.PlagiarismPair{}
+	}
+	return report, nil
+}
+
+// List 分页获取查重报告
+func (s *PlagiarismService) List(page, size int, scope string, targetID uint) (*model.PageData, error) {
+	reports, total, err := s.repo.ListReports(page, size, scope, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageData{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  reports,
+	}, nil
+}
+
+// GetPairDetail 获取一对代码及两份源代码，用于并排对比
+func (s *PlagiarismService) GetPairDetail(reportID, pairID uint) (*model.PlagiarismPairDetail, error) {
+	pair, err := s.repo.GetPair(reportID, pairID)
+	if err != nil {
+		return nil, errors.New("代码对不存在")
+	}
+	detail := &model.PlagiarismPairDetail{PlagiarismPair: *pair}
+	if sub, err := s.submissionRepo.GetByID(pair.SubmissionA); err == nil {
+		detail.CodeA = sub.Code
+	}
+	if sub, err := s.submissionRepo.GetByID(pair.SubmissionB); err == nil {
+		detail.CodeB = sub.Code
+	}
+	return detail, nil
+}
+
+// Delete 删除查重报告
+func (s *PlagiarismService) Delete(id uint) error {
+	report, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if report.Status == model.PlagiarismStatusRunning {
+		return errors.New("查重任务进行中，无法删除")
+	}
+	return s.repo.DeleteReport(id)
+}
+
+// ListSources 获取参与查重的提交：题目范围为全部时间，比赛范围为比赛期间比赛题目的提交
+func (s *PlagiarismService) ListSources(report *model.PlagiarismReport) ([]model.PlagiarismSource, error) {
+	if report.Scope == model.PlagiarismScopeProblem {
+		return s.submissionRepo.ListLatestForPlagiarism([]uint{report.TargetID}, nil, nil)
+	}
+	contest, err := s.contestRepo.GetByID(report.TargetID)
+	if err != nil {
+		return nil, errors.New("比赛不存在")
+	}
+	return s.submissionRepo.ListLatestForPlagiarism(contest.ProblemIDs, &contest.StartAt, &contest.EndAt)
+}
+
+// Complete 保存查重结果，pairs 需按相似度降序，超过上限的部分不保存
+func (s *PlagiarismService) Complete(report *model.PlagiarismReport, sourceCount int, pairs []model.PlagiarismPair) error {
+	found := len(pairs)
+	if len(pairs) > maxPlagiarismPairs {
+		pairs = pairs[:maxPlagiarismPairs]
+	}
+	for i := range pairs {
+		pairs[i].ReportID = report.ID
+	}
+	if err := s.repo.CreatePairs(pairs); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	report.Status = model.PlagiarismStatusDone
+	report.SubmissionCount = sourceCount
+	report.PairCount = len(pairs)
+	report.Message = fmt.Sprintf("比较 %d 份代码，发现 %d 对相似度不低于 %.0f%% 的代码", sourceCount, found, report.Threshold*100)
+	if found > len(pairs) {
+		report.Message += fmt.Sprintf("，仅保存相似度最高的 %d 对", len(pairs))
+	}
+	report.FinishedAt = &now
+	return s.repo.SaveReport(report)
+}
+
+// Fail 查重任务失败
+func (s *PlagiarismService) Fail(report *model.PlagiarismReport, message string) error {
+	now := time.Now()
+	report.Status = model.PlagiarismStatusError
+	report.Message = message
+	report.FinishedAt = &now
+	return s.repo.SaveReport(report)
+}
+
+// RecoverInterrupted 将服务重启前未完成的查重任务标记为异常
+func (s *PlagiarismService) RecoverInterrupted() error {
+	return s.repo.MarkRunningAsError("服务重启，查重任务已中断")
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"oj-system/internal/config"
+	"oj-system/internal/judge/sandbox"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const (
+	maxGeneratedTestcases = 100
+	maxGeneratedDataSize  = 256 << 20 // 一次生成的输入输出文件总大小上限（字节）
+	maxGenerationHistory  = 20
+	programRunTimeLimit   = 10000 // ms，生成器与标准程序单次运行时限
+	programRunMemoryLimit = 1024  // MB
+	programErrorMaxLength = 2000
+)
+
+// testcaseGenerationMu 串行执行“检查进行中的生成任务 + 创建任务”与删除测试数据，
+// 避免并发请求同时启动两个生成任务，或删除生成任务正在写入的数据目录
+var testcaseGenerationMu sync.Mutex
+
+type ProblemProgramService struct {
+	repo        *repository.ProblemProgramRepository
+	problemRepo *repository.ProblemRepository
+}
+
+func NewProblemProgramService() *ProblemProgramService {
+	return &ProblemProgramService{
+		repo:        repository.NewProblemProgramRepository(),
+		problemRepo: repository.NewProblemRepository(),
+	}
+}
+
+// List 获取题目的配套程序
+func (s *ProblemProgramService) List(problemID uint) ([]model.ProblemProgram, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	return s.repo.ListByProblem(problemID)
+}
+
+// Save 保存题目配套程序
+func (s *ProblemProgramService) Save(problemID uint, role string, req *model.ProblemProgramSaveRequest) (*model.ProblemProgram, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	if !isValidProgramRole(role) {
+		return nil, errors.New("无效的程序类型")
+	}
+	if !isValidLanguage(req.Language) {
+		return nil, errors.New("不支持的编程语言")
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return nil, errors.New("程序代码不能为空")
+	}
+
+	program := &model.ProblemProgram{
+		ProblemID: problemID,
+		Role:      role,
+		Language:  req.Language,
+		Code:      req.Code,
+	}
+	if role == model.ProgramRoleGenerator {
+		args := normalizeGeneratorArgs(req.Args)
+		if len(args) > maxGeneratedTestcases {
+			return nil, fmt.Errorf("生成器参数最多 %d 组", maxGeneratedTestcases)
+		}
+		program.Args = model.StringList(args)
+	}
+
+	if err := s.repo.Save(program); err != nil {
+		return nil, errors.New("保存程序失败")
+	}
+	return program, nil
+}
+
+// Delete 删除题目配套程序
+func (s *ProblemProgramService) Delete(problemID uint, role string) error {
+	if !isValidProgramRole(role) {
+		return errors.New("无效的程序类型")
+	}
+	deleted, err := s.repo.DeleteByProblemAndRole(problemID, role)
+	if err != nil {
+		return errors.New("删除程序失败")
+	}
+	if !deleted {
+		return errors.New("程序不存在")
+	}
+	return nil
+}
+
+// StartGeneration 校验生成器、标准程序与参数，创建测试数据生成任务（实际生成由判题模块异步执行）
+func (s *ProblemProgramService) StartGeneration(problemID uint, req *model.TestcaseGenerateRequest, createdBy uint) (*model.TestcaseGeneration, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	generator, err := s.repo.GetByProblemAndRole(problemID, model.ProgramRoleGenerator)
+	if err != nil {
+		return nil, errors.New("请先保存数据生成器")
+	}
+	if _, err := s.repo.GetByProblemAndRole(problemID, model.ProgramRoleReference); err != nil {
+		return nil, errors.New("请先保存标准程序")
+	}
+
+	var args []string
+	if req != nil {
+		args = normalizeGeneratorArgs(req.Args)
+	}
+	if len(args) == 0 {
+		args = normalizeGeneratorArgs(generator.Args)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("请提供生成器参数列表")
+	}
+	if len(args) > maxGeneratedTestcases {
+		return nil, fmt.Errorf("生成器参数最多 %d 组", maxGeneratedTestcases)
+	}
+	testcaseGenerationMu.Lock()
+	defer testcaseGenerationMu.Unlock()
+	if s.repo.HasRunningGeneration(problemID) {
+		return nil, errors.New("该题目已有进行中的生成任务")
+	}
+
+	generation := &model.TestcaseGeneration{
+		ProblemID: problemID,
+		Status:    model.GenerationStatusRunning,
+		Args:      model.StringList(args),
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.CreateGeneration(generation); err != nil {
+		return nil, errors.New("创建生成任务失败")
+	}
+	return generation, nil
+}
+
+// GetGeneration 获取测试数据生成任务
+func (s *ProblemProgramService) GetGeneration(id uint) (*model.TestcaseGeneration, error) {
+	return s.repo.GetGeneration(id)
+}
+
+// ListGenerations 获取题目最近的测试数据生成任务
+func (s *ProblemProgramService) ListGenerations(problemID uint) ([]model.TestcaseGeneration, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	return s.repo.ListGenerations(problemID, maxGenerationHistory)
+}
+
+// RunGeneration 执行测试数据生成任务并保存结果
+func (s *ProblemProgramService) RunGeneration(generation *model.TestcaseGeneration) error {
+	total, totalBytes, err := s.generateTestcases(generation.ProblemID, generation.Args)
+	now := time.Now()
+	generation.FinishedAt = &now
+	if err != nil {
+		generation.Status = model.GenerationStatusError
+		generation.Message = err.Error()
+	} else {
+		generation.Status = model.GenerationStatusSuccess
+		generation.Total = total
+		generation.TotalBytes = totalBytes
+		generation.Message = fmt.Sprintf("已生成 %d 个测试点", total)
+	}
+	return s.repo.SaveGeneration(generation)
+}
+
+// RecoverInterrupted 服务重启后，将中断的生成任务标记为异常
+func (s *ProblemProgramService) RecoverInterrupted() error {
+	return s.repo.MarkRunningGenerationsAsError("服务重启，生成任务已中断")
+}
+
+// generateTestcases 在沙箱中运行数据生成器与标准程序，把新的测试数据写入独立的数据目录，
+// 全部成功后在一个事务中替换题目的普通测试点，再删除旧文件；返回测试点数量与文件总大小
+func (s *ProblemProgramService) generateTestcases(problemID uint, args []string) (int, int64, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return 0, 0, errors.New("题目不存在")
+	}
+	generator, err := s.repo.GetByProblemAndRole(problemID, model.ProgramRoleGenerator)
+	if err != nil {
+		return 0, 0, errors.New("数据生成器已被删除")
+	}
+	reference, err := s.repo.GetByProblemAndRole(problemID, model.ProgramRoleReference)
+	if err != nil {
+		return 0, 0, errors.New("标准程序已被删除")
+	}
+
+	token, err := randomHex(6)
+	if err != nil {
+		return 0, 0, errors.New("创建生成任务失败")
+	}
+	taskName := fmt.Sprintf("gen-%d-%s", problemID, token)
+	generatorDir := sandbox.GetTaskWorkDir(taskName + "-generator")
+	referenceDir := sandbox.GetTaskWorkDir(taskName + "-reference")
+	defer sandbox.CleanWorkDir(generatorDir)
+	defer sandbox.CleanWorkDir(referenceDir)
+
+	// 新数据写入独立目录，替换成功后即为正式数据目录；失败时整个目录删除，不影响现有测试点
+	problemDir := filepath.Join(config.GlobalConfig.Paths.Problems, fmt.Sprintf("%d", problemID))
+	dataDir := filepath.Join(problemDir, taskName)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return 0, 0, errors.New("创建数据目录失败")
+	}
+	replaced := false
+	defer func() {
+		if !replaced {
+			os.RemoveAll(dataDir)
+		}
+	}()
+
+	sb := sandbox.NewSimpleSandbox()
+	if err := prepareProgram(sb, generatorDir, generator, "数据生成器"); err != nil {
+		return 0, 0, err
+	}
+	if err := prepareProgram(sb, referenceDir, reference, "标准程序"); err != nil {
+		return 0, 0, err
+	}
+
+	var totalBytes int64
+	testcases := make([]model.Testcase, 0, len(args))
+	for i, line := range args {
+		inputPath := filepath.Join(dataDir, fmt.Sprintf("%d.in", i+1))
+		outputPath := filepath.Join(dataDir, fmt.Sprintf("%d.out", i+1))
+
+		result, err := sb.RunWithFiles(generatorDir, generator.Language, strings.Fields(line), "", inputPath, programRunTimeLimit, programRunMemoryLimit, maxGeneratedDataSize-totalBytes)
+		if errors.Is(err, sandbox.ErrOutputLimitExceeded) {
+			return 0, 0, fmt.Errorf("第 %d 组参数（%s）生成时测试数据总大小超过 %d MB", i+1, line, maxGeneratedDataSize>>20)
+		}
+		if err := describeProgramRunFailure(result, err); err != nil {
+			return 0, 0, fmt.Errorf("第 %d 组参数（%s）运行数据生成器失败: %v", i+1, line, err)
+		}
+		totalBytes += fileSize(inputPath)
+
+		result, err = sb.RunWithFiles(referenceDir, reference.Language, nil, inputPath, outputPath, programRunTimeLimit, programRunMemoryLimit, maxGeneratedDataSize-totalBytes)
+		if errors.Is(err, sandbox.ErrOutputLimitExceeded) {
+			return 0, 0, fmt.Errorf("第 %d 组数据运行标准程序时测试数据总大小超过 %d MB", i+1, maxGeneratedDataSize>>20)
+		}
+		if err := describeProgramRunFailure(result, err); err != nil {
+			return 0, 0, fmt.Errorf("第 %d 组数据运行标准程序失败: %v", i+1, err)
+		}
+		totalBytes += fileSize(outputPath)
+
+		testcases = append(testcases, model.Testcase{
+			ProblemID:  problemID,
+			InputFile:  inputPath,
+			OutputFile: outputPath,
+			Score:      testcaseScore(i, len(args)),
+			IsSample:   false,
+			OrderNum:   i + 1,
+		})
+	}
+
+	oldTestcases, err := s.problemRepo.GetTestcases(problemID)
+	if err != nil {
+		return 0, 0, errors.New("获取测试点失败")
+	}
+	if err := s.problemRepo.ReplaceRegularTestcases(problemID, testcases); err != nil {
+		return 0, 0, errors.New("保存测试点失败")
+	}
+	replaced = true
+
+	// 替换成功后再删除旧的普通测试点文件，以及因此变空的旧生成目录
+	for _, tc := range oldTestcases {
+		if tc.IsHack {
+			continue
+		}
+		os.Remove(tc.InputFile)
+		os.Remove(tc.OutputFile)
+		if dir := filepath.Dir(tc.InputFile); dir != problemDir {
+			os.Remove(dir)
+		}
+	}
+	return len(testcases), totalBytes, nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// prepareProgram 写入并编译配套程序
+func prepareProgram(sb sandbox.Sandbox, workDir string, program *model.ProblemProgram, label string) error {
+	result, err := sb.Prepare(workDir, program.Language, program.Code)
+	if err != nil {
+		return fmt.Errorf("%s预处理失败: %v", label, err)
+	}
+	if result == nil {
+		return fmt.Errorf("%s预处理失败", label)
+	}
+	if result.Status == model.StatusCompileError {
+		return fmt.Errorf("%s编译失败: %s", label, truncateProgramError(result.Error))
+	}
+	if result.Status != "OK" {
+		return fmt.Errorf("%s预处理失败: %s", label, result.Error)
+	}
+	return nil
+}
+
+// describeProgramRunFailure 将配套程序的非正常运行结果转换为错误
+func describeProgramRunFailure(result *sandbox.ExecuteResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return errors.New("运行结果为空")
+	}
+	if result.Status == "OK" {
+		return nil
+	}
+	if result.Error != "" {
+		return fmt.Errorf("%s: %s", result.Status, truncateProgramError(result.Error))
+	}
+	return errors.New(result.Status)
+}
+
+func truncateProgramError(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if len(msg) > programErrorMaxLength {
+		return msg[:programErrorMaxLength] + "..."
+	}
+	return msg
+}
+
+func normalizeGeneratorArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	for _, line := range args {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+func isValidProgramRole(role string) bool {
+	switch role {
+	case model.ProgramRoleGenerator, model.ProgramRoleReference, model.ProgramRoleValidator:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"archive/zip"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -25,6 +27,8 @@ type ProblemService struct {
 	contestRepo *repository.ContestRepository
 	userRepo *repository.UserRepository
 	participationRepo *repository.ContestParticipationRepository
+	aiCacheRepo *repository.AICacheRepository
+	programRepo *repository.ProblemProgramRepository
 }
 
 func NewProblemService() *ProblemService {
@@ -34,6 +38,8 @@ func NewProblemService() *ProblemService {
 		contestRepo: repository.NewContestRepository(),
 		userRepo: repository.NewUserRepository(),
 		participationRepo: repository.NewContestParticipationRepository(),
+		aiCacheRepo: repository.NewAICacheRepository(),
+		programRepo: repository.NewProblemProgramRepository(),
 	}
 }
 
@@ -56,6 +62,7 @@ func (s *ProblemService) Create(req *model.ProblemCreateRequest, createdBy uint)
 		Difficulty:    req.Difficulty,
 		Tags:          req.Tags,
 		AIJudgeConfig: req.AIJudgeConfig,
+		AIHintEnabled: req.AIHintEnabled,
 		FileIOEnabled: fileEnabled,
 		FileInputName: inputName,
 		FileOutputName: outputName,
@@ -129,6 +136,7 @@ func (s *ProblemService) Update(id uint, req *model.ProblemCreateRequest) (*mode
 		return nil, err
 	}
 
+	oldStatementHash := problem.StatementHash()
 	problem.Title = req.Title
 	problem.Description = req.Description
 	problem.InputFormat = req.InputFormat
@@ -139,7 +147,9 @@ func (s *ProblemService) Update(id uint, req *model.ProblemCreateRequest) (*mode
 	problem.MemoryLimit = req.MemoryLimit
 	problem.Difficulty = req.Difficulty
 	problem.Tags = req.Tags
+	aiConfigChanged := !sameAIJudgeConfig(problem.AIJudgeConfig, req.AIJudgeConfig)
 	problem.AIJudgeConfig = req.AIJudgeConfig
+	problem.AIHintEnabled = req.AIHintEnabled
 	problem.FileIOEnabled = fileEnabled
 	problem.FileInputName = inputName
 	problem.FileOutputName = outputName
@@ -149,9 +159,22 @@ func (s *ProblemService) Update(id uint, req *model.ProblemCreateRequest) (*mode
 		return nil, errors.New("更新题目失败")
 	}
 
+	// AI 判题配置或题面变更后，旧的分析结果不再适用
+	if aiConfigChanged || problem.StatementHash() != oldStatementHash {
+		if err := s.aiCacheRepo.DeleteByProblem(id); err != nil {
+			log.Printf("清理题目 AI 分析缓存失败: problem_id=%d, err=%v", id, err)
+		}
+	}
+
 	return problem, nil
 }
 
+func sameAIJudgeConfig(a, b *model.AIJudgeConfig) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
 // Delete 删除题目
 func (s *ProblemService) Delete(id uint) error {
 	_, err := s.repo.GetByID(id)
@@ -374,8 +397,14 @@ func (s *ProblemService) AddTestcase(problemID uint, inputReader, outputReader i
 	return s.repo.CreateTestcase(testcase)
 }
 
-// DeleteTestcases 删除所有测试用例
-func (s *ProblemService) DeleteTestcases(problemID uint) error {
+// DeleteTestcases 删除所有测试用例；有进行中的生成任务时拒绝删除，避免删掉正在写入的数据目录
+func (s *ProblemService) DeleteTestcases(problemID uint) error {
+	testcaseGenerationMu.Lock()
+	defer testcaseGenerationMu.Unlock()
+	if s.programRepo.HasRunningGeneration(problemID) {
+		return errors.New("该题目有进行中的测试数据生成任务，请等待完成后再删除")
+	}
+
 	// 删除文件
 	problemDir := filepath.Join(config.GlobalConfig.Paths.Problems, fmt.Sprintf("%d", problemID))
 	files, _ := filepath.Glob(filepath.Join(problemDir, "*.in"))
@@ -386,10 +415,60 @@ func (s *ProblemService) DeleteTestcases(problemID uint) error {
 	for _, f := range files {
 		os.Remove(f)
 	}
+	// 服务端生成的测试数据位于单独的子目录
+	dirs, _ := filepath.Glob(filepath.Join(problemDir, "gen-*"))
+	for _, dir := range dirs {
+		os.RemoveAll(dir)
+	}
 
 	return s.repo.DeleteTestcases(problemID)
 }
 
+// DeleteRegularTestcases 删除普通测试用例及其文件，保留比赛中 hack 成功后加入的测试点（重新生成或上传测试数据时使用）
+func (s *ProblemService) DeleteRegularTestcases(problemID uint) error {
+	testcases, err := s.repo.GetTestcases(problemID)
+	if err != nil {
+		return err
+	}
+	problemDir := filepath.Join(config.GlobalConfig.Paths.Problems, fmt.Sprintf("%d", problemID))
+	for _, tc := range testcases {
+		if tc.IsHack {
+			continue
+		}
+		os.Remove(tc.InputFile)
+		os.Remove(tc.OutputFile)
+		// 服务端生成的测试数据位于单独的子目录，清空后一并删除
+		if dir := filepath.Dir(tc.InputFile); dir != problemDir {
+			os.Remove(dir)
+		}
+	}
+	return s.repo.DeleteRegularTestcases(problemID)
+}
+
+// MoveHackTestcasesLast 将 hack 测试点的序号排到普通测试点之后
+func (s *ProblemService) MoveHackTestcasesLast(problemID uint) error {
+	testcases, err := s.repo.GetTestcases(problemID)
+	if err != nil {
+		return err
+	}
+	orderNum := 0
+	for _, tc := range testcases {
+		if !tc.IsHack && tc.OrderNum > orderNum {
+			orderNum = tc.OrderNum
+		}
+	}
+	for _, tc := range testcases {
+		if !tc.IsHack {
+			continue
+		}
+		orderNum++
+		if err := s.repo.UpdateTestcaseOrder(tc.ID, orderNum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *ProblemService) PrepareProblemRejudge(problemID uint) ([]model.Submission, error) {
 	if _, err := s.repo.GetByID(problemID); err != nil {
 		return nil, errors.New("题目不存在")
@@ -559,8 +638,8 @@ func (s *ProblemService) UploadTestcaseZip(problemID uint, zipPath string) error
 		return compareFileNames(validPairs[i].Input.Name, validPairs[j].Input.Name)
 	})
 
-	// 5. 删除旧数据
-	if err := s.DeleteTestcases(problemID); err != nil {
+	// 5. 删除旧数据（保留 hack 测试点）
+	if err := s.DeleteRegularTestcases(problemID); err != nil {
 		return err
 	}
 
@@ -568,12 +647,6 @@ func (s *ProblemService) UploadTestcaseZip(problemID uint, zipPath string) error
 	problemDir := filepath.Join(config.GlobalConfig.Paths.Problems, fmt.Sprintf("%d", problemID))
 	os.MkdirAll(problemDir, 0755)
 
-	// 计算每个测试点的分数
-	scorePerCase := 100 / len(validPairs)
-	if scorePerCase == 0 {
-		scorePerCase = 1
-	}
-
 	for i, p := range validPairs {
 		orderNum := i + 1
 		
@@ -589,18 +662,12 @@ func (s *ProblemService) UploadTestcaseZip(problemID uint, zipPath string) error
 			return err
 		}
 
-		// 最后一个测试点补齐分数
-		score := scorePerCase
-		if i == len(validPairs)-1 {
-			score = 100 - scorePerCase*(len(validPairs)-1)
-		}
-
 		// 创建记录
 		testcase := &model.Testcase{
 			ProblemID:  problemID,
 			InputFile:  inputFile,
 			OutputFile: outputFile,
-			Score:      score,
+			Score:      testcaseScore(i, len(validPairs)),
 			IsSample:   false,
 			OrderNum:   orderNum,
 		}
@@ -609,7 +676,19 @@ func (s *ProblemService) UploadTestcaseZip(problemID uint, zipPath string) error
 		}
 	}
 
-	return nil
+	return s.MoveHackTestcasesLast(problemID)
+}
+
+// testcaseScore 计算批量生成测试点时第 index 个测试点的分数（总和 100，最后一个测试点补齐）
+func testcaseScore(index, total int) int {
+	scorePerCase := 100 / total
+	if scorePerCase == 0 {
+		scorePerCase = 1
+	}
+	if index == total-1 {
+		return 100 - scorePerCase*(total-1)
+	}
+	return scorePerCase
 }
 
 func extractZipFile(f *zip.File, dest string) error {
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type ProblemTranslationService struct {
+	problemRepo *repository.ProblemRepository
+}
+
+func NewProblemTranslationService() *ProblemTranslationService {
+	return &ProblemTranslationService{
+		problemRepo: repository.NewProblemRepository(),
+	}
+}
+
+// Overview 获取题目的全部译文（含草稿）及支持的语言
+func (s *ProblemTranslationService) Overview(problemID uint) (*model.ProblemTranslationOverview, error) {
+	problem, err := s.problemRepo.GetByID(problemID)
+	if err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	hash := problem.StatementHash()
+	items := make([]model.ProblemTranslationItem, 0, len(problem.Translations))
+	for _, locale := range model.ProblemLocales {
+		tr := problem.Translations[locale.Code]
+		if tr == nil {
+			continue
+		}
+		items = append(items, model.ProblemTranslationItem{
+			Locale:             locale.Code,
+			Name:               locale.Name,
+			ProblemTranslation: tr,
+			Outdated:           tr.SourceHash != "" && tr.SourceHash != hash && tr.Status != model.TranslationStatusTranslating,
+		})
+	}
+	return &model.ProblemTranslationOverview{
+		SourceLocale: model.ProblemSourceLocale,
+		Locales:      model.ProblemLocales,
+		Translations: items,
+	}, nil
+}
+
+// StartMachineTranslation 将所选语言的译文标记为翻译中（AI 调用由判题模块异步执行），返回需要翻译的语言；
+// 已发布的译文只标记其待审核版本，翻译期间继续对学生展示
+func (s *ProblemTranslationService) StartMachineTranslation(problemID uint, req *model.ProblemTranslateRequest) ([]string, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	if !GetSettingService().GetAISettings().Enabled {
+		return nil, errors.New("AI 功能未启用")
+	}
+
+	locales := make([]string, 0, len(req.Locales))
+	seen := make(map[string]bool)
+	for _, code := range req.Locales {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if _, ok := model.FindProblemLocale(code); !ok {
+			return nil, fmt.Errorf("不支持的语言: %s", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			locales = append(locales, code)
+		}
+	}
+
+	err := s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		for _, code := range locales {
+			if tr := translations[code]; tr != nil && tr.IsTranslating() {
+				return fmt.Errorf("%s 译文正在翻译中", code)
+			}
+		}
+		// 重新翻译时保留已有内容，翻译完成后再覆盖
+		for _, code := range locales {
+			tr := translations[code]
+			if tr != nil && tr.Status == model.TranslationStatusReviewed {
+				if tr.Pending == nil {
+					tr.Pending = &model.ProblemTranslationDraft{}
+				}
+				tr.Pending.Status = model.TranslationStatusTranslating
+				tr.Pending.Error = ""
+				tr.Pending.UpdatedAt = time.Now()
+				continue
+			}
+			if tr == nil {
+				tr = &model.ProblemTranslation{}
+				translations[code] = tr
+			}
+			tr.Status = model.TranslationStatusTranslating
+			tr.Error = ""
+			tr.UpdatedAt = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, translationUpdateError(err)
+	}
+	return locales, nil
+}
+
+// CompleteMachineTranslation 保存机器翻译结果，作为待审核的草稿；已发布的译文保存为其待审核版本
+func (s *ProblemTranslationService) CompleteMachineTranslation(problemID uint, locale string, result *model.ProblemTranslation, sourceHash, modelName string) error {
+	return s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		if tr := translations[locale]; tr != nil && tr.Status == model.TranslationStatusReviewed {
+			tr.Pending = &model.ProblemTranslationDraft{
+				Status:       model.TranslationStatusDraft,
+				Title:        result.Title,
+				Description:  result.Description,
+				InputFormat:  result.InputFormat,
+				OutputFormat: result.OutputFormat,
+				Hint:         result.Hint,
+				Model:        modelName,
+				SourceHash:   sourceHash,
+				UpdatedAt:    time.Now(),
+			}
+			return nil
+		}
+		translations[locale] = &model.ProblemTranslation{
+			Status:       model.TranslationStatusDraft,
+			Title:        result.Title,
+			Description:  result.Description,
+			InputFormat:  result.InputFormat,
+			OutputFormat: result.OutputFormat,
+			Hint:         result.Hint,
+			Model:        modelName,
+			SourceHash:   sourceHash,
+			UpdatedAt:    time.Now(),
+		}
+		return nil
+	})
+}
+
+// FailMachineTranslation 记录机器翻译失败；之前已有译文内容时保留内容，恢复为草稿，已发布的译文不受影响
+func (s *ProblemTranslationService) FailMachineTranslation(problemID uint, locale string, message string) error {
+	return s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		tr := translations[locale]
+		if tr == nil {
+			tr = &model.ProblemTranslation{}
+			translations[locale] = tr
+		}
+		failTranslation(tr, message)
+		return nil
+	})
+}
+
+// failTranslation 将翻译中的译文（或其待审核版本）标记为失败，已有内容时恢复为草稿
+func failTranslation(tr *model.ProblemTranslation, message string) {
+	if tr.Pending != nil {
+		tr.Pending.Status = model.TranslationStatusError
+		if tr.Pending.Title != "" {
+			tr.Pending.Status = model.TranslationStatusDraft
+		}
+		tr.Pending.Error = message
+		tr.Pending.UpdatedAt = time.Now()
+		return
+	}
+	tr.Status = model.TranslationStatusError
+	if tr.Title != "" {
+		tr.Status = model.TranslationStatusDraft
+	}
+	tr.Error = message
+	tr.UpdatedAt = time.Now()
+}
+
+// Save 保存人工修改的译文；reviewed 为 true 时标记为已审核并记录审核人（替换已发布的内容并清除待审核版本），
+// 否则保存为草稿，已发布的译文保存为其待审核版本
+func (s *ProblemTranslationService) Save(problemID uint, locale string, req *model.ProblemTranslationRequest, reviewerID uint, reviewer string) (*model.ProblemTranslation, error) {
+	if _, ok := model.FindProblemLocale(locale); !ok {
+		return nil, fmt.Errorf("不支持的语言: %s", locale)
+	}
+	problem, err := s.problemRepo.GetByID(problemID)
+	if err != nil {
+		return nil, errors.New("题目不存在")
+	}
+
+	var saved *model.ProblemTranslation
+	err = s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		tr := translations[locale]
+		if tr != nil && tr.IsTranslating() {
+			return errors.New("该译文正在翻译中，请稍后再保存")
+		}
+		if tr == nil {
+			tr = &model.ProblemTranslation{}
+			translations[locale] = tr
+		}
+		saved = tr
+		if !req.Reviewed && tr.Status == model.TranslationStatusReviewed {
+			tr.Pending = &model.ProblemTranslationDraft{
+				Status:       model.TranslationStatusDraft,
+				Title:        strings.TrimSpace(req.Title),
+				Description:  req.Description,
+				InputFormat:  req.InputFormat,
+				OutputFormat: req.OutputFormat,
+				Hint:         req.Hint,
+				UpdatedAt:    time.Now(),
+			}
+			return nil
+		}
+		tr.Title = strings.TrimSpace(req.Title)
+		tr.Description = req.Description
+		tr.InputFormat = req.InputFormat
+		tr.OutputFormat = req.OutputFormat
+		tr.Hint = req.Hint
+		tr.Error = ""
+		tr.UpdatedAt = time.Now()
+		if req.Reviewed {
+			now := time.Now()
+			tr.Status = model.TranslationStatusReviewed
+			tr.ReviewerID = reviewerID
+			tr.Reviewer = reviewer
+			tr.ReviewedAt = &now
+			// 审核即确认译文与当前原文一致
+			tr.SourceHash = problem.StatementHash()
+			tr.Pending = nil
+		} else {
+			tr.Status = model.TranslationStatusDraft
+			tr.ReviewerID = 0
+			tr.Reviewer = ""
+			tr.ReviewedAt = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, translationUpdateError(err)
+	}
+	return saved, nil
+}
+
+// Delete 删除某一语言的译文
+func (s *ProblemTranslationService) Delete(problemID uint, locale string) error {
+	err := s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		tr := translations[locale]
+		if tr == nil {
+			return errors.New("译文不存在")
+		}
+		if tr.IsTranslating() {
+			return errors.New("该译文正在翻译中，请稍后再删除")
+		}
+		delete(translations, locale)
+		return nil
+	})
+	return translationUpdateError(err)
+}
+
+// DiscardPending 放弃已发布译文的待审核版本
+func (s *ProblemTranslationService) DiscardPending(problemID uint, locale string) error {
+	err := s.problemRepo.UpdateTranslations(problemID, func(translations model.ProblemTranslationMap) error {
+		tr := translations[locale]
+		if tr == nil || tr.Pending == nil {
+			return errors.New("没有待审核的新版本")
+		}
+		if tr.Pending.Status == model.TranslationStatusTranslating {
+			return errors.New("该译文正在翻译中，请稍后再放弃")
+		}
+		tr.Pending = nil
+		return nil
+	})
+	return translationUpdateError(err)
+}
+
+// RecoverInterrupted 服务重启后，将中断的机器翻译标记为失败
+func (s *ProblemTranslationService) RecoverInterrupted() error {
+	ids, err := s.problemRepo.ListIDsWithTranslationStatus(model.TranslationStatusTranslating)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		err := s.problemRepo.UpdateTranslations(id, func(translations model.ProblemTranslationMap) error {
+			for _, tr := range translations {
+				if tr.IsTranslating() {
+					failTranslation(tr, "服务重启，翻译中断")
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("恢复中断的题面翻译失败: problem_id=%d, err=%v", id, err)
+		}
+	}
+	return nil
+}
+
+func translationUpdateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("题目不存在")
+	}
+	return err
+}
+
+// LocalizeProblem 按偏好语言（依次尝试）将题面替换为已审核的译文，并填写返回的语言与可选语言；
+// 草稿与原文修改前审核的译文不对外展示，没有匹配的译文时返回原文
+func LocalizeProblem(problem *model.Problem, preferred []string) {
+	hash := problem.StatementHash()
+	problem.Locale = model.ProblemSourceLocale
+	problem.AvailableLocales = []string{model.ProblemSourceLocale}
+	for _, locale := range model.ProblemLocales {
+		if tr := problem.Translations[locale.Code]; tr != nil && tr.IsPublished(hash) {
+			problem.AvailableLocales = append(problem.AvailableLocales, locale.Code)
+		}
+	}
+
+	for _, code := range preferred {
+		if code == model.ProblemSourceLocale {
+			return
+		}
+		tr := problem.Translations[code]
+		if tr == nil || !tr.IsPublished(hash) {
+			continue
+		}
+		problem.Locale = code
+		problem.Title = tr.Title
+		problem.Description = tr.Description
+		problem.InputFormat = tr.InputFormat
+		problem.OutputFormat = tr.OutputFormat
+		problem.Hint = tr.Hint
+		return
+	}
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言代码（只保留主语言，如 en-US 视为 en）
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		code string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+		langs = append(langs, weighted{code: tag, q: q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	codes := make([]string, 0, len(langs))
+	seen := make(map[string]bool)
+	for _, l := range langs {
+		if !seen[l.code] {
+			seen[l.code] = true
+			codes = append(codes, l.code)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const maxVerificationHistory = 20
+
+// verificationCreateMu 串行执行“检查进行中的校验任务 + 创建任务”，避免并发请求同时启动两个校验任务
+var verificationCreateMu sync.Mutex
+
+type ProblemVerificationService struct {
+	repo        *repository.ProblemVerificationRepository
+	problemRepo *repository.ProblemRepository
+}
+
+func NewProblemVerificationService() *ProblemVerificationService {
+	return &ProblemVerificationService{
+		repo:        repository.NewProblemVerificationRepository(),
+		problemRepo: repository.NewProblemRepository(),
+	}
+}
+
+// ListSolutions 获取题目的标注解法
+func (s *ProblemVerificationService) ListSolutions(problemID uint) ([]model.ProblemSolution, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	return s.repo.ListSolutions(problemID)
+}
+
+// CreateSolution 添加标注解法
+func (s *ProblemVerificationService) CreateSolution(problemID uint, req *model.ProblemSolutionRequest, createdBy uint) (*model.ProblemSolution, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	if err := validateSolutionRequest(req); err != nil {
+		return nil, err
+	}
+
+	solution := &model.ProblemSolution{
+		ProblemID:      problemID,
+		Name:           strings.TrimSpace(req.Name),
+		Language:       req.Language,
+		Code:           req.Code,
+		ExpectedStatus: req.ExpectedStatus,
+		CreatedBy:      createdBy,
+	}
+	if err := s.repo.SaveSolution(solution); err != nil {
+		return nil, errors.New("保存解法失败")
+	}
+	return solution, nil
+}
+
+// UpdateSolution 更新标注解法
+func (s *ProblemVerificationService) UpdateSolution(problemID, solutionID uint, req *model.ProblemSolutionRequest) (*model.ProblemSolution, error) {
+	solution, err := s.repo.GetSolution(problemID, solutionID)
+	if err != nil {
+		return nil, errors.New("解法不存在")
+	}
+	if err := validateSolutionRequest(req); err != nil {
+		return nil, err
+	}
+
+	solution.Name = strings.TrimSpace(req.Name)
+	solution.Language = req.Language
+	solution.Code = req.Code
+	solution.ExpectedStatus = req.ExpectedStatus
+	if err := s.repo.SaveSolution(solution); err != nil {
+		return nil, errors.New("保存解法失败")
+	}
+	return solution, nil
+}
+
+// DeleteSolution 删除标注解法
+func (s *ProblemVerificationService) DeleteSolution(problemID, solutionID uint) error {
+	deleted, err := s.repo.DeleteSolution(problemID, solutionID)
+	if err != nil {
+		return errors.New("删除解法失败")
+	}
+	if !deleted {
+		return errors.New("解法不存在")
+	}
+	return nil
+}
+
+// StartVerification 创建题目校验任务（实际评测由判题模块异步执行）
+func (s *ProblemVerificationService) StartVerification(problemID uint, createdBy uint) (*model.ProblemVerification, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	solutions, err := s.repo.ListSolutions(problemID)
+	if err != nil {
+		return nil, errors.New("获取解法失败")
+	}
+	if len(solutions) == 0 {
+		return nil, errors.New("请先添加标注解法")
+	}
+	testcases, err := s.problemRepo.GetTestcases(problemID)
+	if err != nil || len(testcases) == 0 {
+		return nil, errors.New("题目暂无测试数据")
+	}
+	verificationCreateMu.Lock()
+	defer verificationCreateMu.Unlock()
+	if s.repo.HasRunning(problemID) {
+		return nil, errors.New("该题目已有进行中的校验任务")
+	}
+
+	verification := &model.ProblemVerification{
+		ProblemID: problemID,
+		Status:    model.VerificationStatusRunning,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.Create(verification); err != nil {
+		return nil, errors.New("创建校验任务失败")
+	}
+	return verification, nil
+}
+
+// ListVerifications 获取题目最近的校验记录
+func (s *ProblemVerificationService) ListVerifications(problemID uint) ([]model.ProblemVerification, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	return s.repo.ListByProblem(problemID, maxVerificationHistory)
+}
+
+func validateSolutionRequest(req *model.ProblemSolutionRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("解法名称不能为空")
+	}
+	if !isValidLanguage(req.Language) {
+		return errors.New("不支持的编程语言")
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return errors.New("解法代码不能为空")
+	}
+	if !isValidExpectedStatus(req.ExpectedStatus) {
+		return errors.New("无效的预期结果")
+	}
+	return nil
+}
+
+func isValidExpectedStatus(status string) bool {
+	switch status {
+	case model.StatusAccepted,
+		model.StatusWrongAnswer,
+		model.StatusTimeLimitExceeded,
+		model.StatusMemoryLimitExceeded,
+		model.StatusRuntimeError,
+		model.StatusCompileError:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"strconv"
+	"strings"
 	"sync"
 
 	"oj-system/internal/model"
@@ -76,11 +77,24 @@ func (s *SettingService) Set(key, value string) error {
 	return nil
 }
 
+// aiProviderDefaults 各服务商的默认接口地址与模型
+var aiProviderDefaults = map[string]struct {
+	APIURL string
+	Model  string
+}{
+	"deepseek":  {APIURL: "https://api.deepseek.com/v1/chat/completions", Model: "deepseek-chat"},
+	"openai":    {APIURL: "https://api.openai.com/v1/chat/completions", Model: "gpt-4o"},
+	"moonshot":  {APIURL: "https://api.moonshot.cn/v1/chat/completions", Model: "moonshot-v1-8k"},
+	"anthropic": {APIURL: "https://api.anthropic.com/v1/messages", Model: "claude-3-5-sonnet-latest"},
+	"ollama":    {APIURL: "http://localhost:11434/api/chat", Model: "qwen2.5-coder:7b"},
+	"mock":      {APIURL: "", Model: "mock"}, // api_url 为规则文件路径，留空使用内置规则
+}
+
 // GetAISettings 获取 AI 设置
 func (s *SettingService) GetAISettings() *model.AISettings {
 	settings := &model.AISettings{
 		Enabled:  s.Get(model.SettingAIEnabled) == "true",
-		Provider: s.Get(model.SettingAIProvider),
+		Provider: normalizeAIProvider(s.Get(model.SettingAIProvider)), // 兼容修改前保存的未规范化取值
 		APIKey:   s.Get(model.SettingAIAPIKey),
 		APIURL:   s.Get(model.SettingAIAPIURL),
 		Model:    s.Get(model.SettingAIModel),
@@ -91,16 +105,39 @@ func (s *SettingService) GetAISettings() *model.AISettings {
 	} else {
 		settings.Timeout = 60
 	}
+	if retries, err := strconv.Atoi(s.Get(model.SettingAIMaxRetries)); err == nil {
+		settings.MaxRetries = normalizeAIMaxRetries(retries)
+	} else {
+		settings.MaxRetries = defaultAIMaxRetries
+	}
+	settings.FailurePolicy = normalizeAIFailurePolicy(s.Get(model.SettingAIFailurePolicy))
+	if id, err := strconv.ParseUint(s.Get(model.SettingAIPromptTemplateID), 10, 32); err == nil {
+		settings.PromptTemplateID = uint(id)
+	}
+	if days, err := strconv.Atoi(s.Get(model.SettingAILogRetentionDays)); err == nil {
+		settings.LogRetentionDays = normalizeAILogRetentionDays(days)
+	} else {
+		settings.LogRetentionDays = defaultAILogRetentionDays
+	}
+	if budget, err := strconv.ParseInt(s.Get(model.SettingAIMonthlyTokenBudget), 10, 64); err == nil && budget > 0 {
+		settings.MonthlyTokenBudget = budget
+	}
+	settings.BudgetPolicy = normalizeAIFailurePolicy(s.Get(model.SettingAIBudgetPolicy))
+	settings.CompileExplain = s.Get(model.SettingAICompileExplain) == "true"
 	
-	// 设置默认值
+	// 设置默认值（未配置接口地址/模型时使用对应服务商的默认值）
 	if settings.Provider == "" {
 		settings.Provider = "deepseek"
 	}
+	defaults, ok := aiProviderDefaults[settings.Provider]
+	if !ok {
+		defaults = aiProviderDefaults["deepseek"]
+	}
 	if settings.APIURL == "" {
-		settings.APIURL = "https://api.deepseek.com/v1/chat/completions"
+		settings.APIURL = defaults.APIURL
 	}
 	if settings.Model == "" {
-		settings.Model = "deepseek-chat"
+		settings.Model = defaults.Model
 	}
 	
 	return settings
@@ -112,7 +149,7 @@ func (s *SettingService) UpdateAISettings(req *model.UpdateAISettingsRequest) er
 	if err := s.Set(model.SettingAIEnabled, strconv.FormatBool(req.Enabled)); err != nil {
 		return err
 	}
-	if err := s.Set(model.SettingAIProvider, req.Provider); err != nil {
+	if err := s.Set(model.SettingAIProvider, normalizeAIProvider(req.Provider)); err != nil {
 		return err
 	}
 	if req.APIKey != "" && req.APIKey != "********" {
@@ -129,10 +166,81 @@ func (s *SettingService) UpdateAISettings(req *model.UpdateAISettingsRequest) er
 	if err := s.Set(model.SettingAITimeout, strconv.Itoa(req.Timeout)); err != nil {
 		return err
 	}
+	if err := s.Set(model.SettingAIMaxRetries, strconv.Itoa(normalizeAIMaxRetries(req.MaxRetries))); err != nil {
+		return err
+	}
+	if err := s.Set(model.SettingAIFailurePolicy, normalizeAIFailurePolicy(req.FailurePolicy)); err != nil {
+		return err
+	}
+	if err := s.Set(model.SettingAIPromptTemplateID, strconv.FormatUint(uint64(req.PromptTemplateID), 10)); err != nil {
+		return err
+	}
+	if err := s.Set(model.SettingAILogRetentionDays, strconv.Itoa(normalizeAILogRetentionDays(req.LogRetentionDays))); err != nil {
+		return err
+	}
+	budget := req.MonthlyTokenBudget
+	if budget < 0 {
+		budget = 0
+	}
+	if err := s.Set(model.SettingAIMonthlyTokenBudget, strconv.FormatInt(budget, 10)); err != nil {
+		return err
+	}
+	if err := s.Set(model.SettingAIBudgetPolicy, normalizeAIFailurePolicy(req.BudgetPolicy)); err != nil {
+		return err
+	}
+	if err := s.Set(model.SettingAICompileExplain, strconv.FormatBool(req.CompileExplain)); err != nil {
+		return err
+	}
 	
 	return nil
 }
 
+const (
+	defaultAIMaxRetries = 2
+	maxAIMaxRetries     = 5
+)
+
+func normalizeAIMaxRetries(retries int) int {
+	if retries < 0 {
+		return 0
+	}
+	if retries > maxAIMaxRetries {
+		return maxAIMaxRetries
+	}
+	return retries
+}
+
+const (
+	defaultAILogRetentionDays = 90
+	maxAILogRetentionDays     = 3650
+)
+
+// normalizeAILogRetentionDays 0 表示永久保留
+func normalizeAILogRetentionDays(days int) int {
+	if days < 0 {
+		return 0
+	}
+	if days > maxAILogRetentionDays {
+		return maxAILogRetentionDays
+	}
+	return days
+}
+
+// normalizeAIProvider 服务商名称统一去掉首尾空白并转为小写，与 aiProviderDefaults 的键保持一致
+func normalizeAIProvider(provider string) string {
+	return strings.ToLower(strings.TrimSpace(provider))
+}
+
+// normalizeAIFailurePolicy 未配置或无效时沿用原有行为：调用失败视为满足要求
+func normalizeAIFailurePolicy(policy string) string {
+	switch policy {
+	case model.AIFailurePolicyFail, model.AIFailurePolicyPending:
+		return policy
+	default:
+		return model.AIFailurePolicyPass
+	}
+}
+
 // GetAISettingsForDisplay 获取用于显示的 AI 设置（隐藏 API Key）
 func (s *SettingService) GetAISettingsForDisplay() *model.AISettings {
 	settings := s.GetAISettings()
@@ -24,6 +24,7 @@ type SubmissionService struct {
 	userRepo          *repository.UserRepository
 	contestRepo       *repository.ContestRepository
 	participationRepo *repository.ContestParticipationRepository
+	hackRepo          *repository.HackRepository
 }
 
 func NewSubmissionService() *SubmissionService {
@@ -33,6 +34,7 @@ func NewSubmissionService() *SubmissionService {
 		userRepo:          repository.NewUserRepository(),
 		contestRepo:       repository.NewContestRepository(),
 		participationRepo: repository.NewContestParticipationRepository(),
+		hackRepo:          repository.NewHackRepository(),
 	}
 }
 
@@ -68,6 +70,11 @@ func (s *SubmissionService) Submit(req *model.SubmissionCreateRequest, userID ui
 		return nil, err
 	}
 
+	// 已锁定的题目在赛时不能再次提交
+	if err := s.checkContestProblemLock(req.ProblemID, userID, time.Now()); err != nil {
+		return nil, err
+	}
+
 	// 创建提交记录
 	submission := &model.Submission{
 		ProblemID: req.ProblemID,
@@ -118,6 +125,10 @@ func (s *SubmissionService) GetByID(id uint, userID uint, isAdmin bool) (*model.
 
 	if !isAdmin {
 		s.maskSubmissionForOngoingOI(submission, userID)
+		// 节点标识默认取判题机主机名，只对管理员展示
+		if submission.JudgeEnv != nil {
+			submission.JudgeEnv.NodeID = ""
+		}
 	}
 
 	return submission, nil
@@ -147,23 +158,90 @@ func (s *SubmissionService) List(page, size int, problemID, filterUserID uint, s
 	}, nil
 }
 
+// ListAIReviewQueue 获取等待教师复核 AI 判定的提交（先提交的排在前面）
+func (s *SubmissionService) ListAIReviewQueue(page, size int, problemID uint) (*model.PageData, error) {
+	items, total, err := s.repo.ListByAIStatusWithResult(page, size, model.AIStatusManualReview, problemID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PageData{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  items,
+	}, nil
+}
+
 // UpdateResult 更新判题结果
 func (s *SubmissionService) UpdateResult(submission *model.Submission) error {
-	// 如果是第一次 AC，增加用户解题数和题目通过数
-	if submission.Status == model.StatusAccepted {
-		// 只有非比赛提交才立即更新全局统计
-		if !s.isProblemInActiveContest(submission.ProblemID) {
-			if !s.repo.HasAccepted(submission.UserID, submission.ProblemID) {
-				s.userRepo.IncrementSolvedCount(submission.UserID)
-			}
-			s.userRepo.IncrementAcceptedCount(submission.UserID)
-			s.problemRepo.IncrementAcceptedCount(submission.ProblemID)
-		}
+	// AI 分析尚未完成时状态可能被修正，统计在 AI 结果落定后再更新
+	if submission.Status == model.StatusAccepted && !aiAnalysisUnsettled(submission.AIStatus) {
+		s.recordAccepted(submission, !s.repo.HasSettledAccepted(submission.UserID, submission.ProblemID, submission.ID))
 	}
 
 	return s.repo.Update(submission)
 }
 
+// recordAccepted 更新 AC 相关的用户/题目统计；firstAC 为该用户首次通过此题
+func (s *SubmissionService) recordAccepted(submission *model.Submission, firstAC bool) {
+	// 只有非比赛提交才立即更新全局统计
+	if s.isProblemInActiveContest(submission.ProblemID) {
+		return
+	}
+	if firstAC {
+		s.userRepo.IncrementSolvedCount(submission.UserID)
+	}
+	s.userRepo.IncrementAcceptedCount(submission.UserID)
+	s.problemRepo.IncrementAcceptedCount(submission.ProblemID)
+}
+
+func aiAnalysisUnsettled(aiStatus string) bool {
+	return aiStatus == model.AIStatusReviewing || aiStatus == model.AIStatusPending || aiStatus == model.AIStatusManualReview
+}
+
+// ListIDsByAIStatus 获取处于指定 AI 分析状态的提交（等待 AI 审核或重新分析）
+func (s *SubmissionService) ListIDsByAIStatus(aiStatus string, limit int) ([]uint, error) {
+	return s.repo.ListIDsByAIStatus(aiStatus, limit)
+}
+
+// ListIDsByAIHintStatus 获取处于指定 AI 学习提示状态的提交
+func (s *SubmissionService) ListIDsByAIHintStatus(hintStatus string, limit int) ([]uint, error) {
+	return s.repo.ListIDsByAIHintStatus(hintStatus, limit)
+}
+
+// UpdateAIHint 保存 AI 学习提示状态，仅当提交仍处于 fromStatus 时更新（已被重测则跳过）
+func (s *SubmissionService) UpdateAIHint(id uint, fromStatus, hintStatus string, hint *model.AIHint) (bool, error) {
+	return s.repo.UpdateAIHintIfStatus(id, fromStatus, hintStatus, hint)
+}
+
+// ListIDsByCompileExplainStatus 获取处于指定编译错误解释状态的提交
+func (s *SubmissionService) ListIDsByCompileExplainStatus(explainStatus string, limit int) ([]uint, error) {
+	return s.repo.ListIDsByCompileExplainStatus(explainStatus, limit)
+}
+
+// UpdateCompileExplanation 保存编译错误解释，仅当提交仍处于 fromStatus 时更新（已被重测则跳过）
+func (s *SubmissionService) UpdateCompileExplanation(id uint, fromStatus, explainStatus string, explanation *model.CompileExplanation) (bool, error) {
+	return s.repo.UpdateCompileExplanationIfStatus(id, fromStatus, explainStatus, explanation)
+}
+
+// UpdateAIResult 保存 AI 分析后的最终结果，仅当提交仍处于 fromAIStatus 时更新（已被重测则跳过）。
+// AI 结果落定且最终为 AC 时补记统计
+func (s *SubmissionService) UpdateAIResult(submission *model.Submission, fromAIStatus string) (bool, error) {
+	firstAC := false
+	if submission.Status == model.StatusAccepted && !aiAnalysisUnsettled(submission.AIStatus) {
+		firstAC = !s.repo.HasSettledAccepted(submission.UserID, submission.ProblemID, submission.ID)
+	}
+
+	updated, err := s.repo.UpdateIfAIStatus(submission, fromAIStatus)
+	if err != nil || !updated {
+		return updated, err
+	}
+	if submission.Status == model.StatusAccepted && !aiAnalysisUnsettled(submission.AIStatus) {
+		s.recordAccepted(submission, firstAC)
+	}
+	return true, nil
+}
+
 // GetPendingSubmissions 获取待判题的提交
 func (s *SubmissionService) GetPendingSubmissions(limit int) ([]model.Submission, error) {
 	return s.repo.GetPendingSubmissions(limit)
@@ -285,7 +363,7 @@ func (s *SubmissionService) maskSubmissionForOngoingOI(submission *model.Submiss
 		if !canAccessContest(&contest, viewerID, user.Group) {
 			continue
 		}
-		liveStart, liveEnd, active := s.getUserContestLiveWindow(&contest, viewerID)
+		liveStart, liveEnd, active := getUserContestLiveWindow(s.participationRepo, &contest, viewerID)
 		if !active || now.Before(liveStart) || !now.Before(liveEnd) {
 			continue
 		}
@@ -306,6 +384,10 @@ func (s *SubmissionService) maskSubmissionForOngoingOI(submission *model.Submiss
 		submission.AIJudgeResult = nil
 		submission.CompileError = ""
 		submission.FinalMessage = ""
+		submission.AIHintStatus = ""
+		submission.AIHint = nil
+		submission.CompileExplainStatus = ""
+		submission.CompileExplanation = nil
 		return
 	}
 }
@@ -333,7 +415,7 @@ func (s *SubmissionService) maskListForOngoingOI(items []model.SubmissionListIte
 			if !canAccessContest(&contest, viewerID, user.Group) {
 				continue
 			}
-			liveStart, liveEnd, active := s.getUserContestLiveWindow(&contest, viewerID)
+			liveStart, liveEnd, active := getUserContestLiveWindow(s.participationRepo, &contest, viewerID)
 			if !active || now.Before(liveStart) || !now.Before(liveEnd) {
 				continue
 			}
@@ -446,6 +528,45 @@ func (s *SubmissionService) checkContestSubmissionLimit(problemID, userID uint,
 	return nil
 }
 
+func (s *SubmissionService) checkContestProblemLock(problemID, userID uint, now time.Time) error {
+	if userID == 0 {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	// 锁题只在比赛赛时生效，只需检查此刻正在进行的比赛
+	contests, err := s.contestRepo.ListRunningAt(now)
+	if err != nil {
+		return errors.New("校验题目锁定状态失败")
+	}
+
+	for _, contest := range contests {
+		if !contest.HackEnabled || !containsUint([]uint(contest.ProblemIDs), problemID) {
+			continue
+		}
+		if !canAccessContest(&contest, userID, user.Group) {
+			continue
+		}
+
+		participation := model.ContestParticipation{}
+		if p, err := s.participationRepo.GetByContestAndUser(contest.ID, userID); err == nil && p != nil {
+			participation = *p
+		}
+		if classifySubmissionPhase(&contest, participation, now) != leaderboardPhaseLive {
+			continue
+		}
+		if s.hackRepo.IsProblemLocked(contest.ID, userID, problemID) {
+			return fmt.Errorf("已在比赛《%s》中锁定该题，不能再次提交", contest.Title)
+		}
+	}
+
+	return nil
+}
+
 func (s *SubmissionService) countContestEffectiveSubmissions(userID uint, contest *model.Contest, participation model.ContestParticipation, now time.Time) (int, error) {
 	if contest == nil {
 		return 0, nil
@@ -468,7 +589,9 @@ func (s *SubmissionService) countContestEffectiveSubmissions(userID uint, contes
 	return count, nil
 }
 
-func (s *SubmissionService) getUserContestLiveWindow(contest *model.Contest, userID uint) (time.Time, time.Time, bool) {
+// getUserContestLiveWindow 获取用户在比赛中的赛时区间：按时间窗口计时的比赛为用户自己的窗口（尚未开始时返回 false），
+// 否则为比赛时间与用户参赛记录的交集
+func getUserContestLiveWindow(participationRepo *repository.ContestParticipationRepository, contest *model.Contest, userID uint) (time.Time, time.Time, bool) {
 	if contest == nil || userID == 0 {
 		return time.Time{}, time.Time{}, false
 	}
@@ -477,7 +600,7 @@ func (s *SubmissionService) getUserContestLiveWindow(contest *model.Contest, use
 	liveEnd := contest.EndAt
 	mode := normalizeContestTimingMode(contest.TimingMode)
 
-	participation, err := s.participationRepo.GetByContestAndUser(contest.ID, userID)
+	participation, err := participationRepo.GetByContestAndUser(contest.ID, userID)
 	if mode == contestTimingWindow {
 		if err != nil || participation == nil {
 			return time.Time{}, time.Time{}, false
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"oj-system/internal/judge/sandbox"
+	"oj-system/internal/model"
+	"oj-system/internal/repository"
+)
+
+const (
+	defaultProposalCount    = 10
+	maxProposalCount        = 20
+	maxProposalFocusLength  = 500
+	maxProposalInputSize    = 64 * 1024
+	maxProposalOutputSize   = 1024 * 1024
+	maxProposalBatchHistory = 10
+	defaultProposalScore    = 10
+)
+
+// proposalCreateMu 串行执行“检查进行中的建议任务 + 创建任务”，避免并发请求同时启动两个建议任务
+var proposalCreateMu sync.Mutex
+
+type TestcaseProposalService struct {
+	repo           *repository.TestcaseProposalRepository
+	problemRepo    *repository.ProblemRepository
+	programRepo    *repository.ProblemProgramRepository
+	problemService *ProblemService
+}
+
+func NewTestcaseProposalService() *TestcaseProposalService {
+	return &TestcaseProposalService{
+		repo:           repository.NewTestcaseProposalRepository(),
+		problemRepo:    repository.NewProblemRepository(),
+		programRepo:    repository.NewProblemProgramRepository(),
+		problemService: NewProblemService(),
+	}
+}
+
+// Start 创建 AI 测试数据建议任务（AI 调用与程序运行由判题模块异步执行）
+func (s *TestcaseProposalService) Start(problemID uint, req *model.TestcaseProposeRequest, createdBy uint) (*model.TestcaseProposalBatch, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	if !GetSettingService().GetAISettings().Enabled {
+		return nil, errors.New("AI 功能未启用")
+	}
+	if _, err := s.programRepo.GetByProblemAndRole(problemID, model.ProgramRoleReference); err != nil {
+		return nil, errors.New("请先保存标准程序，用于生成测试数据的输出")
+	}
+	count := req.Count
+	if count <= 0 {
+		count = defaultProposalCount
+	}
+	if count > maxProposalCount {
+		return nil, fmt.Errorf("每次最多建议 %d 组测试数据", maxProposalCount)
+	}
+	focus := strings.TrimSpace(req.Focus)
+	if utf8.RuneCountInString(focus) > maxProposalFocusLength {
+		return nil, fmt.Errorf("补充说明最多 %d 字", maxProposalFocusLength)
+	}
+
+	proposalCreateMu.Lock()
+	defer proposalCreateMu.Unlock()
+	if s.repo.HasRunning(problemID) {
+		return nil, errors.New("该题目已有进行中的建议任务")
+	}
+
+	batch := &model.TestcaseProposalBatch{
+		ProblemID: problemID,
+		Status:    model.ProposalBatchRunning,
+		Count:     count,
+		Focus:     focus,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.CreateBatch(batch); err != nil {
+		return nil, errors.New("创建建议任务失败")
+	}
+	return batch, nil
+}
+
+// GetBatch 获取建议任务
+func (s *TestcaseProposalService) GetBatch(id uint) (*model.TestcaseProposalBatch, error) {
+	return s.repo.GetBatch(id)
+}
+
+// List 获取题目最近的建议任务及其测试数据
+func (s *TestcaseProposalService) List(problemID uint) ([]model.TestcaseProposalBatch, error) {
+	if _, err := s.problemRepo.GetByID(problemID); err != nil {
+		return nil, errors.New("题目不存在")
+	}
+	batches, err := s.repo.ListBatches(problemID, maxProposalBatchHistory)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(batches))
+	for _, b := range batches {
+		ids = append(ids, b.ID)
+	}
+	proposals, err := s.repo.ListProposalsByBatches(ids)
+	if err != nil {
+		return nil, err
+	}
+	byBatch := make(map[uint][]model.TestcaseProposal)
+	for _, p := range proposals {
+		byBatch[p.BatchID] = append(byBatch[p.BatchID], p)
+	}
+	for i := range batches {
+		batches[i].Proposals = byBatch[batches[i].ID]
+		if batches[i].Proposals == nil {
+			batches[i].Proposals = []model.TestcaseProposal{}
+		}
+	}
+	return batches, nil
+}
+
+// Fail 建议任务失败（AI 调用或解析失败）
+func (s *TestcaseProposalService) Fail(batch *model.TestcaseProposalBatch, message string) error {
+	now := time.Now()
+	batch.Status = model.ProposalBatchError
+	batch.Message = message
+	batch.FinishedAt = &now
+	return s.repo.SaveBatch(batch)
+}
+
+// Complete 用输入校验器（如已配置）检查 AI 建议的每组输入，通过的再由标准程序生成输出，保存结果。
+// proposals 只需填写 Description 与 Input；generator 为空表示 AI 未给出可用的数据生成器
+func (s *TestcaseProposalService) Complete(batch *model.TestcaseProposalBatch, proposals []model.TestcaseProposal, generator *model.ProblemProgram) error {
+	if generator != nil && isValidLanguage(generator.Language) && strings.TrimSpace(generator.Code) != "" {
+		batch.GeneratorLanguage = generator.Language
+		batch.GeneratorCode = generator.Code
+		batch.GeneratorArgs = model.StringList(normalizeGeneratorArgs(generator.Args))
+	}
+
+	if err := s.evaluate(batch.ProblemID, batch.ID, proposals); err != nil {
+		return s.Fail(batch, err.Error())
+	}
+	for i := range proposals {
+		proposals[i].BatchID = batch.ID
+		proposals[i].ProblemID = batch.ProblemID
+	}
+	if err := s.repo.CreateProposals(proposals); err != nil {
+		return s.Fail(batch, "保存建议的测试数据失败")
+	}
+
+	valid := 0
+	for _, p := range proposals {
+		if p.Status == model.ProposalStatusValid {
+			valid++
+		}
+	}
+	now := time.Now()
+	batch.Status = model.ProposalBatchDone
+	batch.Message = fmt.Sprintf("AI 建议 %d 组测试数据，%d 组可用", len(proposals), valid)
+	if batch.GeneratorCode == "" {
+		batch.Message += "，未给出可用的数据生成器"
+	}
+	batch.FinishedAt = &now
+	return s.repo.SaveBatch(batch)
+}
+
+// evaluate 在沙箱中运行输入校验器与标准程序，填写每组数据的状态与输出；程序无法编译时返回错误
+func (s *TestcaseProposalService) evaluate(problemID, batchID uint, proposals []model.TestcaseProposal) error {
+	reference, err := s.programRepo.GetByProblemAndRole(problemID, model.ProgramRoleReference)
+	if err != nil {
+		return errors.New("题目未配置标准程序")
+	}
+	validator, err := s.programRepo.GetByProblemAndRole(problemID, model.ProgramRoleValidator)
+	if err != nil {
+		validator = nil
+	}
+
+	taskName := fmt.Sprintf("proposal-%d", batchID)
+	dataDir := sandbox.GetTaskWorkDir(taskName + "-data")
+	referenceDir := sandbox.GetTaskWorkDir(taskName + "-reference")
+	validatorDir := sandbox.GetTaskWorkDir(taskName + "-validator")
+	defer sandbox.CleanWorkDir(dataDir)
+	defer sandbox.CleanWorkDir(referenceDir)
+	defer sandbox.CleanWorkDir(validatorDir)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return errors.New("创建工作目录失败")
+	}
+
+	sb := sandbox.NewSimpleSandbox()
+	if err := prepareProgram(sb, referenceDir, reference, "标准程序"); err != nil {
+		return err
+	}
+	if validator != nil {
+		if err := prepareProgram(sb, validatorDir, validator, "输入校验器"); err != nil {
+			return err
+		}
+	}
+
+	for i := range proposals {
+		p := &proposals[i]
+		if !strings.HasSuffix(p.Input, "\n") {
+			p.Input += "\n"
+		}
+		if len(p.Input) > maxProposalInputSize {
+			p.Status = model.ProposalStatusInvalid
+			p.Message = fmt.Sprintf("输入超过 %d KB，请改用数据生成器", maxProposalInputSize/1024)
+			continue
+		}
+
+		inputPath := filepath.Join(dataDir, fmt.Sprintf("%d.in", i+1))
+		outputPath := filepath.Join(dataDir, fmt.Sprintf("%d.out", i+1))
+		if err := os.WriteFile(inputPath, []byte(p.Input), 0644); err != nil {
+			return errors.New("写入测试输入失败")
+		}
+
+		if validator != nil {
+			result, err := sb.RunWithFiles(validatorDir, validator.Language, nil, inputPath, filepath.Join(dataDir, "validator.out"), programRunTimeLimit, programRunMemoryLimit, 0)
+			if err := describeProgramRunFailure(result, err); err != nil {
+				p.Status = model.ProposalStatusInvalid
+				p.Message = "输入未通过校验器: " + err.Error()
+				continue
+			}
+		}
+
+		result, err := sb.RunWithFiles(referenceDir, reference.Language, nil, inputPath, outputPath, programRunTimeLimit, programRunMemoryLimit, 0)
+		if err := describeProgramRunFailure(result, err); err != nil {
+			p.Status = model.ProposalStatusError
+			p.Message = "标准程序运行失败: " + err.Error()
+			continue
+		}
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			p.Status = model.ProposalStatusError
+			p.Message = "读取标准程序输出失败"
+			continue
+		}
+		if info.Size() > maxProposalOutputSize {
+			p.Status = model.ProposalStatusError
+			p.Message = fmt.Sprintf("标准程序输出超过 %d KB", maxProposalOutputSize/1024)
+			continue
+		}
+		output, err := os.ReadFile(outputPath)
+		if err != nil {
+			p.Status = model.ProposalStatusError
+			p.Message = "读取标准程序输出失败"
+			continue
+		}
+		p.Output = string(output)
+		p.Status = model.ProposalStatusValid
+	}
+	return nil
+}
+
+// Accept 将可用的建议数据追加为题目测试点，返回采纳的组数
+func (s *TestcaseProposalService) Accept(problemID uint, req *model.TestcaseProposalReviewRequest) (int, error) {
+	proposals, err := s.repo.ListProposals(problemID, req.IDs)
+	if err != nil {
+		return 0, errors.New("获取建议的测试数据失败")
+	}
+	score := req.Score
+	if score <= 0 {
+		score = defaultProposalScore
+	}
+
+	accepted := 0
+	for _, p := range proposals {
+		if p.Status != model.ProposalStatusValid {
+			continue
+		}
+		// 先占用这组数据再加入测试点，重复点击或多人同时采纳时只加入一次
+		claimed, err := s.repo.UpdateProposalStatusFrom(p.ID, model.ProposalStatusValid, model.ProposalStatusAccepted)
+		if err != nil {
+			return accepted, errors.New("更新建议状态失败")
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.problemService.AddTestcase(problemID, strings.NewReader(p.Input), strings.NewReader(p.Output), score, false); err != nil {
+			if _, rollbackErr := s.repo.UpdateProposalStatusFrom(p.ID, model.ProposalStatusAccepted, model.ProposalStatusValid); rollbackErr != nil {
+				log.Printf("[Proposal] 恢复建议状态失败: proposal_id=%d, %v", p.ID, rollbackErr)
+			}
+			return accepted, fmt.Errorf("保存第 %d 组测试数据失败: %v", p.ID, err)
+		}
+		accepted++
+	}
+	if accepted == 0 {
+		return 0, errors.New("所选数据中没有可采纳的测试数据")
+	}
+	return accepted, nil
+}
+
+// Reject 忽略建议的测试数据（已采纳的不受影响），返回忽略的组数
+func (s *TestcaseProposalService) Reject(problemID uint, req *model.TestcaseProposalReviewRequest) (int, error) {
+	proposals, err := s.repo.ListProposals(problemID, req.IDs)
+	if err != nil {
+		return 0, errors.New("获取建议的测试数据失败")
+	}
+	rejected := 0
+	for _, p := range proposals {
+		if p.Status == model.ProposalStatusAccepted || p.Status == model.ProposalStatusRejected {
+			continue
+		}
+		// 只在状态未被其他请求修改时忽略，避免把刚采纳的数据标记为忽略
+		updated, err := s.repo.UpdateProposalStatusFrom(p.ID, p.Status, model.ProposalStatusRejected)
+		if err != nil {
+			return rejected, errors.New("更新建议状态失败")
+		}
+		if updated {
+			rejected++
+		}
+	}
+	return rejected, nil
+}
+
+// RecoverInterrupted 服务重启后，将中断的建议任务标记为异常
+func (s *TestcaseProposalService) RecoverInterrupted() error {
+	return s.repo.MarkRunningAsError("服务重启，建议任务已中断")
+}
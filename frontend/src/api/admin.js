@@ -51,6 +51,41 @@ export const adminApi = {
     return request.get(`/admin/contests/${id}/export`, { params, responseType: 'blob' })
   },
 
+  // 获取颁奖揭晓榜单（封榜结果按揭晓进度展示）
+  getContestResolver(id) {
+    return request.get(`/admin/contests/${id}/resolver`)
+  },
+
+  // 揭晓下一格封榜结果
+  revealNextContestCell(id) {
+    return request.post(`/admin/contests/${id}/resolver/next`)
+  },
+
+  // 揭晓全部结果并解除封榜
+  unfreezeContest(id) {
+    return request.post(`/admin/contests/${id}/unfreeze`)
+  },
+
+  // 回复比赛提问（visibility: private 仅提问者可见，public 广播给全体参赛者）
+  answerContestClarification(contestId, clarificationId, data) {
+    return request.post(`/admin/contests/${contestId}/clarifications/${clarificationId}/answer`, data)
+  },
+
+  // 删除比赛提问
+  deleteContestClarification(contestId, clarificationId) {
+    return request.delete(`/admin/contests/${contestId}/clarifications/${clarificationId}`)
+  },
+
+  // 发布比赛公告
+  createContestAnnouncement(contestId, data) {
+    return request.post(`/admin/contests/${contestId}/announcements`, data)
+  },
+
+  // 删除比赛公告
+  deleteContestAnnouncement(contestId, announcementId) {
+    return request.delete(`/admin/contests/${contestId}/announcements/${announcementId}`)
+  },
+
   // 重置用户窗口期比赛开赛状态
   resetContestUserStart(contestId, userId) {
     return request.post(`/admin/contests/${contestId}/users/${userId}/reset-start`)
@@ -85,4 +120,99 @@ export const adminApi = {
   testAIConnection() {
     return request.post('/admin/settings/ai/test')
   },
+
+  // 提示词模板列表
+  getPromptTemplates() {
+    return request.get('/admin/settings/ai/prompts')
+  },
+
+  // 内置提示词模板
+  getBuiltinPromptTemplate() {
+    return request.get('/admin/settings/ai/prompts/builtin')
+  },
+
+  // 创建提示词模板
+  createPromptTemplate(data) {
+    return request.post('/admin/settings/ai/prompts', data)
+  },
+
+  // 修改提示词模板
+  updatePromptTemplate(id, data) {
+    return request.put(`/admin/settings/ai/prompts/${id}`, data)
+  },
+
+  // 删除提示词模板
+  deletePromptTemplate(id) {
+    return request.delete(`/admin/settings/ai/prompts/${id}`)
+  },
+
+  // 提示词模板历史版本
+  getPromptTemplateVersions(id) {
+    return request.get(`/admin/settings/ai/prompts/${id}/versions`)
+  },
+
+  // 恢复提示词模板历史版本
+  restorePromptTemplateVersion(id, version) {
+    return request.post(`/admin/settings/ai/prompts/${id}/versions/${version}/restore`)
+  },
+
+  // 按提交试渲染提示词（不调用模型）
+  renderPrompt(data) {
+    return request.post('/admin/settings/ai/prompts/render', data)
+  },
+
+  // AI 调用记录列表
+  getAICallLogs(params) {
+    return request.get('/admin/ai-logs', { params })
+  },
+
+  // AI 调用记录详情
+  getAICallLog(id) {
+    return request.get(`/admin/ai-logs/${id}`)
+  },
+
+  // 按保留天数清理 AI 调用记录
+  purgeAICallLogs() {
+    return request.post('/admin/ai-logs/purge')
+  },
+
+  // AI token 用量统计与本月预算
+  getAIUsage(params) {
+    return request.get('/admin/ai-usage', { params })
+  },
+
+  // 发起代码查重（题目或比赛）
+  createPlagiarismCheck(data) {
+    return request.post('/admin/plagiarism', data)
+  },
+
+  // 查重报告列表
+  getPlagiarismReports(params) {
+    return request.get('/admin/plagiarism', { params })
+  },
+
+  // 查重报告详情（含相似代码对）
+  getPlagiarismReport(id) {
+    return request.get(`/admin/plagiarism/${id}`)
+  },
+
+  // 相似代码对详情（含两份代码）
+  getPlagiarismPair(id, pairId) {
+    return request.get(`/admin/plagiarism/${id}/pairs/${pairId}`)
+  },
+
+  // 删除查重报告
+  deletePlagiarismReport(id) {
+    return request.delete(`/admin/plagiarism/${id}`)
+  },
+
+  // 等待教师复核的低置信度 AI 判定
+  getAIReviews(params) {
+    return request.get('/admin/ai-reviews', { params })
+  },
+
+  // 确认或改判 AI 判定
+  reviewAIResult(submissionId, data) {
+    return request.post(`/admin/submissions/${submissionId}/ai-review`, data)
+  },
 }
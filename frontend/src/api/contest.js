@@ -12,4 +12,16 @@ export const contestApi = {
   start(id) {
     return request.post(`/contest/${id}/start`)
   },
+
+  getLeaderboard(id) {
+    return request.get(`/contest/${id}/leaderboard`)
+  },
+
+  getClarifications(id) {
+    return request.get(`/contest/${id}/clarifications`)
+  },
+
+  askClarification(id, data) {
+    return request.post(`/contest/${id}/clarifications`, data)
+  },
 }
@@ -6,9 +6,9 @@ export const problemApi = {
     return request.get('/problem/list', { params })
   },
 
-  // 获取题目详情
-  getById(id) {
-    return request.get(`/problem/${id}`)
+  // 获取题目详情；lang 指定题面语言，未指定时按浏览器的 Accept-Language 选择已审核的译文
+  getById(id, lang) {
+    return request.get(`/problem/${id}`, { params: lang ? { lang } : undefined })
   },
 
   // 创建题目（管理员）
@@ -50,9 +50,9 @@ export const problemApi = {
     })
   },
 
-  // 整题重测（管理员）
-  rejudge(id) {
-    return request.post(`/problem/${id}/rejudge`)
+  // 整题重测（管理员），forceAI 为 true 时忽略 AI 分析缓存
+  rejudge(id, forceAI = false) {
+    return request.post(`/problem/${id}/rejudge`, null, { params: forceAI ? { force_ai: true } : {} })
   },
 
   // 获取测试用例列表（管理员）
@@ -64,4 +64,54 @@ export const problemApi = {
   deleteTestcases(id) {
     return request.delete(`/problem/${id}/testcases`)
   },
+
+  // 保存题目配套程序（管理员），role 为 generator / reference / validator
+  saveProgram(id, role, data) {
+    return request.put(`/problem/${id}/programs/${role}`, data)
+  },
+
+  // 请 AI 建议边界测试数据（管理员，异步）
+  proposeTestcases(id, data) {
+    return request.post(`/problem/${id}/testcase/ai-propose`, data)
+  },
+
+  // 获取 AI 建议的测试数据（管理员）
+  getTestcaseProposals(id) {
+    return request.get(`/problem/${id}/testcase/ai-proposals`)
+  },
+
+  // 采纳建议数据为测试点（管理员）
+  acceptTestcaseProposals(id, data) {
+    return request.post(`/problem/${id}/testcase/ai-proposals/accept`, data)
+  },
+
+  // 忽略建议数据（管理员）
+  rejectTestcaseProposals(id, data) {
+    return request.post(`/problem/${id}/testcase/ai-proposals/reject`, data)
+  },
+
+  // 获取题面译文（管理员，含草稿）
+  getTranslations(id) {
+    return request.get(`/problem/${id}/translations`)
+  },
+
+  // 用 AI 翻译题面（管理员，异步，结果为草稿）
+  translateProblem(id, data) {
+    return request.post(`/problem/${id}/translations/ai`, data)
+  },
+
+  // 保存译文，reviewed 为 true 时标记为已审核（管理员）
+  saveTranslation(id, locale, data) {
+    return request.put(`/problem/${id}/translations/${locale}`, data)
+  },
+
+  // 删除译文（管理员）
+  deleteTranslation(id, locale) {
+    return request.delete(`/problem/${id}/translations/${locale}`)
+  },
+
+  // 放弃已发布译文的待审核版本（管理员）
+  discardTranslationPending(id, locale) {
+    return request.delete(`/problem/${id}/translations/${locale}/pending`)
+  },
 }
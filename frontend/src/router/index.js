@@ -92,6 +92,11 @@ const routes = [
         name: 'AdminContestEdit',
         component: () => import('@/views/admin/ContestEdit.vue'),
       },
+      {
+        path: 'contest/:id/resolver',
+        name: 'AdminContestResolver',
+        component: () => import('@/views/admin/ContestResolver.vue'),
+      },
       {
         path: 'problem/create',
         name: 'AdminProblemCreate',
@@ -107,6 +112,16 @@ const routes = [
         name: 'AdminUsers',
         component: () => import('@/views/admin/UserManage.vue'),
       },
+      {
+        path: 'plagiarism',
+        name: 'AdminPlagiarism',
+        component: () => import('@/views/admin/PlagiarismManage.vue'),
+      },
+      {
+        path: 'ai-reviews',
+        name: 'AdminAIReviews',
+        component: () => import('@/views/admin/AIReviewQueue.vue'),
+      },
       {
         path: 'settings',
         name: 'AdminSettings',